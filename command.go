@@ -20,40 +20,162 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime/debug"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/choopm/stdfx/globals"
+	"github.com/choopm/stdfx/loggingfx"
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
 	"golang.org/x/sync/errgroup"
 )
 
 const (
-	// startBackoff defines the time frame to capture errors during startup
-	// when using [Commander]
-	startBackoff = 1 * time.Second
+	// DefaultStartBackoff defines the time frame to capture errors during
+	// startup when using [Commander]
+	DefaultStartBackoff = 1 * time.Second
 )
 
+// commanderOptions stores options for [CommanderOption] funcs
+type commanderOptions struct {
+	startBackoff time.Duration
+	stopTimeout  time.Duration
+	readyProbe   *ReadyProbe
+	recover      bool
+}
+
+// CommanderOption is a func to adjust options of *commanderOptions for later
+// usage during [CommanderWithOptions].
+type CommanderOption func(*commanderOptions)
+
+// defaultCommanderOptions returns the default *commanderOptions
+func defaultCommanderOptions() *commanderOptions {
+	return &commanderOptions{
+		startBackoff: DefaultStartBackoff,
+		stopTimeout:  0,
+	}
+}
+
+// WithStartBackoff sets the time frame to capture errors during startup.
+// The command is considered up and running once it has passed.
+// Defaults to [DefaultStartBackoff].
+func WithStartBackoff(d time.Duration) CommanderOption {
+	return func(o *commanderOptions) {
+		o.startBackoff = d
+	}
+}
+
+// WithStopTimeout sets the time to wait for the command to return
+// after its context has been cancelled.
+// A value of 0 waits as long as the fx stop timeout allows,
+// which is [fx.DefaultTimeout] unless set by [fx.StopTimeout].
+func WithStopTimeout(d time.Duration) CommanderOption {
+	return func(o *commanderOptions) {
+		o.stopTimeout = d
+	}
+}
+
+// WithReadyProbe sets the *ReadyProbe injected into the command context,
+// use it to wait for the command to call [Ready].
+// Defaults to a new probe per command.
+func WithReadyProbe(probe *ReadyProbe) CommanderOption {
+	return func(o *commanderOptions) {
+		o.readyProbe = probe
+	}
+}
+
+// WithRecover recovers panics of the command, logging the panic and its
+// stack before shutting down the app using exit code 1.
+// Panics crash the process by default, see [CommanderWithRecover].
+func WithRecover() CommanderOption {
+	return func(o *commanderOptions) {
+		o.recover = true
+	}
+}
+
+// registerOptions stores options for [RegisterOption] funcs
+type registerOptions struct {
+	group string
+}
+
+// RegisterOption is a func to adjust options of *registerOptions for
+// later usage during [AutoRegister].
+type RegisterOption func(*registerOptions)
+
+// WithGroup lists the registered command in the help of the root command
+// below the group id, e.g. "admin". Commands of the same group are listed
+// together, see [WithRootGroups] to set titles and order of groups.
+func WithGroup(id string) RegisterOption {
+	return func(o *registerOptions) {
+		o.group = id
+	}
+}
+
 // AutoRegister annotates a *cobra.Command constructor f to be
 // automatically registered as a sub command in NewRootCommand.
 // Usage example:
 //
 //	fx.Provide(
 //		stdfx.AutoRegister(firstCommandConstructor),
-//		stdfx.AutoRegister(secondCommandConstructor),
+//		stdfx.AutoRegister(secondCommandConstructor, stdfx.WithGroup("admin")),
 //		stdfx.AutoCommand,
 //	),
 //	fx.Invoke(stdfx.Commander),
-func AutoRegister(f any) any {
+func AutoRegister(f any, opts ...RegisterOption) any {
+	// apply any given opts
+	rOpts := &registerOptions{}
+	for _, option := range opts {
+		option(rOpts)
+	}
+
+	if len(rOpts.group) > 0 {
+		f = withCommandGroup(f, rOpts.group)
+	}
+
 	return fx.Annotate(
 		f,
 		fx.ResultTags(`group:"commands"`),
 	)
 }
 
+// withCommandGroup wraps the constructor f setting the GroupID of
+// all returned commands to id. f is returned as is if not a func,
+// which is reported by fx.
+func withCommandGroup(f any, id string) any {
+	fn := reflect.ValueOf(f)
+	if fn.Kind() != reflect.Func {
+		return f
+	}
+
+	return reflect.MakeFunc(fn.Type(), func(args []reflect.Value) []reflect.Value {
+		var results []reflect.Value
+		if fn.Type().IsVariadic() {
+			results = fn.CallSlice(args)
+		} else {
+			results = fn.Call(args)
+		}
+		for _, result := range results {
+			if cmd, ok := result.Interface().(*cobra.Command); ok && cmd != nil {
+				cmd.GroupID = id
+			}
+		}
+		return results
+	}).Interface()
+}
+
 // AutoCommand is an annotated version of NewRootCommand which
 // passes anything previously called with AutoRegister to an
 // annotated version of NewRootCommand.
+// Persistent pre-runs of all parents are called before the one of a
+// command as cobra.EnableTraverseRunHooks is set if logging flags or
+// [ConfigValue] are provided.
 // Usage example:
 //
 //	fx.Provide(
@@ -62,31 +184,242 @@ func AutoRegister(f any) any {
 //		stdfx.AutoCommand,
 //	),
 //	fx.Invoke(stdfx.Commander),
-var AutoCommand = fx.Annotate(
-	newRootCommand,
+var AutoCommand = AutoCommandWithOptions()
+
+// rootCommandOptions stores options for [RootCommandOption] funcs
+type rootCommandOptions struct {
+	out           io.Writer
+	err           io.Writer
+	use           string
+	short         string
+	silenceUsage  bool
+	silenceErrors bool
+	groups        []*cobra.Group
+	noCompletion  bool
+	noHelp        bool
+	decorators    []func(cmd *cobra.Command)
+}
+
+// RootCommandOption is a func to adjust options of *rootCommandOptions for
+// later usage during [AutoCommandWithOptions].
+type RootCommandOption func(*rootCommandOptions)
+
+// WithRootOut sets the writer of the root command and its subcommands
+// for regular output like help pages, defaults to os.Stdout.
+func WithRootOut(w io.Writer) RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.out = w
+	}
+}
+
+// WithRootErr sets the writer of the root command and its subcommands
+// for error output, defaults to os.Stderr.
+func WithRootErr(w io.Writer) RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.err = w
+	}
+}
+
+// WithRootUse sets the Use line of the root command, e.g. "myapp".
+// Defaults to the base name of the binary.
+func WithRootUse(use string) RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.use = use
+	}
+}
+
+// WithRootShort sets the short description of the root command
+// shown in help pages. Defaults to the main module path of the binary.
+func WithRootShort(short string) RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.short = short
+	}
+}
+
+// WithRootSilenceUsage disables printing the usage of the root command
+// and its subcommands when they return an error.
+func WithRootSilenceUsage() RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.silenceUsage = true
+	}
+}
+
+// WithRootSilenceErrors disables printing errors returned by the root
+// command and its subcommands, use it if errors are logged anyway.
+func WithRootSilenceErrors() RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.silenceErrors = true
+	}
+}
+
+// WithRootGroups declares the groups of commands listed in the help of
+// the root command in the given order. Groups used by [WithGroup] but
+// not declared are appended sorted by id using a title derived from it,
+// e.g. "Admin Commands:" for "admin".
+// Usage example:
+//
+//	stdfx.WithRootGroups(
+//		&cobra.Group{ID: "server", Title: "Server Commands:"},
+//		&cobra.Group{ID: "admin", Title: "Administration:"},
+//	),
+func WithRootGroups(groups ...*cobra.Group) RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.groups = append(o.groups, groups...)
+	}
+}
+
+// WithRootDisableCompletion removes the completion command cobra adds
+// to the root command by default.
+func WithRootDisableCompletion() RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.noCompletion = true
+	}
+}
+
+// WithRootDisableHelpCommand removes the help command cobra adds to the
+// root command by default, invoking it fails as an unknown command.
+// The --help flag is still supported.
+func WithRootDisableHelpCommand() RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.noHelp = true
+	}
+}
+
+// WithRootDecorator adds f to be called with the constructed root command
+// after its subcommands have been added. Use it to customize anything not
+// covered by other options, e.g. persistent pre-run hooks.
+// Decorators are called in the order they were added.
+func WithRootDecorator(f func(cmd *cobra.Command)) RootCommandOption {
+	return func(o *rootCommandOptions) {
+		o.decorators = append(o.decorators, f)
+	}
+}
+
+// AutoCommandWithOptions is an [AutoCommand] which can be tuned using opts.
+// Usage example:
+//
+//	fx.Provide(
+//		stdfx.AutoRegister(firstCommandConstructor),
+//		stdfx.AutoCommandWithOptions(
+//			stdfx.WithRootUse("myapp"),
+//			stdfx.WithRootShort("myapp serves things"),
+//			stdfx.WithRootSilenceUsage(),
+//			stdfx.WithRootOut(stdout),
+//			stdfx.WithRootErr(stderr),
+//		),
+//	),
+func AutoCommandWithOptions(opts ...RootCommandOption) any {
+	// apply any given opts
+	rOpts := &rootCommandOptions{}
+	for _, option := range opts {
+		option(rOpts)
+	}
+
+	return fx.Annotate(
+		func(
+			flags *loggingfx.Flags,
+			preRuns []rootPreRun,
+			commands ...*cobra.Command,
+		) (*cobra.Command, error) {
+			return newRootCommand(rOpts, flags, preRuns, commands...)
+		},
+		fx.ParamTags(`optional:"true"`, `group:"rootPreRuns"`, `group:"commands"`),
+	)
+}
+
+// rootPreRun is called by the persistent pre-run of the root command
+// once cobra parsed the flags, before any command runs.
+// Constructors provide them using the group "rootPreRuns".
+type rootPreRun func(cmd *cobra.Command, args []string) error
+
+// Commands lists all commands registered using [AutoRegister].
+type Commands []*cobra.Command
+
+// RegisteredCommands is an annotated constructor providing [Commands].
+// It allows to inspect anything previously called with AutoRegister,
+// which is useful for tooling and tests.
+// Usage example:
+//
+//	fx.Provide(
+//		stdfx.AutoRegister(firstCommandConstructor),
+//		stdfx.RegisteredCommands,
+//	),
+//	fx.Invoke(func(commands stdfx.Commands) {
+//		// ...
+//	}),
+var RegisteredCommands = fx.Annotate(
+	newCommands,
 	fx.ParamTags(`group:"commands"`),
 )
 
-// newRootCommand provides a root command which adds any provided
+// newCommands returns the given commands sorted by name.
+func newCommands(commands ...*cobra.Command) Commands {
+	sorted := slices.Clone(commands)
+	slices.SortFunc(sorted, func(a, b *cobra.Command) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return sorted
+}
+
+// newRootCommand provides a root command using opts which adds any provided
 // commands as child commands.
 // Starting the root command will print the help page.
 // Any globalFlags from ConfigSource implementations will be merged.
+// The logging flags are added if not nil and applied by a persistent
+// pre-run followed by preRuns. It sets cobra.EnableTraverseRunHooks,
+// so commands defining their own persistent pre-run don't shadow it.
 // It is up to the developer to provide meaningful subcommands.
-func newRootCommand(commands ...*cobra.Command) *cobra.Command {
+// Commands sharing a name are rejected using [ErrDuplicateCommand].
+func newRootCommand(
+	opts *rootCommandOptions,
+	flags *loggingfx.Flags,
+	preRuns []rootPreRun,
+	commands ...*cobra.Command,
+) (*cobra.Command, error) {
+	if err := checkDuplicateCommands(commands); err != nil {
+		return nil, err
+	}
+
+	use, short := opts.use, opts.short
+	if len(use) == 0 {
+		use = filepath.Base(os.Args[0])
+	}
+	if len(short) == 0 {
+		short = mainModulePath()
+	}
+
 	cmd := &cobra.Command{
-		Use:   "",
-		Short: "",
+		Use:   use,
+		Short: short,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		},
+		SilenceUsage:  opts.silenceUsage,
+		SilenceErrors: opts.silenceErrors,
+	}
+
+	// default commands
+	cmd.CompletionOptions.DisableDefaultCmd = opts.noCompletion
+
+	// output streams, inherited by subcommands
+	if opts.out != nil {
+		cmd.SetOut(opts.out)
+	}
+	if opts.err != nil {
+		cmd.SetErr(opts.err)
 	}
 
 	// add global RootFlags, can be filled by ConfigSource
-	cmd.PersistentFlags().AddFlagSet(globals.RootFlags)
+	rootFlags, rootPreRuns := globals.TakeRootFlags()
+	cmd.PersistentFlags().AddFlagSet(rootFlags)
+	if flags != nil {
+		cmd.PersistentFlags().AddFlagSet(flags.FlagSet())
+	}
 
 	// add global PreRuns, can be filled by commands
 	cmd.PreRun = func(cmd *cobra.Command, args []string) {
-		for _, cb := range globals.RootPreRuns {
+		for _, cb := range rootPreRuns {
 			cb(cmd, args)
 		}
 	}
@@ -95,8 +428,175 @@ func newRootCommand(commands ...*cobra.Command) *cobra.Command {
 	for _, c := range commands {
 		cmd.AddCommand(c)
 	}
+	addCommandGroups(cmd, opts.groups)
+
+	// custom modifications
+	for _, decorate := range opts.decorators {
+		decorate(cmd)
+	}
+
+	// loggers and config values are built before cobra parsed the flags,
+	// apply them before any command runs, even if it has its own
+	// persistent pre-run
+	if flags != nil || len(preRuns) > 0 {
+		cobra.EnableTraverseRunHooks = true
+		prependPersistentPreRun(cmd, func(c *cobra.Command, args []string) error {
+			if flags != nil {
+				if err := flags.Apply(); err != nil {
+					return err
+				}
+			}
+			for _, preRun := range preRuns {
+				if err := preRun(c, args); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if opts.noHelp {
+		disableHelpCommand(cmd)
+	}
+
+	return cmd, nil
+}
+
+// prependPersistentPreRun sets f to be called by the persistent pre-run
+// of cmd before the one set so far, e.g. by decorators
+func prependPersistentPreRun(cmd *cobra.Command, f func(c *cobra.Command, args []string) error) {
+	preRunE, preRun := cmd.PersistentPreRunE, cmd.PersistentPreRun
+	cmd.PersistentPreRun = nil
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if err := f(c, args); err != nil {
+			return err
+		}
+
+		switch {
+		case preRunE != nil:
+			return preRunE(c, args)
+		case preRun != nil:
+			preRun(c, args)
+		}
+		return nil
+	}
+}
+
+// disableHelpCommand removes the help command of cmd.
+// cobra adds a help command on every execution, so cmd gets a hidden
+// one without a name which is removed by a persistent pre-run of cmd,
+// before any command runs or shell completions are computed.
+// Persistent pre-runs set by decorators are called afterwards.
+func disableHelpCommand(cmd *cobra.Command) {
+	help := &cobra.Command{Hidden: true}
+	cmd.SetHelpCommand(help)
+
+	prependPersistentPreRun(cmd, func(c *cobra.Command, _ []string) error {
+		cmd.RemoveCommand(help)
+		if c == help {
+			return fmt.Errorf("unknown command for %q", cmd.CommandPath())
+		}
+		return nil
+	})
+}
+
+// ErrDuplicateCommand is returned by [AutoCommand] if several commands
+// registered using [AutoRegister] share the same name
+var ErrDuplicateCommand = errors.New("duplicate command")
+
+// checkDuplicateCommands returns an error wrapping [ErrDuplicateCommand]
+// listing the names used by several commands
+func checkDuplicateCommands(commands []*cobra.Command) error {
+	seen := map[string]int{}
+	for _, c := range commands {
+		seen[c.Name()]++
+	}
+
+	duplicates := []string{}
+	for name, count := range seen {
+		if count > 1 {
+			duplicates = append(duplicates, fmt.Sprintf("%q registered %d times", name, count))
+		}
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	slices.Sort(duplicates)
 
-	return cmd
+	return fmt.Errorf("%w: %s", ErrDuplicateCommand, strings.Join(duplicates, ", "))
+}
+
+// addCommandGroups adds groups to cmd followed by any other group
+// referenced by its subcommands sorted by id.
+func addCommandGroups(cmd *cobra.Command, groups []*cobra.Group) {
+	for _, group := range groups {
+		cmd.AddGroup(group)
+	}
+
+	ids := []string{}
+	for _, c := range cmd.Commands() {
+		if len(c.GroupID) == 0 || cmd.ContainsGroup(c.GroupID) || slices.Contains(ids, c.GroupID) {
+			continue
+		}
+		ids = append(ids, c.GroupID)
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		cmd.AddGroup(&cobra.Group{
+			ID:    id,
+			Title: strings.ToUpper(id[:1]) + id[1:] + " Commands:",
+		})
+	}
+}
+
+// mainModulePath returns the path of the main module from the build info
+// of the binary or an empty string if unavailable.
+func mainModulePath() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Path
+}
+
+// ExitError can be returned by commands to exit using Code when
+// run by [Commander]. Other errors exit using code 1.
+// Usage example:
+//
+//	return stdfx.ExitError{Code: 3, Err: err}
+type ExitError struct {
+	// Code is the exit code of the process
+	Code int
+	// Err is the wrapped error
+	Err error
+}
+
+// Error implements error
+func (e ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode returns the exit code of err, the code of a wrapped
+// [ExitError] or 1
+func exitCode(err error) int {
+	var exitErr ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	var exitErrPtr *ExitError
+	if errors.As(err, &exitErrPtr) && exitErrPtr != nil {
+		return exitErrPtr.Code
+	}
+
+	return 1
 }
 
 // Commander can be used as a *cobra.Command invoker for fx.
@@ -106,27 +606,114 @@ func newRootCommand(commands ...*cobra.Command) *cobra.Command {
 // The started *cobra.Command shall use cmd.Context() to watch for Done().
 // The ctx of cmd.Context() will be cancelled when it is time to shutdown.
 // Failure to track cmd.Context() will kill your application after
-// [fx.DefaultTimeout] - 15 seconds.
+// [fx.DefaultTimeout] - 15 seconds. Use [CommanderWithTimeout] to adjust.
+// Errors of cmd shutdown the app using exit code 1 or the code of [ExitError].
 // fx.Lifecycle and fx.Shutdowner are injected into cmd.Context()
-// and can be retrieved by calling [ExtractFromContext].
-func Commander(
+// and can be retrieved by calling [ShutdownerFromContext] and
+// [LifecycleFromContext]. Commands signal readiness using [Ready].
+func Commander(p CommanderParams) {
+	commander(p, defaultCommanderOptions())
+}
+
+// CommanderParams are the dependencies of [Commander].
+// Errors of the command are logged using Log if provided.
+type CommanderParams struct {
+	fx.In
+
+	Lifecycle  fx.Lifecycle
+	Shutdowner fx.Shutdowner
+	Command    *cobra.Command
+	Log        *slog.Logger `optional:"true"`
+}
+
+// CommanderWithTimeout is a [Commander] waiting up to d for the command
+// to stop before forcibly returning.
+// Usage example:
+//
+//	fx.Invoke(stdfx.CommanderWithTimeout(30 * time.Second)),
+func CommanderWithTimeout(d time.Duration) func(p CommanderParams) {
+	return CommanderWithOptions(WithStopTimeout(d))
+}
+
+// CommanderWithRecover is a [Commander] recovering panics of cmd.
+// Panics are logged including their stack and shutdown the app
+// using exit code 1 instead of crashing the process, see [WithRecover].
+// Usage example:
+//
+//	fx.Invoke(stdfx.CommanderWithRecover),
+func CommanderWithRecover(p CommanderParams) {
+	CommanderWithOptions(WithRecover())(p)
+}
+
+// CommanderWithOptions is a [Commander] which can be tuned using opts.
+// Usage example:
+//
+//	fx.Invoke(stdfx.CommanderWithOptions(
+//		stdfx.WithStartBackoff(3 * time.Second),
+//		stdfx.WithStopTimeout(30 * time.Second),
+//	)),
+func CommanderWithOptions(opts ...CommanderOption) func(p CommanderParams) {
+	// apply any given opts
+	cOpts := defaultCommanderOptions()
+	for _, option := range opts {
+		option(cOpts)
+	}
+
+	return func(p CommanderParams) {
+		commander(p, cOpts)
+	}
+}
+
+// commander implements [Commander] using opts
+func commander(p CommanderParams, opts *commanderOptions) {
+	log := p.Log
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+
+	cmd := p.Command
+	appendRun(p.Lifecycle, p.Shutdowner, cmd.Name(), func(ctx context.Context) error {
+		_, err := cmd.ExecuteContextC(ctx)
+		return err
+	}, log, opts)
+}
+
+// appendRun appends hooks to lc running run in a goroutine using opts.
+// The ctx of run carries shutdowner, a lifecycle and a ready probe for use
+// by [ShutdownerFromContext], [LifecycleFromContext] and [Ready], it is
+// cancelled on stop. The app is shutdown once run returns.
+func appendRun(
 	lc fx.Lifecycle,
 	shutdowner fx.Shutdowner,
-	cmd *cobra.Command,
+	name string,
+	run func(ctx context.Context) error,
+	log *slog.Logger,
+	opts *commanderOptions,
 ) {
+	// hooks appended by the running command
+	cmdLc := &commandLifecycle{shutdowner: shutdowner, log: log}
 
-	// errgroup and ctx to start/stop the *cobra.Command
+	// errgroup and ctx to start/stop run
 	ctx := withShutdowner(context.Background(), shutdowner)
+	ctx = withLifecycle(ctx, cmdLc)
+	probe := opts.readyProbe
+	if probe == nil {
+		probe = NewReadyProbe()
+	}
+	ctx = withReadyProbe(ctx, probe)
+	if opts.recover {
+		run = recoverRun(name, run, log)
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	g, ctx := errgroup.WithContext(ctx)
 
 	lc.Append(fx.Hook{
 		OnStart: func(_ context.Context) error {
-			// start the *cobra.Command using the errgroup and its ctx
+			// start run using the errgroup and its ctx
 			g.Go(func() error {
-				_, err := cmd.ExecuteContextC(ctx)
+				err := run(ctx)
 				if err != nil && !errors.Is(err, context.Canceled) {
-					defer shutdowner.Shutdown(fx.ExitCode(1)) // nolint:errcheck
+					defer shutdowner.Shutdown(fx.ExitCode(exitCode(err))) // nolint:errcheck
 					return fmt.Errorf("failed to run: %s", err)
 				}
 				return shutdowner.Shutdown()
@@ -138,14 +725,63 @@ func Commander(
 			case <-ctx.Done():
 				return g.Wait()
 
-			case <-time.After(startBackoff):
+			case <-time.After(opts.startBackoff):
 				return nil
 			}
 		},
-		OnStop: func(_ context.Context) error {
+		OnStop: func(stopCtx context.Context) error {
 			// cancel the errgroup and wait for shutdown to finish
 			cancel()
-			return g.Wait()
+			done := make(chan error, 1)
+			go func() {
+				done <- g.Wait()
+			}()
+
+			// limit the time to wait if requested
+			if opts.stopTimeout > 0 {
+				var stopCancel context.CancelFunc
+				stopCtx, stopCancel = context.WithTimeout(stopCtx, opts.stopTimeout)
+				defer stopCancel()
+			}
+
+			select {
+			case err := <-done:
+				// stop resources of the command after it returned
+				return errors.Join(err, cmdLc.stop(stopCtx))
+
+			case <-stopCtx.Done():
+				log.Warn("command did not stop in time, forcing shutdown",
+					slog.String("command", name),
+					slog.Duration("timeout", opts.stopTimeout))
+				return errors.Join(
+					fmt.Errorf("stopping command: %w", stopCtx.Err()),
+					cmdLc.stop(stopCtx),
+				)
+			}
 		},
 	})
 }
+
+// recoverRun returns run returning an error instead of panicking.
+// The panic is logged using log including its stack.
+func recoverRun(
+	name string,
+	run func(ctx context.Context) error,
+	log *slog.Logger,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) (err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			log.Error("command panicked",
+				slog.String("command", name),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("panic: %v", r)
+		}()
+
+		return run(ctx)
+	}
+}
@@ -0,0 +1,478 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+func TestCommanderWithTimeout(t *testing.T) {
+	// command ignoring its context until the test is done
+	release := make(chan struct{})
+	defer close(release)
+	cmd := &cobra.Command{
+		Use: "stubborn",
+		Run: func(cmd *cobra.Command, args []string) {
+			<-release
+		},
+	}
+	cmd.SetArgs([]string{})
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(cmd, slog.New(slog.DiscardHandler)),
+		fx.Invoke(stdfx.CommanderWithOptions(
+			stdfx.WithStartBackoff(50*time.Millisecond),
+			stdfx.WithStopTimeout(200*time.Millisecond),
+		)),
+	)
+	require.NoError(t, app.Start(context.Background()))
+
+	// stop shall return after the configured timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	err := app.Stop(ctx)
+	elapsed := time.Since(start)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, elapsed, 200*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second)
+}
+
+func TestCommanderWithoutLogger(t *testing.T) {
+	// the logger is optional
+	ran := make(chan struct{})
+	cmd := &cobra.Command{
+		Use: "run",
+		Run: func(cmd *cobra.Command, args []string) {
+			close(ran)
+		},
+	}
+	cmd.SetArgs([]string{})
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(cmd),
+		fx.Invoke(stdfx.Commander),
+	)
+	require.NoError(t, app.Err())
+	require.NoError(t, app.Start(context.Background()))
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("command did not run")
+	}
+	require.NoError(t, app.Stop(context.Background()))
+}
+
+func TestRegisteredCommands(t *testing.T) {
+	newCommand := func(use string) func() *cobra.Command {
+		return func() *cobra.Command {
+			return &cobra.Command{Use: use}
+		}
+	}
+
+	var commands stdfx.Commands
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			stdfx.AutoRegister(newCommand("server")),
+			stdfx.AutoRegister(newCommand("migrate")),
+			stdfx.RegisteredCommands,
+		),
+		fx.Populate(&commands),
+	)
+	require.NoError(t, app.Err())
+
+	names := []string{}
+	for _, cmd := range commands {
+		names = append(names, cmd.Name())
+	}
+	assert.Equal(t, []string{"migrate", "server"}, names)
+}
+
+func TestAutoCommandWithOptions(t *testing.T) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+
+	var root *cobra.Command
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			stdfx.AutoRegister(func() *cobra.Command {
+				return &cobra.Command{Use: "server", Short: "starts the server"}
+			}),
+			stdfx.AutoCommandWithOptions(
+				stdfx.WithRootOut(out),
+				stdfx.WithRootErr(errOut),
+			),
+			loggingfx.NewFlags,
+		),
+		fx.Populate(&root),
+	)
+	require.NoError(t, app.Err())
+
+	root.SetArgs([]string{"--help"})
+	require.NoError(t, root.Execute())
+	assert.Contains(t, out.String(), "starts the server")
+	assert.Contains(t, out.String(), "--log-level")
+	assert.Contains(t, out.String(), "--log-format")
+	assert.Contains(t, out.String(), "--log-output")
+
+	root.SetArgs([]string{"unknown"})
+	require.Error(t, root.Execute())
+	assert.Contains(t, errOut.String(), `unknown command "unknown"`)
+}
+
+func TestAutoCommandLoggingFlags(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv("LOG_LEVEL", "info")
+	t.Setenv("LOG_FORMAT", "json")
+	t.Setenv("LOG_OUTPUT", filename)
+
+	var root *cobra.Command
+	var log *zerolog.Logger
+	app := fx.New(
+		fx.NopLogger,
+		zerologfx.Module,
+		fx.Provide(
+			stdfx.AutoRegister(func(log *zerolog.Logger) *cobra.Command {
+				return &cobra.Command{
+					Use: "server",
+					Run: func(cmd *cobra.Command, args []string) {
+						log.Debug().Msg("flagged")
+					},
+				}
+			}),
+			stdfx.AutoCommand,
+		),
+		fx.Populate(&root, &log),
+	)
+	require.NoError(t, app.Err())
+
+	// the level of the environment is used until the flags are applied
+	log.Debug().Msg("environment")
+	root.SetArgs([]string{"server", "--log-level", "debug"})
+	require.NoError(t, root.Execute())
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "environment")
+	assert.Contains(t, string(b), "flagged")
+
+	// invalid flags fail the command
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(errOut)
+	root.SetArgs([]string{"server", "--log-level", "verbose"})
+	assert.ErrorContains(t, root.Execute(), "unknown log.level: verbose")
+	assert.Contains(t, errOut.String(), "Error: unknown log.level: verbose")
+	assert.Contains(t, out.String(), "--log-level")
+}
+
+func TestAutoCommandWithOptionsRoot(t *testing.T) {
+	var root *cobra.Command
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			stdfx.AutoRegister(func() *cobra.Command {
+				return &cobra.Command{Use: "server"}
+			}),
+			stdfx.AutoCommandWithOptions(
+				stdfx.WithRootUse("myapp"),
+				stdfx.WithRootShort("myapp serves things"),
+				stdfx.WithRootSilenceUsage(),
+				stdfx.WithRootSilenceErrors(),
+				stdfx.WithRootDecorator(func(cmd *cobra.Command) {
+					// subcommands are added already
+					assert.True(t, cmd.HasSubCommands())
+					cmd.Version = "1.0.0"
+				}),
+			),
+		),
+		fx.Populate(&root),
+	)
+	require.NoError(t, app.Err())
+
+	assert.Equal(t, "myapp", root.Use)
+	assert.Equal(t, "myapp serves things", root.Short)
+	assert.True(t, root.SilenceUsage)
+	assert.True(t, root.SilenceErrors)
+	assert.Equal(t, "1.0.0", root.Version)
+}
+
+func TestAutoCommandDefaultUse(t *testing.T) {
+	var root *cobra.Command
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(stdfx.AutoCommand),
+		fx.Populate(&root),
+	)
+	require.NoError(t, app.Err())
+
+	assert.Equal(t, filepath.Base(os.Args[0]), root.Use)
+	assert.Equal(t, filepath.Base(os.Args[0]), root.Name())
+}
+
+func TestAutoRegisterWithGroup(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	var root *cobra.Command
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(
+			stdfx.AutoRegister(func(*slog.Logger) *cobra.Command {
+				return &cobra.Command{Use: "server", Run: func(*cobra.Command, []string) {}}
+			}, stdfx.WithGroup("service")),
+			stdfx.AutoRegister(func() (*cobra.Command, error) {
+				return &cobra.Command{Use: "migrate", Run: func(*cobra.Command, []string) {}}, nil
+			}, stdfx.WithGroup("admin")),
+			stdfx.AutoRegister(func() *cobra.Command {
+				return &cobra.Command{Use: "version", Run: func(*cobra.Command, []string) {}}
+			}),
+			stdfx.AutoCommandWithOptions(
+				stdfx.WithRootOut(out),
+				stdfx.WithRootGroups(&cobra.Group{ID: "service", Title: "Service:"}),
+			),
+		),
+		fx.Populate(&root),
+	)
+	require.NoError(t, app.Err())
+
+	groups := map[string]string{}
+	for _, c := range root.Commands() {
+		groups[c.Name()] = c.GroupID
+	}
+	assert.Equal(t, "service", groups["server"])
+	assert.Equal(t, "admin", groups["migrate"])
+	assert.Equal(t, "", groups["version"])
+
+	// declared groups come first
+	require.Len(t, root.Groups(), 2)
+	assert.Equal(t, "Service:", root.Groups()[0].Title)
+	assert.Equal(t, "Admin Commands:", root.Groups()[1].Title)
+
+	root.SetArgs([]string{"--help"})
+	require.NoError(t, root.Execute())
+	assert.Regexp(t, `(?s)Service:\s+server.*Admin Commands:\s+migrate.*Additional Commands:.*version`, out.String())
+}
+
+func TestAutoCommandDisableDefaultCommands(t *testing.T) {
+	// names returns the names of the available subcommands of root
+	names := func(opts ...stdfx.RootCommandOption) []string {
+		out := &bytes.Buffer{}
+		var root *cobra.Command
+		app := fx.New(
+			fx.NopLogger,
+			fx.Provide(
+				stdfx.AutoRegister(func() *cobra.Command {
+					return &cobra.Command{Use: "server", Run: func(*cobra.Command, []string) {}}
+				}),
+				stdfx.AutoCommandWithOptions(append(opts, stdfx.WithRootOut(out))...),
+			),
+			fx.Populate(&root),
+		)
+		require.NoError(t, app.Err())
+
+		// cobra adds default commands on execute
+		root.SetArgs([]string{"--help"})
+		require.NoError(t, root.Execute())
+
+		names := []string{}
+		for _, c := range root.Commands() {
+			if c.IsAvailableCommand() || c.Name() == "help" {
+				names = append(names, c.Name())
+			}
+		}
+		return names
+	}
+
+	assert.ElementsMatch(t, []string{"completion", "help", "server"}, names())
+	assert.ElementsMatch(t, []string{"server"}, names(
+		stdfx.WithRootDisableCompletion(),
+		stdfx.WithRootDisableHelpCommand(),
+	))
+}
+
+func TestAutoCommandDisableHelpCommand(t *testing.T) {
+	// execute runs the root command using args and returns its error and output
+	execute := func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		var root *cobra.Command
+		app := fx.New(
+			fx.NopLogger,
+			fx.Provide(
+				stdfx.AutoRegister(func() *cobra.Command {
+					return &cobra.Command{Use: "server", Run: func(*cobra.Command, []string) {}}
+				}),
+				stdfx.AutoCommandWithOptions(
+					stdfx.WithRootDisableHelpCommand(),
+					stdfx.WithRootOut(out),
+					stdfx.WithRootErr(out),
+					stdfx.WithRootDecorator(func(cmd *cobra.Command) {
+						cmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+							cmd.Println("decorated")
+						}
+					}),
+				),
+			),
+			fx.Populate(&root),
+		)
+		require.NoError(t, app.Err())
+
+		root.SetArgs(args)
+		err := root.Execute()
+		return out.String(), err
+	}
+
+	for _, name := range []string{"help", "no-help"} {
+		out, err := execute(name)
+		assert.ErrorContains(t, err, "unknown command", name)
+		assert.Contains(t, out, "unknown command", name)
+	}
+
+	out, err := execute("__complete", "")
+	require.NoError(t, err)
+	assert.Contains(t, out, "server")
+	assert.NotContains(t, out, "help")
+
+	out, err = execute("server")
+	require.NoError(t, err)
+	assert.Contains(t, out, "decorated")
+}
+
+func TestAutoCommandDuplicate(t *testing.T) {
+	// newServer returns a constructor of a server command
+	newServer := func(short string) func() *cobra.Command {
+		return func() *cobra.Command {
+			return &cobra.Command{Use: "server [flags]", Short: short}
+		}
+	}
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Provide(
+			stdfx.AutoRegister(newServer("first")),
+			stdfx.AutoRegister(newServer("second")),
+			stdfx.AutoCommand,
+		),
+		fx.Invoke(func(*cobra.Command) {}),
+	)
+
+	err := app.Err()
+	assert.ErrorIs(t, err, stdfx.ErrDuplicateCommand)
+	assert.ErrorContains(t, err, `"server" registered 2 times`)
+}
+
+func TestCommanderExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "success", err: nil, code: 0},
+		{name: "plain error", err: errors.New("failed"), code: 1},
+		{name: "exit error", err: stdfx.ExitError{Code: 3, Err: errors.New("failed")}, code: 3},
+		{name: "wrapped exit error", err: fmt.Errorf("run: %w", &stdfx.ExitError{Code: 4}), code: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{
+				Use: "exit",
+				RunE: func(cmd *cobra.Command, args []string) error {
+					time.Sleep(50 * time.Millisecond) // fail after starting
+					return tt.err
+				},
+				SilenceErrors: true,
+				SilenceUsage:  true,
+			}
+			cmd.SetArgs([]string{})
+
+			app := fx.New(
+				fx.NopLogger,
+				fx.Supply(cmd, slog.New(slog.DiscardHandler)),
+				fx.Invoke(stdfx.CommanderWithOptions(
+					stdfx.WithStartBackoff(time.Millisecond),
+				)),
+			)
+			require.NoError(t, app.Start(context.Background()))
+			defer app.Stop(context.Background()) // nolint:errcheck
+
+			select {
+			case sig := <-app.Wait():
+				assert.Equal(t, tt.code, sig.ExitCode)
+			case <-time.After(5 * time.Second):
+				t.Fatal("app did not shutdown")
+			}
+		})
+	}
+}
+
+func TestCommanderWithRecover(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "panic",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			time.Sleep(50 * time.Millisecond) // panic after starting
+			panic("boom")
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	cmd.SetArgs([]string{})
+
+	out := &bytes.Buffer{}
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(cmd, slog.New(slog.NewJSONHandler(out, nil))),
+		fx.Invoke(stdfx.CommanderWithOptions(
+			stdfx.WithRecover(),
+			stdfx.WithStartBackoff(time.Millisecond),
+		)),
+	)
+	require.NoError(t, app.Start(context.Background()))
+	defer app.Stop(context.Background()) // nolint:errcheck
+
+	select {
+	case sig := <-app.Wait():
+		assert.Equal(t, 1, sig.ExitCode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("app did not shutdown")
+	}
+
+	assert.Contains(t, out.String(), `"msg":"command panicked"`)
+	assert.Contains(t, out.String(), `"panic":"boom"`)
+	assert.Contains(t, out.String(), `"stack":"goroutine`)
+}
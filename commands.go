@@ -17,31 +17,62 @@ limitations under the License.
 package stdfx
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/choopm/stdfx/configfx"
 	"github.com/choopm/stdfx/globals"
+	"github.com/choopm/stdfx/loggingfx"
 	"github.com/earthboundkid/versioninfo/v2"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/xhit/go-str2duration/v2"
 	"sigs.k8s.io/yaml"
 )
 
 // AppVersion is the version given to [VersionCommand]
 var AppVersion = "unknown"
 
+// DefaultConfigValidateTimeout is the default for the --timeout flag
+// of the validate subcommand of [ConfigCommand]
+const DefaultConfigValidateTimeout = 30 * time.Second
+
+// ErrConfigLoadTimeout is returned by the validate subcommand of
+// [ConfigCommand] when loading the config exceeds its --timeout
+var ErrConfigLoadTimeout = errors.New("timed out loading configuration")
+
+var (
+	// ErrVersionDirty is returned by [VersionCommand] using --fail-on-dirty
+	// if the build contains uncommitted changes
+	ErrVersionDirty = errors.New("build is dirty")
+	// ErrVersionUnknown is returned by [VersionCommand] using --fail-on-unknown
+	// if no version was given
+	ErrVersionUnknown = errors.New("version is unknown")
+)
+
 // VersionCommand a version *cobra.Command constructor to print version information.
 // Supply your build tag as version and it will add runtime and compiler details.
+// Use --fail-on-dirty and --fail-on-unknown to exit non-zero in release pipelines.
 func VersionCommand(version string) func(log *slog.Logger) *cobra.Command {
 	if version != "" {
 		AppVersion = version
 	}
 
 	return func(log *slog.Logger) *cobra.Command {
+		var failOnDirty, failOnUnknown bool
 		cmd := &cobra.Command{
 			Use:   "version",
 			Short: "print version and exit",
@@ -58,9 +89,20 @@ func VersionCommand(version string) func(log *slog.Logger) *cobra.Command {
 				)
 			},
 		}
+		cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			cmd.Run(cmd, args)
+
+			// errors are returned as non-zero exit codes by the Commander
+			cmd.SilenceUsage = true
+			return checkVersion(failOnDirty, failOnUnknown)
+		}
+		cmd.Flags().BoolVar(&failOnDirty, "fail-on-dirty", false,
+			"exit non-zero if the build contains uncommitted changes")
+		cmd.Flags().BoolVar(&failOnUnknown, "fail-on-unknown", false,
+			"exit non-zero if the version is unknown")
 
 		// add a flag
-		versionFlag := globals.RootFlags.BoolP("version", "v",
+		versionFlag := globals.RootBool("version", "v",
 			false, "print version and exit")
 
 		// add a hook to print version and quit
@@ -82,6 +124,19 @@ func VersionCommand(version string) func(log *slog.Logger) *cobra.Command {
 	}
 }
 
+// checkVersion returns [ErrVersionDirty] or [ErrVersionUnknown]
+// if requested and the build is dirty or its version is unknown
+func checkVersion(failOnDirty, failOnUnknown bool) error {
+	if failOnDirty && versioninfo.DirtyBuild {
+		return ErrVersionDirty
+	}
+	if failOnUnknown && (AppVersion == "" || AppVersion == "unknown") {
+		return ErrVersionUnknown
+	}
+
+	return nil
+}
+
 // ConfigCommand is a *cobra.Command constructor to print, modify and validate config.
 func ConfigCommand[T any](
 	log *slog.Logger,
@@ -96,6 +151,7 @@ func ConfigCommand[T any](
 	}
 
 	// show subcommand
+	var showFormat string
 	showCmd := &cobra.Command{
 		Use:     "show",
 		Aliases: []string{"print"},
@@ -107,14 +163,75 @@ func ConfigCommand[T any](
 			}
 			v := configProvider.Viper()
 
+			// print serialized config to stdout if requested
+			if len(showFormat) > 0 {
+				b, err := configfx.Encode(cfg, showFormat)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(b)
+				return err
+			}
+
 			log.Info("configuration",
 				slog.String("file", v.ConfigFileUsed()),
 				slog.Any("parsed", cfg))
 			return nil
 		},
 	}
+	showCmd.Flags().StringVar(&showFormat, "format", "",
+		"print configuration to stdout using format, one of: "+
+			strings.Join(configfx.EncodeFormats, "|"))
 	cmd.AddCommand(showCmd)
 
+	// defaults subcommand
+	defaultsFormat := "yaml"
+	defaultsCmd := &cobra.Command{
+		Use:   "defaults",
+		Short: "print the default configuration without reading any file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := configfx.Defaults[T]()
+			if err != nil {
+				return err
+			}
+
+			b, err := configfx.Encode(cfg, defaultsFormat)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(b)
+			return err
+		},
+	}
+	defaultsCmd.Flags().StringVar(&defaultsFormat, "format", defaultsFormat,
+		"print configuration to stdout using format, one of: "+
+			strings.Join(configfx.EncodeFormats, "|"))
+	cmd.AddCommand(defaultsCmd)
+
+	// diff subcommand
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "print a diff of config file and effective configuration including defaults",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := configProvider.Config()
+			if err != nil {
+				return err
+			}
+
+			settings, err := configfx.FileSettings(configProvider)
+			if err != nil {
+				return err
+			}
+			diff, err := configDiff(configProvider.Viper().ConfigFileUsed(), settings, cfg)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), diff)
+			return err
+		},
+	}
+	cmd.AddCommand(diffCmd)
+
 	// get subcommand
 	getCmd := &cobra.Command{
 		Use:   "get [key]...",
@@ -127,10 +244,16 @@ func ConfigCommand[T any](
 			}
 			v := configProvider.Viper()
 
-			// get values
+			// get values, numeric key segments index into lists
+			settings := v.AllSettings()
 			attrs := []any{}
 			for _, key := range args {
-				value := v.Get(key)
+				value, ok := configfx.GetPath(settings, key)
+				if !ok {
+					value = v.Get(key)
+				}
+				// align env provided strings to the type of T
+				value = configfx.CoerceValue[T](key, value)
 				attrs = append(attrs, slog.Any(key, value))
 			}
 
@@ -140,27 +263,80 @@ func ConfigCommand[T any](
 	}
 	cmd.AddCommand(getCmd)
 
+	// env subcommand
+	envCmd := &cobra.Command{
+		Use:   "env",
+		Short: "list environment variables overriding configuration keys",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v := configProvider.Viper()
+			keys := append(configfx.Keys[T](), v.AllKeys()...)
+			allowlist := configfx.EnvAllowlist(configProvider)
+			overrides := envOverrides(v.GetEnvPrefix(), keys, os.Environ())
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, o := range overrides {
+				status := ""
+				switch {
+				case !o.known:
+					status = "unknown config key"
+				case !configfx.EnvAllowed(allowlist, o.key):
+					status = "ignored, not in env allowlist"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\n", o.name, o.key, status)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.AddCommand(envCmd)
+
 	// set subcommand
 	setCmd := &cobra.Command{
 		Use:   "set [key=value]...",
 		Short: "set value(s) by key from configuration",
 		Args:  cobra.MinimumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			_, err := configProvider.Config()
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			_, err = configProvider.Config()
 			if err != nil {
 				return err
 			}
 			v := configProvider.Viper()
 
-			// update state
+			if v.ConfigFileUsed() == configfx.StdinFilename {
+				return fmt.Errorf("config read from stdin can't be modified")
+			}
+
+			// lock the config file against concurrent modifications
+			// and read it again to not lose updates made in between
+			unlock, err := configfx.LockFile(v.ConfigFileUsed())
+			if err != nil {
+				return err
+			}
+			defer Defer(&err, unlock)
+			err = v.ReadInConfig()
+			if err != nil {
+				return err
+			}
+
+			// update state, numeric key segments index into lists
+			// which requires to rebuild the nested structure
+			settings := v.AllSettings()
 			attrs := []any{}
 			for _, arg := range args {
 				key, value, found := strings.Cut(arg, "=")
 				if !found {
 					return fmt.Errorf("invalid syntax in %q, use key=value", arg)
 				}
-				v.Set(key, value)
-				attrs = append(attrs, slog.Any(key, value))
+				current, _ := configfx.GetPath(settings, key)
+				parsed, err := parseSetValue[T](key, value, current)
+				if err != nil {
+					return err
+				}
+				if err := configfx.SetPath(settings, key, parsed); err != nil {
+					return err
+				}
+				root, _, _ := strings.Cut(strings.ToLower(key), ".")
+				v.Set(root, settings[root])
+				attrs = append(attrs, slog.Any(key, parsed))
 			}
 
 			// persist changes
@@ -176,57 +352,237 @@ func ConfigCommand[T any](
 	cmd.AddCommand(setCmd)
 
 	// validate subcommand
+	var validateTimeout time.Duration
 	validateCmd := &cobra.Command{
 		Use:     "validate",
 		Aliases: []string{"test"},
 		Short:   "test or validate configuration",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// validate viper parsing
-			v := configProvider.Viper()
-			err := v.ReadInConfig()
-			if err != nil {
-				return err
+			// bound loading the config to not hang on stuck sources
+			ctx := cmd.Context()
+			if validateTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, validateTimeout)
+				defer cancel()
 			}
 
-			// more strict config parsing
-			b, err := os.ReadFile(v.ConfigFileUsed())
-			if err != nil {
-				return err
-			}
-			switch t := strings.ToLower(filepath.Ext(v.ConfigFileUsed())); t {
-			case "yaml":
-				// more strict yaml parsing by using k8s parser:
-				log.Debug("using strict yaml parser",
-					slog.String("type", t))
-				err := yaml.Unmarshal(b, &struct{}{})
-				if err != nil {
-					return err
-				}
-			default:
-				log.Debug("missing strict parser for config",
-					slog.String("type", t))
-			}
-
-			// validate config hook
-			cfg, err := configProvider.Config()
-			if err != nil {
-				return err
-			}
-			if ctype, ok := any(cfg).(configfx.CustomValidator); ok {
-				// T implements CustomValidator and therefore
-				// has a custom func Validate(), use it:
-				log.Debug("found custom config Validate()")
-				if err := ctype.Validate(); err != nil {
-					return err
-				}
+			err := validateConfig(ctx, log, configProvider)
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("%w after %s", ErrConfigLoadTimeout, validateTimeout)
 			}
-
-			log.Info("configuration ok",
-				slog.String("file", v.ConfigFileUsed()))
-			return nil
+			return err
 		},
 	}
+	validateCmd.Flags().DurationVar(&validateTimeout, "timeout",
+		DefaultConfigValidateTimeout,
+		"maximum duration to load and validate configuration, 0 disables it")
 	cmd.AddCommand(validateCmd)
 
 	return cmd
 }
+
+// validateConfig loads and validates the config of configProvider.
+// Loading the config and its files is bounded by ctx.
+func validateConfig[T any](
+	ctx context.Context,
+	log *slog.Logger,
+	configProvider configfx.Provider[T],
+) error {
+	// validate viper parsing
+	cfg, err := configfx.ConfigContext(ctx, configProvider)
+	if err != nil {
+		return err
+	}
+
+	// more strict config parsing of the files as read by viper
+	files, err := configfx.ConfigFiles(ctx, configProvider)
+	if errors.Is(err, configfx.ErrNoConfigFile) {
+		log.Debug("missing config files for strict parsing",
+			slog.Any("error", err))
+	} else if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := validateConfigFile[T](log, file); err != nil {
+			if len(files) > 1 {
+				return fmt.Errorf("%s: %w", file.Name, err)
+			}
+			return err
+		}
+	}
+
+	// validate config hook
+	if ctype, ok := any(cfg).(configfx.CustomValidator); ok {
+		// T implements CustomValidator and therefore
+		// has a custom func Validate(), use it:
+		log.Debug("found custom config Validate()")
+		if err := ctype.Validate(); err != nil {
+			return err
+		}
+	}
+	if ctype, ok := any(cfg).(loggingfx.ConfigWithLogging); ok {
+		// T carries a logging config, check what adapters would reject
+		log.Debug("found config LoggingConfig()")
+		if err := ctype.LoggingConfig().Validate(); err != nil {
+			return err
+		}
+	}
+
+	log.Info("configuration ok",
+		slog.String("file", configProvider.Viper().ConfigFileUsed()))
+	return nil
+}
+
+// validateConfigFile parses file strictly if a strict parser
+// exists for its type
+func validateConfigFile[T any](log *slog.Logger, file configfx.ConfigFile) error {
+	switch file.Type {
+	case "yaml", "yml":
+		// more strict yaml parsing by using k8s parser:
+		log.Debug("using strict yaml parser",
+			slog.String("type", file.Type))
+		return yaml.Unmarshal(file.Content, &struct{}{})
+	case "json":
+		// strict json parsing rejecting unknown keys and trailing data
+		log.Debug("using strict json parser",
+			slog.String("type", file.Type))
+		return configfx.StrictJSON[T](file.Content)
+	default:
+		log.Debug("missing strict parser for config",
+			slog.String("type", file.Type))
+		return nil
+	}
+}
+
+// envOverride is an environment variable overriding a config key
+type envOverride struct {
+	name  string
+	key   string
+	known bool
+}
+
+// envOverrides returns all variables of environ starting with prefix and
+// the config key each would override. Variables not matching any of keys
+// are reported as unknown using a guessed key. Without prefix only
+// variables matching keys are returned.
+func envOverrides(prefix string, keys []string, environ []string) []envOverride {
+	known := map[string]string{}
+	for _, key := range keys {
+		known[configfx.EnvVarName(prefix, key)] = key
+	}
+
+	overrides := []envOverride{}
+	for _, env := range environ {
+		name, _, _ := strings.Cut(env, "=")
+		if key, ok := known[name]; ok {
+			overrides = append(overrides, envOverride{name: name, key: key, known: true})
+			continue
+		}
+
+		rest, ok := strings.CutPrefix(name, strings.ToUpper(prefix)+"_")
+		if len(prefix) == 0 || !ok {
+			continue
+		}
+		overrides = append(overrides, envOverride{
+			name: name,
+			key:  strings.ToLower(strings.ReplaceAll(rest, "_", ".")),
+		})
+	}
+	slices.SortFunc(overrides, func(a, b envOverride) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return overrides
+}
+
+// configDiff returns a unified diff of the settings found in filename
+// and the effective config cfg. Keys are compared case-insensitive
+// like viper does, therefore both sides use lowercase keys.
+func configDiff(filename string, settings map[string]any, cfg any) (string, error) {
+	b, err := configfx.Encode(cfg, "yaml")
+	if err != nil {
+		return "", err
+	}
+	effective := viper.New()
+	effective.SetConfigType("yaml")
+	if err := effective.ReadConfig(bytes.NewReader(b)); err != nil {
+		return "", fmt.Errorf("read effective config: %s", err)
+	}
+
+	from, err := yaml.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	to, err := yaml.Marshal(effective.AllSettings())
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(string(from), "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(string(to), "\n")),
+		FromFile: filename,
+		ToFile:   "effective",
+		Context:  3,
+	})
+}
+
+var _durationType = reflect.TypeFor[time.Duration]()
+
+// parseSetValue converts value to the type of the field at key inside T
+// or to the type of current if T does not define key.
+// Durations are validated but kept as string to stay readable.
+// Lists and maps accept a JSON literal.
+// Any other value is returned as string.
+func parseSetValue[T any](key, value string, current any) (any, error) {
+	typ, ok := configfx.KeyType[T](key)
+	if !ok && current != nil {
+		typ, ok = reflect.TypeOf(current), true
+	}
+	if !ok {
+		return value, nil
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	var (
+		parsed any
+		err    error
+	)
+	switch kind := typ.Kind(); {
+	case typ == _durationType:
+		parsed = value
+		_, err = str2duration.ParseDuration(value)
+
+	case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+		// accept a JSON literal, otherwise decode hooks handle the string
+		if json.Unmarshal([]byte(value), &parsed) != nil {
+			return value, nil
+		}
+
+	case typ.PkgPath() != "":
+		// named types like enums are decoded by hooks
+		return value, nil
+
+	case kind == reflect.Bool:
+		parsed, err = strconv.ParseBool(value)
+
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		parsed, err = strconv.ParseInt(value, 10, typ.Bits())
+
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		parsed, err = strconv.ParseUint(value, 10, typ.Bits())
+
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		parsed, err = strconv.ParseFloat(value, typ.Bits())
+
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q for %q of type %s", value, key, typ)
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"testing"
+
+	"github.com/earthboundkid/versioninfo/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckVersion(t *testing.T) {
+	version, dirty := AppVersion, versioninfo.DirtyBuild
+	defer func() { AppVersion, versioninfo.DirtyBuild = version, dirty }()
+
+	// default behavior is unchanged
+	AppVersion, versioninfo.DirtyBuild = "unknown", true
+	assert.NoError(t, checkVersion(false, false))
+
+	assert.ErrorIs(t, checkVersion(false, true), ErrVersionUnknown)
+	assert.ErrorIs(t, checkVersion(true, false), ErrVersionDirty)
+
+	// clean tagged build
+	AppVersion, versioninfo.DirtyBuild = "v1.2.3", false
+	assert.NoError(t, checkVersion(true, true))
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/configfx"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stuckProvider is a configfx.Provider[T] blocking until release is closed
+type stuckProvider[T any] struct {
+	release chan struct{}
+}
+
+// Config implements configfx.Provider[T]
+func (p *stuckProvider[T]) Config(...configfx.ConfigOption) (*T, error) {
+	<-p.release
+	return new(T), nil
+}
+
+// Viper implements configfx.Provider[T]
+func (p *stuckProvider[T]) Viper() *viper.Viper {
+	<-p.release
+	return viper.New()
+}
+
+func TestConfigCommandValidateTimeout(t *testing.T) {
+	provider := &stuckProvider[struct{}]{release: make(chan struct{})}
+	defer close(provider.release)
+
+	cmd := stdfx.ConfigCommand[struct{}](slog.New(slog.DiscardHandler), provider)
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	cmd.SetArgs([]string{"validate", "--timeout", "100ms"})
+
+	start := time.Now()
+	err := cmd.Execute()
+
+	assert.ErrorIs(t, err, stdfx.ErrConfigLoadTimeout)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.Contains(t, errOut.String(), "Error: timed out loading configuration after 100ms")
+	assert.Contains(t, out.String(), "--timeout duration")
+}
+
+func TestConfigCommandValidateNormalized(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "10-host.json"),
+		[]byte("\xef\xbb\xbf{\r\n  \"host\": \"example.com\"\r\n}\r\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20-port.yaml"),
+		[]byte("port: 9090\r\n"), 0644))
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[defaultsConfig](configfx.NewSourceDir[defaultsConfig](dir)(log), log)
+	run := func() error {
+		cmd := stdfx.ConfigCommand[defaultsConfig](log, provider)
+		cmd.SetOut(io.Discard)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs([]string{"validate"})
+		return cmd.Execute()
+	}
+
+	// the strict parsers see the files as read by viper
+	require.NoError(t, run())
+
+	// every fragment is parsed strictly
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "30-unknown.json"),
+		[]byte(`{"unknown": true}`), 0644))
+	assert.ErrorContains(t, run(), "30-unknown.json")
+}
+
+type defaultsConfig struct {
+	Host string `mapstructure:"host" default:"localhost"`
+	Port int    `mapstructure:"port" default:"8080"`
+}
+
+func TestConfigCommandDefaults(t *testing.T) {
+	// reading the provider would block, defaults must not touch it
+	provider := &stuckProvider[defaultsConfig]{release: make(chan struct{})}
+	defer close(provider.release)
+
+	cmd := stdfx.ConfigCommand[defaultsConfig](slog.New(slog.DiscardHandler), provider)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"defaults", "--format", "json"})
+
+	require.NoError(t, cmd.Execute())
+	assert.JSONEq(t, `{"host":"localhost","port":8080}`, out.String())
+}
+
+// fileSource is a configfx.Source[T] reading an explicit config file
+type fileSource[T any] struct {
+	filename     string
+	envPrefix    string
+	envAllowlist []string
+}
+
+// EnvAllowlist implements configfx.EnvRestrictedSource
+func (s *fileSource[T]) EnvAllowlist() []string {
+	return s.envAllowlist
+}
+
+// Viper implements configfx.Source[T]
+func (s *fileSource[T]) Viper(opts ...viper.Option) *viper.Viper {
+	v := viper.NewWithOptions(opts...)
+	v.SetConfigFile(s.filename)
+	if len(s.envPrefix) > 0 {
+		v.SetEnvPrefix(s.envPrefix)
+		v.AutomaticEnv()
+	}
+	for _, key := range s.envAllowlist {
+		_ = v.BindEnv(key)
+	}
+	return v
+}
+
+type routesConfig struct {
+	Routes []struct {
+		Path    string `mapstructure:"path"`
+		Content string `mapstructure:"content"`
+	} `mapstructure:"routes"`
+}
+
+func TestConfigCommandGetSetIndexed(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(`
+routes:
+- path: /
+  content: hello world
+- path: /example
+  content: example
+`), 0644))
+
+	// run executes the config command using args and returns its log output
+	run := func(args ...string) string {
+		out := &bytes.Buffer{}
+		log := slog.New(slog.NewJSONHandler(out, nil))
+		provider := configfx.NewProvider[routesConfig](&fileSource[routesConfig]{filename: filename}, log)
+
+		cmd := stdfx.ConfigCommand[routesConfig](log, provider)
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	run("set", "routes.1.path=/changed")
+	assert.Contains(t, run("get", "routes.1.path"), `"routes.1.path":"/changed"`)
+	assert.Contains(t, run("get", "routes.0.content"), `"routes.0.content":"hello world"`)
+
+	cfg, err := configfx.NewProvider[routesConfig](
+		&fileSource[routesConfig]{filename: filename},
+		slog.New(slog.DiscardHandler),
+	).Config()
+	require.NoError(t, err)
+	require.Len(t, cfg.Routes, 2)
+	assert.Equal(t, "/", cfg.Routes[0].Path)
+	assert.Equal(t, "/changed", cfg.Routes[1].Path)
+	assert.Equal(t, "example", cfg.Routes[1].Content)
+}
+
+type typedConfig struct {
+	Port    int           `mapstructure:"port"`
+	Debug   bool          `mapstructure:"debug"`
+	Timeout time.Duration `mapstructure:"timeout"`
+	Tags    []string      `mapstructure:"tags"`
+	Name    string        `mapstructure:"name"`
+}
+
+func TestConfigCommandSetTyped(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("port: 8080\n"), 0644))
+
+	// run executes the config command using args
+	run := func(args ...string) error {
+		log := slog.New(slog.DiscardHandler)
+		provider := configfx.NewProvider[typedConfig](&fileSource[typedConfig]{filename: filename}, log)
+
+		cmd := stdfx.ConfigCommand[typedConfig](log, provider)
+		cmd.SetArgs(args)
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return cmd.Execute()
+	}
+
+	require.NoError(t, run("set",
+		"port=9000",
+		"debug=true",
+		"timeout=30s",
+		`tags=["a","b"]`,
+		"name=1234",
+	))
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.YAMLEq(t, `
+port: 9000
+debug: true
+timeout: 30s
+tags: [a, b]
+name: "1234"
+`, string(b))
+
+	// durations support days and weeks like the config decoder does
+	require.NoError(t, run("set", "timeout=1d"))
+	cfg, err := configfx.NewProvider[typedConfig](
+		&fileSource[typedConfig]{filename: filename},
+		slog.New(slog.DiscardHandler),
+	).Config()
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, cfg.Timeout)
+
+	assert.ErrorContains(t, run("set", "port=abc"), `invalid value "abc" for "port" of type int`)
+	assert.ErrorContains(t, run("set", "timeout=soon"), `invalid value "soon" for "timeout" of type time.Duration`)
+}
+
+func TestConfigCommandDiff(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("port: 9090\n"), 0644))
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[defaultsConfig](&fileSource[defaultsConfig]{filename: filename}, log)
+	cmd := stdfx.ConfigCommand[defaultsConfig](log, provider)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"diff"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Contains(t, out.String(), "--- "+filename)
+	assert.Contains(t, out.String(), "+++ effective")
+	assert.Contains(t, out.String(), "+host: localhost") // defaulted
+	assert.Contains(t, out.String(), " port: 9090")      // from file
+}
+
+func TestConfigCommandEnv(t *testing.T) {
+	t.Setenv("STDFXTEST_HOST", "example.com")
+	t.Setenv("STDFXTEST_PROT", "9000")
+	t.Setenv("OTHER_PORT", "1")
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[defaultsConfig](&fileSource[defaultsConfig]{
+		filename:  filepath.Join(t.TempDir(), "config.yaml"),
+		envPrefix: "STDFXTEST",
+	}, log)
+	cmd := stdfx.ConfigCommand[defaultsConfig](log, provider)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"env"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, ""+
+		"STDFXTEST_HOST  host  \n"+
+		"STDFXTEST_PROT  prot  unknown config key\n",
+		out.String())
+}
+
+func TestConfigCommandEnvAllowlist(t *testing.T) {
+	t.Setenv("STDFXTEST_HOST", "example.com")
+	t.Setenv("STDFXTEST_PORT", "9000")
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[defaultsConfig](&fileSource[defaultsConfig]{
+		filename:     filepath.Join(t.TempDir(), "config.yaml"),
+		envPrefix:    "STDFXTEST",
+		envAllowlist: []string{"port"},
+	}, log)
+	cmd := stdfx.ConfigCommand[defaultsConfig](log, provider)
+	out := &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetArgs([]string{"env"})
+
+	require.NoError(t, cmd.Execute())
+	assert.Equal(t, ""+
+		"STDFXTEST_HOST  host  ignored, not in env allowlist\n"+
+		"STDFXTEST_PORT  port  \n",
+		out.String())
+}
@@ -17,21 +17,32 @@ limitations under the License.
 package stdfx
 
 import (
+	"fmt"
 	"log/slog"
 
 	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/globals"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
 )
 
 // ConfigFile provides your fx.App with a ConfigProvider[T] constructor.
 // The provider - when being constructed - can be used to search for,
 // read and unmarshal your config file to a struct of type *T or error.
 // [configName] shall be the name of a config file without extension.
+// Any opts are passed to [configfx.NewSourceFileWithOptions].
 // Internally this curries both functions [config.NewSourceFile] and
 // [config.NewProvider] for syntactic sugar.
 // Usage example:
 //
 //	fx.Provide(stdfx.Config[mypkg.ConfStruct]("configname")),
 //
+// To skip searching the working directory for config files:
+//
+//	fx.Provide(stdfx.ConfigFile[mypkg.ConfStruct]("configname",
+//		configfx.WithoutWorkingDirSearch(),
+//	)),
+//
 // After providing as described above, you will be able to request
 // this provider to fetch the actual config *struct like this:
 //
@@ -47,12 +58,65 @@ import (
 //	}
 func ConfigFile[T any](
 	configName string,
+	opts ...configfx.SourceFileOption,
 ) func(log *slog.Logger) configfx.Provider[T] {
 	return func(log *slog.Logger) configfx.Provider[T] {
-		buildSource := configfx.NewSourceFile[T](configName)
+		buildSource := configfx.NewSourceFileWithOptions[T](configName, opts...)
 		return configfx.NewProvider[T](
 			buildSource(log),
 			log,
 		)
 	}
 }
+
+// ConfigValue returns an annotated constructor of *T using the provider
+// of [ConfigFile]. It allows to request the config directly instead of
+// its provider:
+//
+//	fx.Provide(stdfx.ConfigFile[mypkg.ConfStruct]("configname")),
+//	fx.Provide(stdfx.ConfigValue[mypkg.ConfStruct]()),
+//	fx.Provide(stdfx.AutoRegister(func(cfg *mypkg.ConfStruct) *cobra.Command {
+//		// ...
+//	})),
+//
+// The config is parsed by a persistent pre-run of [AutoCommand] once the
+// root command parsed its flags like --config-path or --profile, errors
+// fail the command before it runs. The returned *T is empty until then,
+// read it only while a command runs, not in constructors.
+// [AutoCommand] sets cobra.EnableTraverseRunHooks, so the pre-run is
+// called for commands defining their own PersistentPreRun(E) as well.
+// Root commands built otherwise must call the hooks of their parents.
+// Commands which must work without a valid config, like [VersionCommand]
+// or [ConfigCommand], should keep using the provider.
+// The returned *T is not updated on config changes.
+func ConfigValue[T any]() any {
+	return fx.Annotate(
+		func(provider configfx.Provider[T]) (*T, rootPreRun) {
+			cfg := new(T)
+			return cfg, func(*cobra.Command, []string) error {
+				parsed, err := provider.Config()
+				if err != nil {
+					return fmt.Errorf("config: %s", err)
+				}
+				*cfg = *parsed
+
+				return nil
+			}
+		},
+		fx.ResultTags(``, `group:"rootPreRuns"`),
+	)
+}
+
+// Profile returns the config profile selected using the --profile flag
+// of [ConfigFile] or an empty string if none was selected.
+// Use it to adjust application behavior per dev/staging/prod profile.
+// The profile config file <configName>.<profile>.<ext> overrides the
+// base config file and is overridden by environment variables.
+func Profile() string {
+	flag := globals.RootFlags.Lookup("profile")
+	if flag == nil {
+		return ""
+	}
+
+	return flag.Value.String()
+}
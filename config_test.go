@@ -0,0 +1,168 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/globals"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+// failingProvider is a configfx.Provider[T] failing with err
+type failingProvider[T any] struct {
+	err error
+}
+
+// Config implements configfx.Provider[T]
+func (p *failingProvider[T]) Config(...configfx.ConfigOption) (*T, error) {
+	return nil, p.err
+}
+
+// Viper implements configfx.Provider[T]
+func (p *failingProvider[T]) Viper() *viper.Viper {
+	return viper.New()
+}
+
+// configValueApp returns the root command of an app using opts to provide
+// configfx.Provider[defaultsConfig]. Its command "server" stores a copy of
+// the config value in got.
+func configValueApp(t *testing.T, got **defaultsConfig, opts ...fx.Option) *cobra.Command {
+	t.Helper()
+
+	var root *cobra.Command
+	app := fx.New(
+		fx.NopLogger,
+		fx.Options(opts...),
+		fx.Provide(
+			stdfx.ConfigValue[defaultsConfig](),
+			stdfx.AutoRegister(func(cfg *defaultsConfig) *cobra.Command {
+				return &cobra.Command{
+					Use: "server",
+					Run: func(cmd *cobra.Command, args []string) {
+						copied := *cfg
+						*got = &copied
+					},
+				}
+			}),
+			stdfx.AutoCommand,
+		),
+		fx.Populate(&root),
+	)
+	require.NoError(t, app.Err())
+
+	return root
+}
+
+func TestConfigValue(t *testing.T) {
+	var got *defaultsConfig
+	root := configValueApp(t, &got,
+		fx.Provide(func() configfx.Provider[defaultsConfig] {
+			return configfx.NewStaticProvider(&defaultsConfig{Host: "example.com", Port: 443})
+		}),
+	)
+
+	root.SetArgs([]string{"server"})
+	require.NoError(t, root.Execute())
+	assert.Equal(t, &defaultsConfig{Host: "example.com", Port: 443}, got)
+}
+
+func TestConfigValueFlags(t *testing.T) {
+	globals.ResetRootFlags()
+	t.Cleanup(globals.ResetRootFlags)
+
+	// the config file is only found using the config path flag
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "server.yaml"),
+		[]byte("host: example.com\nport: 443\n"), 0o644))
+
+	var got *defaultsConfig
+	root := configValueApp(t, &got,
+		fx.Provide(
+			func() *slog.Logger { return slog.New(slog.DiscardHandler) },
+			stdfx.ConfigFile[defaultsConfig]("server",
+				configfx.WithSearchPaths(t.TempDir()),
+				configfx.WithoutWorkingDirSearch(),
+			),
+		),
+	)
+
+	root.SetArgs([]string{"-c", dir, "server"})
+	require.NoError(t, root.Execute())
+	assert.Equal(t, &defaultsConfig{Host: "example.com", Port: 443}, got)
+}
+
+func TestConfigValueOwnPreRun(t *testing.T) {
+	// commands defining their own persistent pre-run get the config as well
+	var got, other *defaultsConfig
+	preRan := false
+	root := configValueApp(t, &got,
+		fx.Provide(
+			func() configfx.Provider[defaultsConfig] {
+				return configfx.NewStaticProvider(&defaultsConfig{Host: "example.com", Port: 443})
+			},
+			stdfx.AutoRegister(func(cfg *defaultsConfig) *cobra.Command {
+				return &cobra.Command{
+					Use: "worker",
+					PersistentPreRunE: func(*cobra.Command, []string) error {
+						preRan = true
+						return nil
+					},
+					Run: func(cmd *cobra.Command, args []string) {
+						copied := *cfg
+						other = &copied
+					},
+				}
+			}),
+		),
+	)
+
+	root.SetArgs([]string{"worker"})
+	require.NoError(t, root.Execute())
+	assert.True(t, preRan)
+	assert.Equal(t, &defaultsConfig{Host: "example.com", Port: 443}, other)
+	assert.Nil(t, got)
+}
+
+func TestConfigValueError(t *testing.T) {
+	errBroken := errors.New("broken config")
+	var got *defaultsConfig
+	root := configValueApp(t, &got,
+		fx.Provide(func() configfx.Provider[defaultsConfig] {
+			return &failingProvider[defaultsConfig]{err: errBroken}
+		}),
+	)
+
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	root.SetOut(out)
+	root.SetErr(errOut)
+	root.SetArgs([]string{"server"})
+	assert.ErrorContains(t, root.Execute(), "config: broken config")
+	assert.Contains(t, errOut.String(), "Error: config: broken config")
+	assert.Nil(t, got)
+}
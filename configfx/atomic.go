@@ -0,0 +1,151 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Atomic holds a config of type T which is swapped on reloads.
+// Readers call Load to get the current config lock-free while Store
+// replaces it and notifies subscribers. Configs must not be modified
+// after Store, build a new one instead.
+// Usage example:
+//
+//	cfg, err := configfx.WatchAtomic(provider, log)
+//	...
+//	port := cfg.Load().Webserver.Port
+//	for newcfg := range cfg.Subscribe(ctx) {
+//		server.Reconfigure(newcfg)
+//	}
+type Atomic[T any] struct {
+	ptr atomic.Pointer[T]
+
+	mutex       sync.Mutex
+	subscribers map[chan *T]struct{}
+}
+
+// NewAtomic returns a new *Atomic[T] holding initial
+func NewAtomic[T any](initial *T) *Atomic[T] {
+	a := &Atomic[T]{
+		subscribers: map[chan *T]struct{}{},
+	}
+	a.ptr.Store(initial)
+
+	return a
+}
+
+// Load returns the current config
+func (a *Atomic[T]) Load() *T {
+	return a.ptr.Load()
+}
+
+// Store replaces the current config by t and notifies all subscribers
+func (a *Atomic[T]) Store(t *T) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.ptr.Store(t)
+	for ch := range a.subscribers {
+		// drop a pending config not yet received, subscribers
+		// are only interested in the most recent one
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- t
+	}
+}
+
+// Subscribe returns a channel receiving the config on every Store.
+// Slow subscribers only receive the most recent config.
+// The channel is closed once ctx is done.
+func (a *Atomic[T]) Subscribe(ctx context.Context) <-chan *T {
+	ch := make(chan *T, 1)
+
+	a.mutex.Lock()
+	a.subscribers[ch] = struct{}{}
+	a.mutex.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+
+		delete(a.subscribers, ch)
+		close(ch)
+	})
+
+	return ch
+}
+
+// WatchAtomic returns an *Atomic[T] holding the config of provider using opts.
+// The config is reloaded on changes and stored if it is valid, see
+// [WithOnConfigChange] and [CustomValidator]. Invalid configs are logged
+// and keep the current config in place.
+func WatchAtomic[T any](provider Provider[T], log *slog.Logger, opts ...ConfigOption) (*Atomic[T], error) {
+	a := NewAtomic[T](nil)
+
+	// reload reloads the config using the same opts
+	var watchOpts []ConfigOption
+	reload := func(in fsnotify.Event) {
+		cfg, err := provider.Config(watchOpts...)
+		if err != nil {
+			log.Error("reloading config",
+				slog.String("file", in.Name), slog.Any("error", err))
+			return
+		}
+		if err := validate(cfg); err != nil {
+			log.Error("reloading config",
+				slog.String("file", in.Name), slog.Any("error", err))
+			return
+		}
+		log.Info("config reloaded", slog.String("file", in.Name))
+		a.Store(cfg)
+	}
+	watchOpts = append(append([]ConfigOption{}, opts...), WithOnConfigChange(reload))
+
+	cfg, err := provider.Config(watchOpts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(cfg); err != nil {
+		return nil, err
+	}
+	// keep a config stored by a reload in the meantime
+	a.ptr.CompareAndSwap(nil, cfg)
+
+	return a, nil
+}
+
+// validate calls Validate of cfg if it implements [CustomValidator]
+func validate(cfg any) error {
+	ctype, ok := cfg.(CustomValidator)
+	if !ok {
+		return nil
+	}
+	if err := ctype.Validate(); err != nil {
+		return fmt.Errorf("validate config: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAtomicConcurrent(t *testing.T) {
+	a := configfx.NewAtomic(&profileConfig{Port: 0})
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := 0
+			for range 1000 {
+				cfg := a.Load()
+				if !assert.NotNil(t, cfg) {
+					return
+				}
+				// stores are monotonic, readers never see older configs
+				assert.GreaterOrEqual(t, cfg.Port, last)
+				last = cfg.Port
+			}
+		}()
+	}
+	for i := 1; i <= 1000; i++ {
+		a.Store(&profileConfig{Port: i})
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, a.Load().Port)
+}
+
+func TestAtomicSubscribe(t *testing.T) {
+	a := configfx.NewAtomic(&profileConfig{Port: 80})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changes := a.Subscribe(ctx)
+
+	a.Store(&profileConfig{Port: 8080})
+	select {
+	case cfg := <-changes:
+		assert.Equal(t, 8080, cfg.Port)
+	case <-time.After(time.Second):
+		t.Fatal("subscriber was not notified")
+	}
+
+	// slow subscribers only receive the most recent config
+	a.Store(&profileConfig{Port: 8081})
+	a.Store(&profileConfig{Port: 8082})
+	assert.Equal(t, 8082, (<-changes).Port)
+
+	cancel()
+	select {
+	case _, ok := <-changes:
+		assert.False(t, ok, "channel must be closed")
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed")
+	}
+
+	// stores after unsubscribing must not block
+	a.Store(&profileConfig{Port: 9090})
+	assert.Equal(t, 9090, a.Load().Port)
+}
+
+func TestWatchAtomic(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+host: example.com
+port: 8080
+`)
+
+	provider := newTestProvider[profileConfig](filename)
+	a, err := configfx.WatchAtomic(provider, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	assert.Equal(t, 8080, a.Load().Port)
+
+	changes := a.Subscribe(t.Context())
+	writeConfig(t, filepath.Dir(filename), "config.yaml", `
+host: example.com
+port: 9090
+`)
+	select {
+	case cfg := <-changes:
+		assert.Equal(t, 9090, cfg.Port)
+		assert.Equal(t, 9090, a.Load().Port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+}
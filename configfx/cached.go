@@ -0,0 +1,256 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// CachedProvider is a Provider[T] memoizing the config of a wrapped
+// Provider[T]. The config is parsed once and returned by every call of
+// Config until the config file changes or Reload is called.
+// Calls passing opts bypass the memoized config, see Config.
+// It is safe for concurrent use. The returned *T is shared by all
+// callers and must not be modified.
+type CachedProvider[T any] struct {
+	provider Provider[T]
+	opts     []ConfigOption
+	onChange ConfigOption
+
+	// loading serializes parsing the config,
+	// a channel to abort waiting for it once ctx is done
+	loading chan struct{}
+
+	mutex      sync.Mutex
+	config     *T
+	generation uint64
+
+	// callOnChange are the callbacks of [WithOnConfigChange]
+	// passed to calls of Config
+	callOnChange onChangeCallbacks
+}
+
+// ensure CachedProvider[T] implements ContextProvider[T] and io.Closer
+var (
+	_ ContextProvider[any] = &CachedProvider[any]{}
+	_ io.Closer            = &CachedProvider[any]{}
+)
+
+// NewCachedProvider returns a *CachedProvider[T] parsing the config of
+// provider using opts. The memoized config is invalidated whenever the
+// config file changes, callbacks of [WithOnConfigChange] given as opts
+// are invoked afterwards.
+// Usage example:
+//
+//	fx.Decorate(func(p configfx.Provider[Config]) configfx.Provider[Config] {
+//		return configfx.NewCachedProvider(p)
+//	}),
+func NewCachedProvider[T any](provider Provider[T], opts ...ConfigOption) *CachedProvider[T] {
+	p := &CachedProvider[T]{
+		provider: provider,
+		loading:  make(chan struct{}, 1),
+	}
+
+	// chain any callback of opts after invalidating the memoized config
+	cOpts := defaultConfigOptions()
+	for _, option := range opts {
+		option(cOpts)
+	}
+	onConfigChange := cOpts.onConfigChange
+	p.opts = opts
+	p.onChange = WithOnConfigChange(func(in fsnotify.Event) {
+		p.invalidate()
+		onConfigChange.call(in)
+		p.mutex.Lock()
+		callOnChange := slices.Clone(p.callOnChange)
+		p.mutex.Unlock()
+		callOnChange.call(in)
+	})
+
+	return p
+}
+
+// Config implements Provider[T].
+// It returns the memoized config or parses and memoizes it using the
+// opts of [NewCachedProvider] if none is memoized. Calls passing opts
+// always parse the config using the opts of [NewCachedProvider] followed
+// by opts without memoizing it, as the config depends on them.
+// Callbacks of [WithOnConfigChange] in opts are invoked after the ones
+// given to [NewCachedProvider] and chained with those of other calls.
+func (p *CachedProvider[T]) Config(opts ...ConfigOption) (*T, error) {
+	return p.ConfigContext(context.Background(), opts...)
+}
+
+// ConfigContext implements ContextProvider[T].
+// It behaves like Config but aborts parsing once ctx is done.
+func (p *CachedProvider[T]) ConfigContext(ctx context.Context, opts ...ConfigOption) (*T, error) {
+	if len(opts) == 0 {
+		if config := p.cached(); config != nil {
+			return config, nil
+		}
+	}
+
+	if err := p.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer p.unlock()
+
+	if len(opts) > 0 {
+		return p.parse(ctx, opts...)
+	}
+
+	// parsed by a concurrent call in the meantime
+	if config := p.cached(); config != nil {
+		return config, nil
+	}
+
+	return p.load(ctx)
+}
+
+// Reload parses the config again using the opts of [NewCachedProvider]
+// and memoizes it. Opts passed to earlier calls of Config are not used.
+func (p *CachedProvider[T]) Reload() (*T, error) {
+	p.invalidate()
+
+	if err := p.lock(context.Background()); err != nil {
+		return nil, err
+	}
+	defer p.unlock()
+
+	return p.load(context.Background())
+}
+
+// Viper implements Provider[T]
+func (p *CachedProvider[T]) Viper() *viper.Viper {
+	return p.provider.Viper()
+}
+
+// bindPFlag binds flag to key of the wrapped provider, see [BindFlags]
+func (p *CachedProvider[T]) bindPFlag(key string, flag *pflag.Flag) error {
+	if binder, ok := p.provider.(flagBinder); ok {
+		return binder.bindPFlag(key, flag)
+	}
+
+	return p.provider.Viper().BindPFlag(key, flag)
+}
+
+// Close implements io.Closer.
+// It closes the wrapped provider if it implements io.Closer.
+func (p *CachedProvider[T]) Close() error {
+	if closer, ok := p.provider.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// envAllowlist returns the allowlist of the wrapped provider,
+// see [EnvAllowlist]
+func (p *CachedProvider[T]) envAllowlist() []string {
+	return EnvAllowlist(p.provider)
+}
+
+// fileSettings returns the settings found in the config file
+// of the wrapped provider, see [FileSettings]
+func (p *CachedProvider[T]) fileSettings() (map[string]any, error) {
+	return FileSettings(p.provider)
+}
+
+// configFiles returns the config files of the wrapped provider,
+// see [ConfigFiles]
+func (p *CachedProvider[T]) configFiles() ([]ConfigFile, error) {
+	return ConfigFiles(context.Background(), p.provider)
+}
+
+// cached returns the memoized config or nil
+func (p *CachedProvider[T]) cached() *T {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.config
+}
+
+// lock acquires the right to parse the config or returns the error
+// of ctx once it is done while waiting for it
+func (p *CachedProvider[T]) lock(ctx context.Context) error {
+	select {
+	case p.loading <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// unlock releases the right to parse the config
+func (p *CachedProvider[T]) unlock() {
+	<-p.loading
+}
+
+// parse parses the config using the opts of [NewCachedProvider]
+// followed by opts. It must be called holding the lock.
+func (p *CachedProvider[T]) parse(ctx context.Context, opts ...ConfigOption) (*T, error) {
+	cOpts := defaultConfigOptions()
+	for _, option := range opts {
+		option(cOpts)
+	}
+	if len(cOpts.onConfigChange) > 0 {
+		p.mutex.Lock()
+		p.callOnChange = p.callOnChange.add(cOpts.onConfigChange...)
+		p.mutex.Unlock()
+	}
+
+	// callbacks are invoked by p.onChange after invalidating the config
+	all := append(append(append([]ConfigOption{}, p.opts...), opts...),
+		withoutOnConfigChange(), p.onChange)
+	return ConfigContext(ctx, p.provider, all...)
+}
+
+// load parses the config and memoizes it unless it has been
+// invalidated while parsing. It must be called holding the lock.
+func (p *CachedProvider[T]) load(ctx context.Context) (*T, error) {
+	p.mutex.Lock()
+	generation := p.generation
+	p.mutex.Unlock()
+
+	config, err := p.parse(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.generation == generation {
+		p.config = config
+	}
+
+	return config, nil
+}
+
+// invalidate drops the memoized config
+func (p *CachedProvider[T]) invalidate() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.config = nil
+	p.generation++
+}
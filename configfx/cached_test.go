@@ -0,0 +1,141 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedProvider(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+host: example.com
+port: 8080
+`)
+
+	changes := make(chan fsnotify.Event, 16)
+	provider := configfx.NewCachedProvider(newTestProvider[profileConfig](filename),
+		configfx.WithOnConfigChange(func(in fsnotify.Event) {
+			changes <- in
+		}),
+	)
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+
+	// repeated calls return the memoized config
+	again, err := provider.Config()
+	require.NoError(t, err)
+	assert.Same(t, cfg, again)
+
+	// reloading parses the config again
+	reloaded, err := provider.Reload()
+	require.NoError(t, err)
+	assert.NotSame(t, cfg, reloaded)
+	assert.Equal(t, *cfg, *reloaded)
+	again, err = provider.Config()
+	require.NoError(t, err)
+	assert.Same(t, reloaded, again)
+
+	// changes invalidate the memoized config
+	writeConfig(t, filepath.Dir(filename), "config.yaml", `
+host: example.com
+port: 9090
+`)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change callback was not invoked")
+	}
+	changed, err := provider.Config()
+	require.NoError(t, err)
+	assert.NotSame(t, reloaded, changed)
+	assert.Equal(t, 9090, changed.Port)
+}
+
+func TestCachedProviderConcurrent(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", "port: 8080\n")
+	provider := configfx.NewCachedProvider(newTestProvider[profileConfig](filename))
+
+	configs := make([]*profileConfig, 8)
+	var wg sync.WaitGroup
+	for i := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			configs[i], _ = provider.Config()
+		}()
+	}
+	wg.Wait()
+
+	for _, cfg := range configs {
+		assert.Same(t, configs[0], cfg)
+	}
+}
+
+func TestCachedProviderCallOptions(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+port: 8080
+unknown: true
+`)
+	provider := configfx.NewCachedProvider(newTestProvider[profileConfig](filename))
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+
+	// opts bypass the memoized config
+	_, err = provider.Config(configfx.WithStrictUnmarshal())
+	require.Error(t, err)
+
+	again, err := provider.Config()
+	require.NoError(t, err)
+	assert.Same(t, cfg, again)
+}
+
+func TestCachedProviderContextWhileLoading(t *testing.T) {
+	blocking := &blockingProvider[profileConfig]{
+		entered: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	provider := configfx.NewCachedProvider[profileConfig](blocking)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := provider.Config()
+		done <- err
+	}()
+	<-blocking.entered
+
+	// waiting for the concurrent load aborts once ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := provider.ConfigContext(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(blocking.release)
+	require.NoError(t, <-done)
+}
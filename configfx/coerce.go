@@ -0,0 +1,192 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// KeyType returns the type of the field addressed by the dotted
+// viper key inside T. Keys are matched case-insensitive against
+// `mapstructure:""` tags or field names like viper does.
+// It returns false if key can't be resolved.
+func KeyType[T any](key string) (reflect.Type, bool) {
+	typ := reflect.TypeFor[T]()
+	for _, part := range strings.Split(key, ".") {
+		var ok bool
+		typ, ok = childType(typ, part)
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return typ, true
+}
+
+// Keys returns the dotted viper keys of all fields inside T.
+// Nested structs are walked, fields of any other type including
+// maps and slices are returned as a single key.
+func Keys[T any]() []string {
+	keys := []string{}
+	collectKeys(reflect.TypeFor[T](), "", map[reflect.Type]bool{}, &keys)
+
+	return keys
+}
+
+// collectKeys appends the keys of all fields inside typ to keys
+func collectKeys(typ reflect.Type, key string, seen map[reflect.Type]bool, keys *[]string) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] || isValueStruct(typ) {
+		if len(key) > 0 {
+			*keys = append(*keys, key)
+		}
+		return
+	}
+	// guard against recursive types
+	seen[typ] = true
+	defer delete(seen, typ)
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// squashed structs contribute their fields to the parent
+		if strings.Contains(opts, "squash") {
+			collectKeys(field.Type, key, seen, keys)
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		collectKeys(field.Type, joinKey(key, strings.ToLower(name)), seen, keys)
+	}
+}
+
+var (
+	_textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	_urlType             = reflect.TypeFor[url.URL]()
+)
+
+// isValueStruct returns true for structs decoded from a single value
+// like time.Time or url.URL
+func isValueStruct(typ reflect.Type) bool {
+	return typ == _urlType || reflect.PointerTo(typ).Implements(_textUnmarshalerType)
+}
+
+// childType returns the type of the element called name inside typ
+func childType(typ reflect.Type, name string) (reflect.Type, bool) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		return fieldType(typ, name)
+
+	case reflect.Map:
+		return typ.Elem(), true
+
+	case reflect.Slice, reflect.Array:
+		if _, err := strconv.Atoi(name); err != nil {
+			return nil, false
+		}
+		return typ.Elem(), true
+
+	default:
+		return nil, false
+	}
+}
+
+// fieldType returns the type of the struct field called name inside typ.
+// Fields of squashed structs are searched as well.
+func fieldType(typ reflect.Type, name string) (reflect.Type, bool) {
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tagName, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if tagName == "-" {
+			continue
+		}
+
+		// squashed structs contribute their fields to the parent
+		if strings.Contains(opts, "squash") {
+			if t, ok := childType(field.Type, name); ok {
+				return t, true
+			}
+			continue
+		}
+
+		if len(tagName) == 0 {
+			tagName = field.Name
+		}
+		if strings.EqualFold(tagName, name) {
+			return field.Type, true
+		}
+	}
+
+	return nil, false
+}
+
+// CoerceValue converts the string value of key to the type of the
+// matching field in T using [DefaultDecoders].
+// This aligns values read by viper.Get, which are strings when
+// set by environment variables, with the type the application uses.
+// The value is returned as it is if it is no string, key can't be
+// resolved or conversion fails.
+func CoerceValue[T any](key string, value any) any {
+	if _, ok := value.(string); !ok {
+		return value
+	}
+	typ, ok := KeyType[T](key)
+	if !ok {
+		return value
+	}
+
+	out := reflect.New(typ)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.ComposeDecodeHookFunc(DefaultDecoders()...),
+		WeaklyTypedInput: true,
+		Result:           out.Interface(),
+	})
+	if err != nil {
+		return value
+	}
+	if err := decoder.Decode(value); err != nil {
+		return value
+	}
+
+	return out.Elem().Interface()
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+)
+
+type coerceConfig struct {
+	Webserver struct {
+		Host    string        `mapstructure:"host"`
+		Port    int           `mapstructure:"port"`
+		TLS     bool          `mapstructure:"tls"`
+		Timeout time.Duration `mapstructure:"readTimeout"`
+	} `mapstructure:"webserver"`
+
+	Limits map[string]float64 `mapstructure:"limits"`
+	Ports  []uint16           `mapstructure:"ports"`
+}
+
+func TestCoerceValue(t *testing.T) {
+	tests := []struct {
+		key   string
+		value any
+		want  any
+	}{
+		{"webserver.port", "8080", 8080},
+		{"webserver.tls", "true", true},
+		{"webserver.host", "localhost", "localhost"},
+		{"webserver.readtimeout", "1d", 24 * time.Hour},
+		{"limits.cpu", "1.5", 1.5},
+		{"ports.0", "443", uint16(443)},
+		{"webserver.port", 8080, 8080},           // no string
+		{"webserver.port", "invalid", "invalid"}, // conversion fails
+		{"unknown.key", "8080", "8080"},          // key not found
+	}
+
+	for _, test := range tests {
+		got := configfx.CoerceValue[coerceConfig](test.key, test.value)
+		assert.Equal(t, test.want, got, test.key)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	type KeysBase struct {
+		Host string `mapstructure:"host"`
+	}
+	type keysConfig struct {
+		KeysBase `mapstructure:",squash"`
+
+		Webserver struct {
+			Port        int           `mapstructure:"port"`
+			ReadTimeout time.Duration `mapstructure:"read-timeout"`
+		} `mapstructure:"webserver"`
+
+		Labels  map[string]string `mapstructure:"labels"`
+		Started time.Time         `mapstructure:"started"`
+		Ignored string            `mapstructure:"-"`
+	}
+
+	assert.Equal(t, []string{
+		"host",
+		"webserver.port",
+		"webserver.read-timeout",
+		"labels",
+		"started",
+	}, configfx.Keys[keysConfig]())
+}
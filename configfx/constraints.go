@@ -0,0 +1,176 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// validateConstraints checks the field group constraints of structs
+// found in v which are declared using struct tags:
+//
+//	// KeyFile requires CertFile to be set if set itself
+//	KeyFile string `mapstructure:"key" requiredWith:"CertFile"`
+//	// at most one field of the group "auth" may be set
+//	Token    string `mapstructure:"token" mutuallyExclusive:"auth"`
+//	Password string `mapstructure:"password" mutuallyExclusive:"auth"`
+//
+// requiredWith lists comma separated field names or keys of the same
+// struct. A field is considered set if it is not the zero value.
+// All violations are returned naming the full keys of the fields.
+func validateConstraints(v reflect.Value, key string) error {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return validateConstraints(v.Elem(), key)
+
+	case reflect.Slice, reflect.Array:
+		errs := []error{}
+		for i := range v.Len() {
+			errs = append(errs, validateConstraints(v.Index(i), fmt.Sprintf("%s[%d]", key, i)))
+		}
+		return errors.Join(errs...)
+
+	case reflect.Map:
+		errs := []error{}
+		iter := v.MapRange()
+		for iter.Next() {
+			errs = append(errs, validateConstraints(iter.Value(),
+				joinKey(key, fmt.Sprint(iter.Key().Interface()))))
+		}
+		return errors.Join(errs...)
+
+	case reflect.Struct:
+		return validateStructConstraints(v, key)
+
+	default:
+		return nil
+	}
+}
+
+// constraintField is a struct field taking part in constraints
+type constraintField struct {
+	name  string
+	key   string
+	value reflect.Value
+	field reflect.StructField
+}
+
+// validateStructConstraints validates the constraints of the struct v
+// and all of its fields.
+func validateStructConstraints(v reflect.Value, key string) error {
+	fields := constraintFields(v, key)
+	errs := []error{}
+
+	// requiredWith, collecting mutuallyExclusive groups on the way
+	groups := map[string][]*constraintField{}
+	groupOrder := []string{}
+	for _, f := range fields {
+		if group := f.field.Tag.Get("mutuallyExclusive"); len(group) > 0 {
+			if _, ok := groups[group]; !ok {
+				groupOrder = append(groupOrder, group)
+			}
+			groups[group] = append(groups[group], f)
+		}
+
+		required := f.field.Tag.Get("requiredWith")
+		if len(required) == 0 || f.value.IsZero() {
+			continue
+		}
+		for _, name := range strings.Split(required, ",") {
+			name = strings.TrimSpace(name)
+			idx := slices.IndexFunc(fields, func(other *constraintField) bool {
+				return other.name == name || strings.EqualFold(other.key, joinKey(key, name))
+			})
+			if idx < 0 {
+				errs = append(errs, fmt.Errorf("%q requires unknown field %q", f.key, name))
+				continue
+			}
+			if fields[idx].value.IsZero() {
+				errs = append(errs, fmt.Errorf("%q requires %q to be set", f.key, fields[idx].key))
+			}
+		}
+	}
+
+	// mutuallyExclusive
+	for _, group := range groupOrder {
+		set := []string{}
+		for _, f := range groups[group] {
+			if !f.value.IsZero() {
+				set = append(set, fmt.Sprintf("%q", f.key))
+			}
+		}
+		if len(set) > 1 {
+			errs = append(errs, fmt.Errorf("%s are mutually exclusive", strings.Join(set, ", ")))
+		}
+	}
+
+	// nested structs
+	for _, f := range fields {
+		errs = append(errs, validateConstraints(f.value, f.key))
+	}
+
+	return errors.Join(errs...)
+}
+
+// constraintFields returns the exported fields of the struct v.
+// Fields of squashed structs are returned as fields of v.
+func constraintFields(v reflect.Value, key string) []*constraintField {
+	fields := []*constraintField{}
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// squashed structs contribute their fields to the parent
+		fv := v.Field(i)
+		if strings.Contains(opts, "squash") {
+			for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				fields = append(fields, constraintFields(fv, key)...)
+			}
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		fields = append(fields, &constraintField{
+			name:  field.Name,
+			key:   joinKey(key, name),
+			value: fv,
+			field: field,
+		})
+	}
+
+	return fields
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tlsConfig struct {
+	CertFile string `mapstructure:"cert" requiredWith:"KeyFile"`
+	KeyFile  string `mapstructure:"key" requiredWith:"cert"`
+}
+
+type constraintsConfig struct {
+	TLS tlsConfig `mapstructure:"tls"`
+
+	Upstreams []struct {
+		Token    string `mapstructure:"token" mutuallyExclusive:"auth"`
+		Password string `mapstructure:"password" mutuallyExclusive:"auth"`
+	} `mapstructure:"upstreams"`
+}
+
+func TestProviderConstraints(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		errs    []string
+	}{
+		{
+			name: "valid",
+			content: `
+tls:
+  cert: tls.crt
+  key: tls.key
+upstreams:
+- token: abc
+- password: secret
+`,
+		},
+		{
+			name:    "neither set",
+			content: `upstreams: [{}]`,
+		},
+		{
+			name: "required together",
+			content: `
+tls:
+  key: tls.key
+`,
+			errs: []string{`"tls.key" requires "tls.cert" to be set`},
+		},
+		{
+			name: "mutually exclusive",
+			content: `
+tls:
+  cert: tls.crt
+upstreams:
+- token: abc
+- token: abc
+  password: secret
+`,
+			errs: []string{
+				`"tls.cert" requires "tls.key" to be set`,
+				`"upstreams[1].token", "upstreams[1].password" are mutually exclusive`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := writeConfig(t, t.TempDir(), "config.yaml", tt.content)
+			_, err := newTestProvider[constraintsConfig](filename).Config()
+			if len(tt.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			for _, msg := range tt.errs {
+				assert.ErrorContains(t, err, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowSource is a configfx.ContextSource blocking until release is
+// closed or the context is done
+type slowSource[T any] struct {
+	reading chan struct{}
+	release chan struct{}
+}
+
+// Viper implements configfx.Source[T]
+func (s *slowSource[T]) Viper(opts ...viper.Option) *viper.Viper {
+	return viper.NewWithOptions(opts...)
+}
+
+// ReadConfigContext implements configfx.ContextSource
+func (s *slowSource[T]) ReadConfigContext(ctx context.Context, v *viper.Viper) error {
+	close(s.reading)
+	select {
+	case <-s.release:
+		v.Set("port", 8080)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestConfigContext(t *testing.T) {
+	source := &slowSource[profileConfig]{
+		reading: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	defer close(source.release)
+	provider := configfx.NewProvider[profileConfig](source, slog.New(slog.DiscardHandler))
+
+	// cancel while the source is reading
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-source.reading
+		cancel()
+	}()
+
+	start := time.Now()
+	cfg, err := configfx.ConfigContext(ctx, provider)
+	assert.Nil(t, cfg)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// done contexts are rejected before reading
+	cfg, err = configfx.ConfigContext(ctx, provider)
+	assert.Nil(t, cfg)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestConfigContextFallback(t *testing.T) {
+	// stuck providers not implementing ContextProvider are abandoned
+	release := make(chan struct{})
+	defer close(release)
+	provider := &blockingProvider[profileConfig]{release: release}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	cfg, err := configfx.ConfigContext[profileConfig](ctx, provider)
+	assert.Nil(t, cfg)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// static providers return their config
+	static := configfx.NewStaticProvider(&profileConfig{Port: 8080})
+	cfg, err = configfx.ConfigContext(context.Background(), static)
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+}
+
+// blockingProvider is a configfx.Provider[T] blocking until release is closed
+type blockingProvider[T any] struct {
+	release chan struct{}
+	// entered is closed once Config is called if non-nil
+	entered chan struct{}
+}
+
+// Config implements configfx.Provider[T]
+func (p *blockingProvider[T]) Config(...configfx.ConfigOption) (*T, error) {
+	if p.entered != nil {
+		close(p.entered)
+	}
+	<-p.release
+	return new(T), nil
+}
+
+// Viper implements configfx.Provider[T]
+func (p *blockingProvider[T]) Viper() *viper.Viper {
+	return viper.New()
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package configfx
 
 import (
+	"reflect"
+
 	"github.com/choopm/stdfx/configfx/decoders"
 	"github.com/go-viper/mapstructure/v2"
 )
@@ -38,7 +40,66 @@ func DefaultDecoders() []mapstructure.DecodeHookFunc {
 
 		// decoders from subpackage
 		decoders.Duration(), // replaces StringToTimeDurationHookFunc
+		decoders.IP(),
+		decoders.CIDR(),
+		decoders.AddrPort(),
+		decoders.ByteSize(),
+		decoders.Regexp(),
+		decoders.URL(),
 	}
 
 	return decoders
 }
+
+// collectDecodeHooks returns the DecodeHook() of t and of all types of
+// nested fields implementing [CustomDecoder], outer types first.
+// Fields of slices, maps and pointers are searched as well.
+// Embedded types are skipped if their outer type implements [CustomDecoder]
+// itself, as it either promotes or deliberately overrides their DecodeHook().
+// Every type contributes its DecodeHook() only once.
+func collectDecodeHooks(t any) []mapstructure.DecodeHookFunc {
+	hooks := []mapstructure.DecodeHookFunc{}
+	seen := map[reflect.Type]bool{}
+
+	var walk func(typ reflect.Type, instance any)
+	walk = func(typ reflect.Type, instance any) {
+		typ = indirectType(typ)
+		if typ.Kind() != reflect.Struct || seen[typ] {
+			return
+		}
+		seen[typ] = true
+
+		if instance == nil {
+			instance = reflect.New(typ).Interface()
+		}
+		ctype, implements := instance.(CustomDecoder)
+		if implements {
+			hooks = append(hooks, ctype.DecodeHook())
+		}
+
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			// fields of unexported embedded structs are promoted
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			if field.Anonymous && implements {
+				seen[indirectType(field.Type)] = true
+				continue
+			}
+			walk(field.Type, nil)
+		}
+	}
+	walk(reflect.TypeOf(t), t)
+
+	return hooks
+}
+
+// indirectType returns the element type of pointers, slices, arrays and maps
+func indirectType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice ||
+		typ.Kind() == reflect.Array || typ.Kind() == reflect.Map {
+		typ = typ.Elem()
+	}
+	return typ
+}
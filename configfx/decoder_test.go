@@ -0,0 +1,86 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upperString is decoded in upper case by upperFragment
+type upperString string
+
+type upperFragment struct {
+	Name upperString `mapstructure:"name"`
+}
+
+// DecodeHook implements configfx.CustomDecoder
+func (f *upperFragment) DecodeHook() mapstructure.DecodeHookFunc {
+	return func(from, to reflect.Type, data any) (any, error) {
+		if to != reflect.TypeFor[upperString]() || from.Kind() != reflect.String {
+			return data, nil
+		}
+		return strings.ToUpper(data.(string)), nil
+	}
+}
+
+// lowerString is decoded in lower case by lowerFragment
+type lowerString string
+
+type lowerFragment struct {
+	Names []lowerString `mapstructure:"names"`
+}
+
+// DecodeHook implements configfx.CustomDecoder
+func (f lowerFragment) DecodeHook() mapstructure.DecodeHookFunc {
+	return func(from, to reflect.Type, data any) (any, error) {
+		if to != reflect.TypeFor[lowerString]() || from.Kind() != reflect.String {
+			return data, nil
+		}
+		return strings.ToLower(data.(string)), nil
+	}
+}
+
+type fragmentsConfig struct {
+	upperFragment `mapstructure:",squash"`
+
+	Lower *lowerFragment  `mapstructure:"lower"`
+	Items []upperFragment `mapstructure:"items"`
+}
+
+func TestProviderNestedDecodeHooks(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+name: embedded
+lower:
+  names: [Foo, BAR]
+items:
+  - name: item
+`)
+
+	cfg, err := newTestProvider[fragmentsConfig](filename).Config()
+	require.NoError(t, err)
+
+	assert.Equal(t, upperString("EMBEDDED"), cfg.Name)
+	require.NotNil(t, cfg.Lower)
+	assert.Equal(t, []lowerString{"foo", "bar"}, cfg.Lower.Names)
+	assert.Equal(t, []upperFragment{{Name: "ITEM"}}, cfg.Items)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// Bytes is a size in bytes decoded by [ByteSize] from
+// strings such as "10KB", "256MiB" or "1.5GB".
+type Bytes int64
+
+// byteSizeUnits maps lower case unit suffixes to their multiplier.
+// SI units are powers of 1000, IEC units powers of 1024.
+var byteSizeUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+
+	"kb": 1e3,
+	"mb": 1e6,
+	"gb": 1e9,
+	"tb": 1e12,
+	"pb": 1e15,
+	"eb": 1e18,
+
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+}
+
+// ParseByteSize parses s into a number of bytes.
+// s is a decimal number followed by an optional SI (KB, MB, GB, ...)
+// or IEC (KiB, MiB, GiB, ...) unit, units are case-insensitive.
+func ParseByteSize(s string) (int64, error) {
+	trimmed := strings.TrimSpace(s)
+
+	// split number and unit
+	i := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(trimmed)
+	}
+	number, unit := trimmed[:i], strings.ToLower(strings.TrimSpace(trimmed[i:]))
+
+	multiplier, ok := byteSizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, trimmed[i:])
+	}
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	bytes := value * multiplier
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q: overflows int64", s)
+	}
+
+	return int64(bytes), nil
+}
+
+// ByteSize returns a mapstructure.DecodeHookFunc which supports
+// decoding [Bytes] from strings in a format such as "256MiB",
+// see [ParseByteSize]. Other target types are not affected.
+func ByteSize() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeFor[Bytes]() {
+			return data, nil
+		}
+
+		size, err := ParseByteSize(data.(string))
+		if err != nil {
+			return nil, err
+		}
+
+		return Bytes(size), nil
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx/configfx/decoders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type byteSizeConfig struct {
+	Size  decoders.Bytes `mapstructure:"size"`
+	Plain int64          `mapstructure:"plain"`
+}
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		input string
+		size  decoders.Bytes
+		err   string
+	}{
+		{input: "1024", size: 1024},
+		{input: "512B", size: 512},
+		{input: "10KB", size: 10_000},
+		{input: "10kb", size: 10_000},
+		{input: "256MiB", size: 256 << 20},
+		{input: "1.5GB", size: 1_500_000_000},
+		{input: "0.5 KiB", size: 512},
+		{input: "2TiB", size: 2 << 40},
+		{input: "10XB", err: `unknown unit "XB"`},
+		{input: "10 KB/s", err: "unknown unit"},
+		{input: "GB", err: `invalid byte size "GB"`},
+		{input: "1.2.3MB", err: `invalid byte size "1.2.3MB"`},
+		{input: "8EiB", err: "overflows int64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cfg, err := decode[byteSizeConfig](t, map[string]any{"size": tt.input}, decoders.ByteSize())
+			if len(tt.err) > 0 {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.size, cfg.Size)
+		})
+	}
+
+	// other types are not affected
+	_, err := decode[byteSizeConfig](t, map[string]any{"plain": "1KB"}, decoders.ByteSize())
+	assert.Error(t, err)
+	cfg, err := decode[byteSizeConfig](t, map[string]any{"plain": 1024}, decoders.ByteSize())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1024), cfg.Plain)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// Enum returns a mapstructure.DecodeHookFunc which decodes strings
+// into T and fails for values not found in allowed, listing all
+// valid options. Other target types are not affected.
+//
+//	type Mode string
+//	decoders.Enum[Mode]("active", "passive")
+func Enum[T ~string](allowed ...T) mapstructure.DecodeHookFunc {
+	return enum(false, allowed...)
+}
+
+// EnumFold works like [Enum] but matches case-insensitive
+// and returns the value as given in allowed.
+func EnumFold[T ~string](allowed ...T) mapstructure.DecodeHookFunc {
+	return enum(true, allowed...)
+}
+
+// enum implements [Enum] and [EnumFold]
+func enum[T ~string](fold bool, allowed ...T) mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeFor[T]() {
+			return data, nil
+		}
+
+		value := reflect.ValueOf(data).String()
+		for _, option := range allowed {
+			if string(option) == value || (fold && strings.EqualFold(string(option), value)) {
+				return option, nil
+			}
+		}
+
+		options := make([]string, len(allowed))
+		for i, option := range allowed {
+			options[i] = fmt.Sprintf("%q", option)
+		}
+		return nil, fmt.Errorf("invalid value %q, valid options are: %s",
+			value, strings.Join(options, ", "))
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx/configfx/decoders"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mode string
+
+type enumConfig struct {
+	Mode  mode   `mapstructure:"mode"`
+	Plain string `mapstructure:"plain"`
+}
+
+func TestEnum(t *testing.T) {
+	tests := []struct {
+		name  string
+		hook  mapstructure.DecodeHookFunc
+		input string
+		mode  mode
+		err   string
+	}{
+		{name: "allowed", hook: decoders.Enum[mode]("active", "passive"), input: "passive", mode: "passive"},
+		{
+			name:  "disallowed",
+			hook:  decoders.Enum[mode]("active", "passive"),
+			input: "standby",
+			err:   `invalid value "standby", valid options are: "active", "passive"`,
+		},
+		{
+			name:  "case sensitive",
+			hook:  decoders.Enum[mode]("active", "passive"),
+			input: "Active",
+			err:   `invalid value "Active"`,
+		},
+		{name: "case insensitive", hook: decoders.EnumFold[mode]("active", "passive"), input: "ACTIVE", mode: "active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decode[enumConfig](t, map[string]any{"mode": tt.input, "plain": "anything"}, tt.hook)
+			if len(tt.err) > 0 {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.mode, cfg.Mode)
+			assert.Equal(t, "anything", cfg.Plain) // other types are not affected
+		})
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// IP returns a mapstructure.DecodeHookFunc which supports
+// decoding net.IP and netip.Addr from IPv4 or IPv6 strings
+// such as "192.0.2.1" or "2001:db8::1".
+func IP() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+
+		switch t {
+		case reflect.TypeFor[net.IP]():
+			ip := net.ParseIP(data.(string))
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address %q", data)
+			}
+			return ip, nil
+
+		case reflect.TypeFor[netip.Addr]():
+			addr, err := netip.ParseAddr(data.(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid IP address %q: %s", data, err)
+			}
+			return addr, nil
+		}
+
+		return data, nil
+	}
+}
+
+// CIDR returns a mapstructure.DecodeHookFunc which supports
+// decoding net.IPNet, *net.IPNet and netip.Prefix from CIDR strings
+// such as "192.0.2.0/24" or "2001:db8::/32".
+func CIDR() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+
+		switch t {
+		case reflect.TypeFor[net.IPNet](), reflect.TypeFor[*net.IPNet]():
+			_, ipnet, err := net.ParseCIDR(data.(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %s", data, err)
+			}
+			if t.Kind() == reflect.Pointer {
+				return ipnet, nil
+			}
+			return *ipnet, nil
+
+		case reflect.TypeFor[netip.Prefix]():
+			prefix, err := netip.ParsePrefix(data.(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %s", data, err)
+			}
+			return prefix, nil
+		}
+
+		return data, nil
+	}
+}
+
+// AddrPort returns a mapstructure.DecodeHookFunc which supports
+// decoding netip.AddrPort from strings such as "192.0.2.1:8080"
+// or "[2001:db8::1]:8080".
+func AddrPort() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeFor[netip.AddrPort]() {
+			return data, nil
+		}
+
+		addrPort, err := netip.ParseAddrPort(data.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid address and port %q: %s", data, err)
+		}
+
+		return addrPort, nil
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders_test
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx/decoders"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ipConfig struct {
+	IP       net.IP         `mapstructure:"ip"`
+	Addr     netip.Addr     `mapstructure:"addr"`
+	Net      *net.IPNet     `mapstructure:"net"`
+	Prefix   netip.Prefix   `mapstructure:"prefix"`
+	AddrPort netip.AddrPort `mapstructure:"addrPort"`
+}
+
+// decode decodes input onto a fresh *T using hooks
+func decode[T any](t *testing.T, input map[string]any, hooks ...mapstructure.DecodeHookFunc) (*T, error) {
+	t.Helper()
+
+	result := new(T)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(hooks...),
+		Result:     result,
+	})
+	require.NoError(t, err)
+
+	return result, decoder.Decode(input)
+}
+
+func TestIPDecoders(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  map[string]any
+		err    string
+		assert func(t *testing.T, cfg *ipConfig)
+	}{
+		{
+			name:  "ipv4",
+			input: map[string]any{"ip": "192.0.2.1", "addr": "192.0.2.2"},
+			assert: func(t *testing.T, cfg *ipConfig) {
+				assert.True(t, cfg.IP.Equal(net.ParseIP("192.0.2.1")))
+				assert.Equal(t, netip.MustParseAddr("192.0.2.2"), cfg.Addr)
+			},
+		},
+		{
+			name:  "ipv6",
+			input: map[string]any{"ip": "2001:db8::1", "addr": "2001:db8::2"},
+			assert: func(t *testing.T, cfg *ipConfig) {
+				assert.True(t, cfg.IP.Equal(net.ParseIP("2001:db8::1")))
+				assert.Equal(t, netip.MustParseAddr("2001:db8::2"), cfg.Addr)
+			},
+		},
+		{
+			name:  "prefixes",
+			input: map[string]any{"net": "192.0.2.0/24", "prefix": "2001:db8::/32"},
+			assert: func(t *testing.T, cfg *ipConfig) {
+				require.NotNil(t, cfg.Net)
+				assert.Equal(t, "192.0.2.0/24", cfg.Net.String())
+				assert.Equal(t, netip.MustParsePrefix("2001:db8::/32"), cfg.Prefix)
+			},
+		},
+		{
+			name:  "addrport",
+			input: map[string]any{"addrPort": "[2001:db8::1]:8080"},
+			assert: func(t *testing.T, cfg *ipConfig) {
+				assert.Equal(t, netip.MustParseAddrPort("[2001:db8::1]:8080"), cfg.AddrPort)
+			},
+		},
+		{name: "invalid ipv4", input: map[string]any{"ip": "192.0.2.256"}, err: `invalid IP address "192.0.2.256"`},
+		{name: "invalid ipv6", input: map[string]any{"addr": "2001:db8::g"}, err: `invalid IP address "2001:db8::g"`},
+		{name: "invalid prefix", input: map[string]any{"net": "192.0.2.0/33"}, err: `invalid CIDR "192.0.2.0/33"`},
+		{name: "missing prefix length", input: map[string]any{"prefix": "2001:db8::"}, err: `invalid CIDR "2001:db8::"`},
+		{name: "missing port", input: map[string]any{"addrPort": "192.0.2.1"}, err: `invalid address and port "192.0.2.1"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decode[ipConfig](t, tt.input, decoders.IP(), decoders.CIDR(), decoders.AddrPort())
+			if len(tt.err) > 0 {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			tt.assert(t, cfg)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// Regexp returns a mapstructure.DecodeHookFunc which supports
+// decoding *regexp.Regexp and regexp.Regexp from strings using
+// regexp.Compile, so patterns are compiled at load time.
+func Regexp() mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeFor[*regexp.Regexp]() && t != reflect.TypeFor[regexp.Regexp]() {
+			return data, nil
+		}
+
+		re, err := regexp.Compile(data.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regexp %q: %s", data, err)
+		}
+		if t.Kind() != reflect.Pointer {
+			return *re, nil
+		}
+
+		return re, nil
+	}
+}
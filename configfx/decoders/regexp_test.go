@@ -0,0 +1,48 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx/decoders"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type regexpConfig struct {
+	Pattern *regexp.Regexp   `mapstructure:"pattern"`
+	Many    []*regexp.Regexp `mapstructure:"many"`
+}
+
+func TestRegexp(t *testing.T) {
+	cfg, err := decode[regexpConfig](t, map[string]any{
+		"pattern": "^/api/v[0-9]+/",
+		"many":    []any{"foo.*", "(?i)bar"},
+	}, decoders.Regexp())
+	require.NoError(t, err)
+
+	require.NotNil(t, cfg.Pattern)
+	assert.Equal(t, "^/api/v[0-9]+/", cfg.Pattern.String())
+	assert.True(t, cfg.Pattern.MatchString("/api/v2/users"))
+	require.Len(t, cfg.Many, 2)
+	assert.True(t, cfg.Many[1].MatchString("BAR"))
+
+	_, err = decode[regexpConfig](t, map[string]any{"pattern": "route/(unclosed"}, decoders.Regexp())
+	assert.ErrorContains(t, err, `invalid regexp "route/(unclosed"`)
+}
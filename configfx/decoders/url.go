@@ -0,0 +1,70 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// URL returns a mapstructure.DecodeHookFunc which supports
+// decoding *url.URL and url.URL from strings using url.Parse.
+// Relative URLs are allowed for *url.URL only, url.URL
+// requires a scheme and a host.
+func URL() mapstructure.DecodeHookFunc {
+	return URLWithSchemes()
+}
+
+// URLWithSchemes works like [URL] but rejects URLs with a scheme
+// not found in schemes. Any scheme is allowed if schemes is empty.
+func URLWithSchemes(schemes ...string) mapstructure.DecodeHookFunc {
+	return func(
+		f reflect.Type,
+		t reflect.Type,
+		data interface{},
+	) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+		if t != reflect.TypeFor[*url.URL]() && t != reflect.TypeFor[url.URL]() {
+			return data, nil
+		}
+
+		u, err := url.Parse(data.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL %q: %s", data, err)
+		}
+		if len(schemes) > 0 && !slices.Contains(schemes, strings.ToLower(u.Scheme)) {
+			return nil, fmt.Errorf("invalid URL %q: scheme %q is not one of %s",
+				data, u.Scheme, strings.Join(schemes, ", "))
+		}
+
+		if t.Kind() == reflect.Pointer {
+			return u, nil
+		}
+		if len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return nil, fmt.Errorf("invalid URL %q: missing scheme or host", data)
+		}
+
+		return *u, nil
+	}
+}
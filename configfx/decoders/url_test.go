@@ -0,0 +1,112 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package decoders_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx/decoders"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type urlConfig struct {
+	Upstream url.URL  `mapstructure:"upstream"`
+	Link     *url.URL `mapstructure:"link"`
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    map[string]any
+		hook     mapstructure.DecodeHookFunc
+		upstream string
+		link     string
+		err      string
+	}{
+		{
+			name:     "absolute",
+			input:    map[string]any{"upstream": "https://example.com:8443/api", "link": "http://example.com"},
+			hook:     decoders.URL(),
+			upstream: "https://example.com:8443/api",
+			link:     "http://example.com",
+		},
+		{
+			name:  "relative pointer",
+			input: map[string]any{"link": "/docs?page=2"},
+			hook:  decoders.URL(),
+			link:  "/docs?page=2",
+		},
+		{
+			name:  "relative value",
+			input: map[string]any{"upstream": "/api"},
+			hook:  decoders.URL(),
+			err:   `invalid URL "/api": missing scheme or host`,
+		},
+		{
+			name:  "missing scheme",
+			input: map[string]any{"upstream": "example.com:8080"},
+			hook:  decoders.URL(),
+			err:   `invalid URL "example.com:8080"`,
+		},
+		{
+			name:  "unparseable",
+			input: map[string]any{"link": "http://[::1"},
+			hook:  decoders.URL(),
+			err:   `invalid URL "http://[::1"`,
+		},
+		{
+			name:     "allowed scheme",
+			input:    map[string]any{"upstream": "HTTPS://example.com"},
+			hook:     decoders.URLWithSchemes("http", "https"),
+			upstream: "https://example.com",
+		},
+		{
+			name:  "disallowed scheme",
+			input: map[string]any{"upstream": "ftp://example.com"},
+			hook:  decoders.URLWithSchemes("http", "https"),
+			err:   `scheme "ftp" is not one of http, https`,
+		},
+		{
+			name:  "relative with scheme allowlist",
+			input: map[string]any{"link": "/docs"},
+			hook:  decoders.URLWithSchemes("https"),
+			err:   `scheme "" is not one of https`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := decode[urlConfig](t, tt.input, tt.hook)
+			if len(tt.err) > 0 {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			if len(tt.upstream) > 0 {
+				assert.Equal(t, tt.upstream, cfg.Upstream.String())
+			}
+			if len(tt.link) > 0 {
+				require.NotNil(t, cfg.Link)
+				assert.Equal(t, tt.link, cfg.Link.String())
+			}
+		})
+	}
+}
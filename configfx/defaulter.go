@@ -0,0 +1,40 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+// CustomDefaulter denotes types which implement a custom SetDefaults()
+// for defaults which can't be expressed using `default:""` struct tags,
+// e.g. values derived from other fields or the environment.
+//
+// By default SetDefaults is called after applying struct tags and before
+// unmarshalling, the config source overrides what it sets.
+// Use [WithDefaulterOrder] to call it after unmarshalling for computed
+// defaults instead, SetDefaults must then only fill zero values.
+type CustomDefaulter interface {
+	// SetDefaults shall set default values or return an error.
+	SetDefaults() error
+}
+
+// DefaulterOrder selects when [CustomDefaulter] is invoked
+type DefaulterOrder int
+
+const (
+	// DefaultsBeforeUnmarshal invokes SetDefaults before unmarshalling
+	DefaultsBeforeUnmarshal DefaulterOrder = iota
+	// DefaultsAfterUnmarshal invokes SetDefaults after unmarshalling
+	DefaultsAfterUnmarshal
+)
@@ -17,9 +17,14 @@ limitations under the License.
 package configfx
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
+
+	"github.com/creasty/defaults"
 )
 
 // DefaultEnvironmentPrefix returns the default environment prefix.
@@ -37,15 +42,33 @@ func DefaultEnvironmentPrefix(configName string) string {
 	return ""
 }
 
+// envKeyReplacer maps config keys to environment variable names
+var envKeyReplacer = strings.NewReplacer(
+	".", "_",
+	"-", "_",
+)
+
+// EnvVarName returns the name of the environment variable overriding
+// the config key using prefix, like [SourceFile] does.
+func EnvVarName(prefix, key string) string {
+	name := envKeyReplacer.Replace(key)
+	if len(prefix) > 0 {
+		name = prefix + "_" + name
+	}
+
+	return strings.ToUpper(name)
+}
+
 // DefaultFileSearchPaths returns default config file search paths.
 // In order of decreasing priority the following paths are searched
 // for a file <configName> with any supported extension by default:
 // - <working directory>/
-// - $HOME/.config/
+// - $XDG_CONFIG_HOME/<configName>/ (defaults to $HOME/.config/<configName>/)
 // - $HOME/.<configName>/
 // - $HOME/.local/etc/
 // - $HOME/.local/etc/<configName>/
 // - $HOME/
+// - <dir>/<configName>/ for each dir of $XDG_CONFIG_DIRS if set
 // - /opt/<configName>/
 // - /opt/<configName>/etc/
 // - /opt/<configName>/etc/<configName>/
@@ -53,16 +76,59 @@ func DefaultEnvironmentPrefix(configName string) string {
 // - /usr/local/etc/<configName>/
 // - /etc/
 // - /etc/<configName>/
+//
+// On Windows the following paths are searched instead:
+// - <working directory>\
+// - %APPDATA%\<configName>\
+// - %LOCALAPPDATA%\<configName>\
+// - %USERPROFILE%\.<configName>\
+// - %PROGRAMDATA%\<configName>\
+// - <executable directory>\
+//
+// Paths depending on an unknown directory are skipped.
+// The working directory can be excluded using [WithoutWorkingDirSearch].
 func DefaultFileSearchPaths(configName string) []string {
+	return defaultFileSearchPaths(configName, searchPathEnv{
+		goos:       runtime.GOOS,
+		getenv:     os.Getenv,
+		homeDir:    os.UserHomeDir,
+		executable: os.Executable,
+	})
+}
+
+// searchPathEnv abstracts the platform for [DefaultFileSearchPaths]
+type searchPathEnv struct {
+	goos       string
+	getenv     func(key string) string
+	homeDir    func() (string, error)
+	executable func() (string, error)
+}
+
+// defaultFileSearchPaths implements [DefaultFileSearchPaths] for env
+func defaultFileSearchPaths(configName string, env searchPathEnv) []string {
+	if env.goos == "windows" {
+		return windowsFileSearchPaths(configName, env)
+	}
+
 	// working dir
 	paths := []string{
 		".",
 	}
 
+	// user config dir
+	home, err := env.homeDir()
+	if err != nil {
+		home = ""
+	}
+	if xdg := env.getenv("XDG_CONFIG_HOME"); len(xdg) > 0 {
+		paths = append(paths, filepath.Join(xdg, configName))
+	} else if len(home) > 0 {
+		paths = append(paths, filepath.Join(home, ".config", configName))
+	}
+
 	// home folder dirs
-	if home := os.Getenv("HOME"); len(home) > 0 {
+	if len(home) > 0 {
 		paths = append(paths, []string{
-			filepath.Join(home, ".config", configName),
 			filepath.Join(home, "."+configName),
 			filepath.Join(home, ".local/etc"),
 			filepath.Join(home, ".local/etc", configName),
@@ -70,6 +136,13 @@ func DefaultFileSearchPaths(configName string) []string {
 		}...)
 	}
 
+	// system config dirs
+	for _, dir := range filepath.SplitList(env.getenv("XDG_CONFIG_DIRS")) {
+		if len(dir) > 0 {
+			paths = append(paths, filepath.Join(dir, configName))
+		}
+	}
+
 	// common system dirs
 	paths = append(paths, []string{
 		filepath.Join("/opt", configName),
@@ -83,3 +156,154 @@ func DefaultFileSearchPaths(configName string) []string {
 
 	return paths
 }
+
+// windowsFileSearchPaths returns the search paths of [DefaultFileSearchPaths]
+// for windows
+func windowsFileSearchPaths(configName string, env searchPathEnv) []string {
+	// working dir
+	paths := []string{
+		".",
+	}
+
+	// user dirs
+	for _, name := range []string{"APPDATA", "LOCALAPPDATA"} {
+		if dir := env.getenv(name); len(dir) > 0 {
+			paths = append(paths, filepath.Join(dir, configName))
+		}
+	}
+	if home, err := env.homeDir(); err == nil && len(home) > 0 {
+		paths = append(paths, filepath.Join(home, "."+configName))
+	}
+
+	// system dirs
+	if dir := env.getenv("PROGRAMDATA"); len(dir) > 0 {
+		paths = append(paths, filepath.Join(dir, configName))
+	}
+	if exe, err := env.executable(); err == nil {
+		paths = append(paths, filepath.Dir(exe))
+	}
+
+	return paths
+}
+
+// Defaults returns a fresh *T having all default values set
+// by struct tags `default:""` or error.
+// No config file or environment is taken into account.
+func Defaults[T any]() (*T, error) {
+	t := new(T)
+	if err := setDefaults(t); err != nil {
+		return nil, fmt.Errorf("setting config defaults: %s", err)
+	}
+
+	return t, nil
+}
+
+// setDefaults sets default values by struct tags `default:""` on t.
+// Unlike a plain [defaults.Set] it first allocates nil pointers to
+// embedded structs, so shared config fragments bring their defaults along
+// no matter if they are embedded by value or by pointer.
+func setDefaults(t any) error {
+	allocEmbedded(reflect.ValueOf(t), map[reflect.Type]bool{})
+
+	return defaults.Set(t)
+}
+
+// allocEmbedded walks the struct v and allocates any nil pointer
+// to an embedded struct. Types in seen are not allocated again
+// to prevent endless recursion on self-referencing types.
+func allocEmbedded(v reflect.Value, seen map[reflect.Type]bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := range v.NumField() {
+		field, fv := v.Type().Field(i), v.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+
+		// allocate embedded struct pointers
+		alloc := field.Anonymous && fv.Kind() == reflect.Pointer && fv.IsNil() &&
+			field.Type.Elem().Kind() == reflect.Struct && !seen[field.Type]
+		if alloc {
+			seen[field.Type] = true
+			fv.Set(reflect.New(field.Type.Elem()))
+		}
+
+		// descend into nested and embedded structs
+		allocEmbedded(fv, seen)
+
+		if alloc {
+			delete(seen, field.Type)
+		}
+	}
+}
+
+// setElementDefaults walks v and sets default values by struct tags
+// `default:""` on all struct elements of slices, arrays and maps.
+// It is used after unmarshalling, as defaults of elements are unknown
+// before the config source defined them.
+// Only zero values are set, explicitly configured zero values of
+// fields with a non-zero default are therefore overridden.
+func setElementDefaults(v reflect.Value) error {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if !v.Field(i).CanSet() {
+				continue
+			}
+			if err := setElementDefaults(v.Field(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := setElementValueDefaults(v.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			// map values are not addressable, work on a copy
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(v.MapIndex(key))
+			if err := setElementValueDefaults(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	}
+
+	return nil
+}
+
+// setElementValueDefaults sets defaults on the collection element elem
+// if it is a struct or a pointer to one and descends into it.
+func setElementValueDefaults(elem reflect.Value) error {
+	target := elem
+	for target.Kind() == reflect.Pointer && !target.IsNil() {
+		target = target.Elem()
+	}
+	if target.Kind() == reflect.Struct && target.CanAddr() {
+		if err := defaults.Set(target.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+
+	return setElementDefaults(elem)
+}
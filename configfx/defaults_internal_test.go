@@ -0,0 +1,103 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFileSearchPaths(t *testing.T) {
+	env := map[string]string{
+		"APPDATA":      filepath.Join("C:", "Users", "me", "AppData", "Roaming"),
+		"LOCALAPPDATA": filepath.Join("C:", "Users", "me", "AppData", "Local"),
+		"PROGRAMDATA":  filepath.Join("C:", "ProgramData"),
+	}
+	home := filepath.Join("C:", "Users", "me")
+	exe := filepath.Join("C:", "Program Files", "app", "app.exe")
+
+	windows := searchPathEnv{
+		goos:       "windows",
+		getenv:     func(key string) string { return env[key] },
+		homeDir:    func() (string, error) { return home, nil },
+		executable: func() (string, error) { return exe, nil },
+	}
+	assert.Equal(t, []string{
+		".",
+		filepath.Join(env["APPDATA"], "app"),
+		filepath.Join(env["LOCALAPPDATA"], "app"),
+		filepath.Join(home, ".app"),
+		filepath.Join(env["PROGRAMDATA"], "app"),
+		filepath.Dir(exe),
+	}, defaultFileSearchPaths("app", windows))
+
+	linux := searchPathEnv{
+		goos:       "linux",
+		getenv:     func(string) string { return "" },
+		homeDir:    func() (string, error) { return "/home/me", nil },
+		executable: func() (string, error) { return "/usr/bin/app", nil },
+	}
+	assert.Equal(t, []string{
+		".",
+		"/home/me/.config/app",
+		"/home/me/.app",
+		"/home/me/.local/etc",
+		"/home/me/.local/etc/app",
+		"/home/me",
+		"/opt/app",
+		"/opt/app/etc",
+		"/opt/app/etc/app",
+		"/usr/local/etc",
+		"/usr/local/etc/app",
+		"/etc",
+		"/etc/app",
+	}, defaultFileSearchPaths("app", linux))
+
+	// unknown directories are skipped
+	linux.homeDir = func() (string, error) { return "", errors.New("no home") }
+	assert.Equal(t, []string{".", "/opt/app"}, defaultFileSearchPaths("app", linux)[:2])
+}
+
+func TestDefaultFileSearchPathsXDG(t *testing.T) {
+	env := map[string]string{}
+	linux := searchPathEnv{
+		goos:       "linux",
+		getenv:     func(key string) string { return env[key] },
+		homeDir:    func() (string, error) { return "/home/me", nil },
+		executable: func() (string, error) { return "/usr/bin/app", nil },
+	}
+
+	// unset
+	paths := defaultFileSearchPaths("app", linux)
+	assert.Equal(t, []string{".", "/home/me/.config/app", "/home/me/.app"}, paths[:3])
+	assert.Equal(t, "/opt/app", paths[6])
+
+	// set
+	env["XDG_CONFIG_HOME"] = "/xdg/home"
+	env["XDG_CONFIG_DIRS"] = "/xdg/first:/xdg/second"
+	paths = defaultFileSearchPaths("app", linux)
+	assert.Equal(t, []string{".", "/xdg/home/app", "/home/me/.app"}, paths[:3])
+	assert.Equal(t, []string{"/xdg/first/app", "/xdg/second/app", "/opt/app"}, paths[6:9])
+
+	// XDG_CONFIG_HOME does not depend on a known home
+	linux.homeDir = func() (string, error) { return "", errors.New("no home") }
+	assert.Equal(t, []string{".", "/xdg/home/app", "/xdg/first/app"},
+		defaultFileSearchPaths("app", linux)[:3])
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// EncodeFormats lists the formats supported by [Encode]
+var EncodeFormats = []string{"json", "yaml", "toml"}
+
+// Encode serializes cfg into the requested format or error.
+// Supported formats are listed in [EncodeFormats].
+// Keys are named after `mapstructure:""` struct tags, therefore
+// the result can be read again by a [Provider].
+func Encode(cfg any, format string) ([]byte, error) {
+	m := encodeValue(reflect.ValueOf(cfg))
+
+	switch strings.ToLower(format) {
+	case "json":
+		return json.MarshalIndent(m, "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(m)
+	case "toml":
+		return toml.Marshal(m)
+	default:
+		return nil, fmt.Errorf("unknown format %q, supported: %s",
+			format, strings.Join(EncodeFormats, ", "))
+	}
+}
+
+var (
+	_durationType      = reflect.TypeFor[time.Duration]()
+	_textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// encodeValue converts v into plain maps, slices and values.
+// Struct fields are keyed by their `mapstructure:""` tag.
+func encodeValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	// types which know how to represent themselves
+	switch {
+	case v.Type() == _durationType:
+		return v.Interface().(time.Duration).String()
+	case v.Type().Implements(_textMarshalerType):
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return nil
+		}
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return encodeValue(v.Elem())
+
+	case reflect.Struct:
+		m := map[string]any{}
+		encodeStruct(v, m)
+		return m
+
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = encodeValue(iter.Value())
+		}
+		return m
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []any{}
+		}
+		s := make([]any, v.Len())
+		for i := range v.Len() {
+			s[i] = encodeValue(v.Index(i))
+		}
+		return s
+
+	default:
+		return v.Interface()
+	}
+}
+
+// encodeStruct adds all exported fields of struct v to m
+func encodeStruct(v reflect.Value, m map[string]any) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// squashed structs contribute their fields to the parent
+		fv := v.Field(i)
+		if strings.Contains(opts, "squash") && fv.Kind() == reflect.Struct {
+			encodeStruct(fv, m)
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		m[name] = encodeValue(fv)
+	}
+}
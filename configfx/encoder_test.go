@@ -0,0 +1,96 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type encoderConfig struct {
+	Name     string            `mapstructure:"name"`
+	Timeout  time.Duration     `mapstructure:"timeout"`
+	Enabled  bool              `mapstructure:"enabled"`
+	Server   encoderServer     `mapstructure:"server"`
+	Routes   []*encoderRoute   `mapstructure:"routes"`
+	Labels   map[string]string `mapstructure:"labels"`
+	internal string
+}
+
+type encoderServer struct {
+	Host string `mapstructure:"host"`
+	Port int    `mapstructure:"port"`
+}
+
+type encoderRoute struct {
+	Path    string `mapstructure:"path"`
+	Content string `mapstructure:"content"`
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	cfg := &encoderConfig{
+		Name:    "test",
+		Timeout: 90 * time.Second,
+		Enabled: true,
+		Server: encoderServer{
+			Host: "127.0.0.1",
+			Port: 8080,
+		},
+		Routes: []*encoderRoute{
+			{Path: "/", Content: "hello world"},
+			{Path: "/example", Content: "example"},
+		},
+		Labels: map[string]string{
+			"team": "platform",
+		},
+		internal: "not encoded",
+	}
+
+	for _, format := range configfx.EncodeFormats {
+		t.Run(format, func(t *testing.T) {
+			b, err := configfx.Encode(cfg, format)
+			require.NoError(t, err)
+			assert.NotContains(t, string(b), "not encoded")
+
+			// read it back like a provider would do
+			v := viper.New()
+			v.SetConfigType(format)
+			require.NoError(t, v.ReadConfig(bytes.NewReader(b)))
+
+			decoded := &encoderConfig{}
+			require.NoError(t, v.Unmarshal(decoded, viper.DecodeHook(
+				mapstructure.ComposeDecodeHookFunc(configfx.DefaultDecoders()...),
+			)))
+
+			expected := *cfg
+			expected.internal = ""
+			assert.Equal(t, &expected, decoded)
+		})
+	}
+}
+
+func TestEncodeUnknownFormat(t *testing.T) {
+	_, err := configfx.Encode(&encoderConfig{}, "xml")
+	assert.ErrorContains(t, err, "unknown format")
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// applyIndexedEnv overrides values of list elements in v using environment
+// variables addressing them by index, e.g. MYAPP_ROUTES_0_PATH overrides
+// the key routes.0.path using the env prefix MYAPP of v.
+// Viper does not support this by itself as AutomaticEnv only considers
+// the keys it knows about, which do not include list elements.
+//
+// Only scalar values which are present in the config source can be
+// overridden. Lists can't be extended and fields missing in an element
+// can't be added this way. Given an allowlist, only list elements below
+// its keys are overridden, see [WithEnvAllowlist].
+func applyIndexedEnv(v *viper.Viper, allowlist []string, lookupEnv func(key string) (string, bool)) error {
+	settings := v.AllSettings()
+
+	overrides := map[string]string{}
+	lists := map[string]bool{}
+	walkListElements(settings, "", "", func(key, list string) {
+		if !EnvAllowed(allowlist, key) {
+			return
+		}
+		if value, ok := lookupEnv(EnvVarName(v.GetEnvPrefix(), key)); ok {
+			overrides[key] = value
+			lists[list] = true
+		}
+	})
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	for key, value := range overrides {
+		if err := SetPath(settings, key, value); err != nil {
+			return err
+		}
+	}
+
+	// only the overridden lists are merged, lists are replaced as a whole
+	changed := map[string]any{}
+	for list := range lists {
+		value, _ := GetPath(settings, list)
+		if err := SetPath(changed, list, value); err != nil {
+			return err
+		}
+	}
+
+	// merge instead of v.Set to let a reload of the config source
+	// replace the overridden lists
+	return v.MergeConfigMap(changed)
+}
+
+// EnvAllowed returns true if key might be overridden by the environment
+// given allowlist as returned by [EnvAllowlist], which allows any key if
+// empty. Keys below the keys of allowlist are allowed as well.
+func EnvAllowed(allowlist []string, key string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+
+	key = strings.ToLower(key)
+	for _, allowed := range allowlist {
+		allowed = strings.ToLower(allowed)
+		if key == allowed || strings.HasPrefix(key, allowed+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// walkListElements invokes fn using the key of every scalar value found
+// inside node below prefix which is part of a list, along with the key
+// of the outermost list containing it
+func walkListElements(node any, prefix string, list string, fn func(key, list string)) {
+	join := func(part string) string {
+		if len(prefix) == 0 {
+			return part
+		}
+		return prefix + "." + part
+	}
+
+	v := reflect.ValueOf(node)
+	switch v.Kind() {
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			walkListElements(v.MapIndex(k).Interface(), join(fmt.Sprint(k.Interface())), list, fn)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if len(list) == 0 {
+			list = prefix
+		}
+		for i := range v.Len() {
+			walkListElements(v.Index(i).Interface(), join(strconv.Itoa(i)), list, fn)
+		}
+
+	default:
+		if len(list) > 0 && len(prefix) > 0 {
+			fn(prefix, list)
+		}
+	}
+}
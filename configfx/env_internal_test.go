@@ -0,0 +1,102 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyIndexedEnv(t *testing.T) {
+	env := map[string]string{
+		"MYAPP_ROUTES_1_PATH":   "/new",
+		"MYAPP_ROUTES_0_TAGS_1": "changed",
+		"MYAPP_ROUTES_2_PATH":   "/out-of-range",
+		"MYAPP_NAME":            "ignored",
+	}
+
+	v := viper.New()
+	v.SetEnvPrefix("MYAPP")
+	v.SetConfigType("yaml")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+name: app
+routes:
+- path: /
+  tags: [a, b]
+- path: /example
+`)))
+
+	require.NoError(t, applyIndexedEnv(v, nil, func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}))
+
+	var config struct {
+		Name   string `mapstructure:"name"`
+		Routes []struct {
+			Path string   `mapstructure:"path"`
+			Tags []string `mapstructure:"tags"`
+		} `mapstructure:"routes"`
+	}
+	require.NoError(t, v.Unmarshal(&config))
+	assert.Equal(t, "app", config.Name)
+	require.Len(t, config.Routes, 2)
+	assert.Equal(t, "/", config.Routes[0].Path)
+	assert.Equal(t, []string{"a", "changed"}, config.Routes[0].Tags)
+	assert.Equal(t, "/new", config.Routes[1].Path)
+}
+
+func TestApplyIndexedEnvAllowlist(t *testing.T) {
+	env := map[string]string{
+		"MYAPP_SERVER_ROUTES_0_PATH": "/new",
+		"MYAPP_TARGETS_0":            "changed",
+	}
+
+	v := viper.New()
+	v.SetEnvPrefix("MYAPP")
+	v.SetConfigType("yaml")
+	v.SetDefault("server.host", "localhost")
+	require.NoError(t, v.ReadConfig(strings.NewReader(`
+server:
+  routes:
+  - path: /
+targets: [a, b]
+`)))
+
+	require.NoError(t, applyIndexedEnv(v, []string{"server.routes"}, func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}))
+
+	assert.Equal(t, "/new", v.Get("server.routes.0.path"))
+	assert.Equal(t, []any{"a", "b"}, v.Get("targets"), "not in allowlist")
+	assert.False(t, v.InConfig("server.host"), "only overridden lists are merged")
+}
+
+func TestEnvAllowed(t *testing.T) {
+	allowlist := []string{"server.routes", "Port"}
+	assert.True(t, EnvAllowed(nil, "any.key"))
+	assert.True(t, EnvAllowed(allowlist, "server.routes"))
+	assert.True(t, EnvAllowed(allowlist, "server.routes.0.path"))
+	assert.True(t, EnvAllowed(allowlist, "port"))
+	assert.False(t, EnvAllowed(allowlist, "server.routesx"))
+	assert.False(t, EnvAllowed(allowlist, "server"))
+}
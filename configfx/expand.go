@@ -0,0 +1,84 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envReference matches ${ENV_VAR} and ${ENV_VAR:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// expandEnv replaces environment variable references in all string
+// values of v. Supported are ${ENV_VAR} and ${ENV_VAR:-default}.
+// Non-string values are left untouched.
+// It returns an error if a referenced variable is unset and has no default.
+func expandEnv(v any) (any, error) {
+	switch t := v.(type) {
+	case string:
+		return expandEnvString(t)
+
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for key, value := range t {
+			expanded, err := expandEnv(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			m[key] = expanded
+		}
+		return m, nil
+
+	case []any:
+		s := make([]any, len(t))
+		for i, value := range t {
+			expanded, err := expandEnv(value)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			s[i] = expanded
+		}
+		return s, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// expandEnvString replaces environment variable references in s
+func expandEnvString(s string) (string, error) {
+	var err error
+	expanded := envReference.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envReference.FindStringSubmatch(ref)
+		name, hasDefault, def := match[1], len(match[2]) > 0, match[3]
+
+		if value, ok := os.LookupEnv(name); ok {
+			return value
+		}
+		if hasDefault {
+			return def
+		}
+		if err == nil {
+			err = fmt.Errorf("environment variable %q is not set and has no default", name)
+		}
+		return ref
+	})
+
+	return expanded, err
+}
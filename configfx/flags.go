@@ -0,0 +1,161 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/choopm/stdfx/globals"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// FlagName returns the name of the flag overriding key,
+// e.g. "webserver-port" for "webserver.port".
+func FlagName(key string) string {
+	return strings.ReplaceAll(key, ".", "-")
+}
+
+// BindFlags registers a flag on cmd for every key of T and binds it to
+// the key of provider, e.g. --webserver-port overrides webserver.port.
+// Flags default to the `default:""` struct tags of T and are supported
+// for strings, bools, numbers, durations and string slices. Keys of other
+// types are skipped as well as flags defined already or by [globals.RootFlags].
+// Providers of this package keep the bindings across viper instances
+// recreated after failed Config calls.
+// Usage example:
+//
+//	cmd := &cobra.Command{Use: "server", RunE: ...}
+//	err := configfx.BindFlags(cmd, provider)
+func BindFlags[T any](cmd *cobra.Command, provider Provider[T]) error {
+	bind := func(key string, flag *pflag.Flag) error {
+		return provider.Viper().BindPFlag(key, flag)
+	}
+	if binder, ok := provider.(flagBinder); ok {
+		bind = binder.bindPFlag
+	}
+
+	// flag defaults must match the defaults of T, viper falls back to
+	// the default of unchanged flags otherwise
+	defaults, err := Defaults[T]()
+	if err != nil {
+		return err
+	}
+	dv := viper.New()
+	if m, ok := encodeValue(reflect.ValueOf(defaults)).(map[string]any); ok {
+		if err := dv.MergeConfigMap(m); err != nil {
+			return fmt.Errorf("bind flags: %s", err)
+		}
+	}
+
+	flags := cmd.Flags()
+	for _, key := range Keys[T]() {
+		name := FlagName(key)
+		if flags.Lookup(name) != nil ||
+			cmd.PersistentFlags().Lookup(name) != nil ||
+			globals.RootFlags.Lookup(name) != nil {
+			continue
+		}
+
+		typ, _ := KeyType[T](key)
+		if !defineFlag(flags, typ, name, key, dv) {
+			continue
+		}
+		if err := bind(key, flags.Lookup(name)); err != nil {
+			return fmt.Errorf("bind flag %s: %s", name, err)
+		}
+	}
+
+	return nil
+}
+
+// flagBinder denotes providers keeping flag bindings
+// across the viper instances they create
+type flagBinder interface {
+	bindPFlag(key string, flag *pflag.Flag) error
+}
+
+// defineFlag defines the flag name for key of type typ on flags using the
+// default of key found in defaults. It returns false for unsupported types.
+func defineFlag(flags *pflag.FlagSet, typ reflect.Type, name, key string, defaults *viper.Viper) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	usage := fmt.Sprintf("overrides config key %s", key)
+
+	if typ == _durationType {
+		value := durationValue(defaults.GetDuration(key))
+		flags.Var(&value, name, usage)
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		flags.String(name, defaults.GetString(key), usage)
+	case reflect.Bool:
+		flags.Bool(name, defaults.GetBool(key), usage)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		flags.Int(name, defaults.GetInt(key), usage)
+	case reflect.Int64:
+		flags.Int64(name, defaults.GetInt64(key), usage)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		flags.Uint64(name, defaults.GetUint64(key), usage)
+	case reflect.Float32, reflect.Float64:
+		flags.Float64(name, defaults.GetFloat64(key), usage)
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.String {
+			return false
+		}
+		flags.StringSlice(name, defaults.GetStringSlice(key), usage)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// durationValue is a pflag.Value of a time.Duration parsed like the
+// config decoder does, supporting days and weeks, e.g. "1d12h".
+type durationValue time.Duration
+
+// ensure durationValue implements pflag.Value
+var _ pflag.Value = new(durationValue)
+
+// Set implements pflag.Value
+func (d *durationValue) Set(s string) error {
+	v, err := str2duration.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+// String implements pflag.Value
+func (d *durationValue) String() string {
+	return time.Duration(*d).String()
+}
+
+// Type implements pflag.Value
+func (d *durationValue) Type() string {
+	return "duration"
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flagsConfig struct {
+	Webserver struct {
+		Host string `mapstructure:"host" default:"localhost"`
+		Port int    `mapstructure:"port" default:"8080"`
+	} `mapstructure:"webserver"`
+
+	Timeout time.Duration     `mapstructure:"timeout" default:"10s"`
+	Debug   bool              `mapstructure:"debug"`
+	Tags    []string          `mapstructure:"tags"`
+	Name    string            `mapstructure:"name" default:"app"`
+	Labels  map[string]string `mapstructure:"labels"`
+}
+
+func TestBindFlags(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+webserver:
+  host: example.com
+  port: 8000
+timeout: 20s
+`)
+	provider := newTestProvider[flagsConfig](filename)
+
+	cmd := &cobra.Command{Use: "server"}
+	require.NoError(t, configfx.BindFlags(cmd, provider))
+
+	// flags default to the struct tags
+	flag := cmd.Flags().Lookup("webserver-port")
+	require.NotNil(t, flag)
+	assert.Equal(t, "8080", flag.DefValue)
+	assert.Nil(t, cmd.Flags().Lookup("labels"), "maps are not supported")
+
+	require.NoError(t, cmd.ParseFlags([]string{
+		"--webserver-port", "9000",
+		"--debug",
+		"--tags", "a,b",
+		"--timeout", "1d",
+	}))
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Webserver.Port)          // flag
+	assert.Equal(t, "example.com", cfg.Webserver.Host) // file
+	assert.Equal(t, 24*time.Hour, cfg.Timeout)         // flag
+	assert.True(t, cfg.Debug)                          // flag
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)      // flag
+	assert.Equal(t, "app", cfg.Name)                   // default
+}
+
+func TestBindFlagsAfterFailedConfig(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", "webserver: [invalid")
+	provider := newTestProvider[flagsConfig](filename)
+
+	cmd := &cobra.Command{Use: "server"}
+	require.NoError(t, configfx.BindFlags(cmd, provider))
+	require.NoError(t, cmd.ParseFlags([]string{"--webserver-port", "9000"}))
+
+	// the failed read discards the viper instance
+	_, err := provider.Config()
+	require.Error(t, err)
+
+	writeConfig(t, dir, "config.yaml", "webserver:\n  port: 8000\n")
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Webserver.Port)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// ErrIncludeCycle is returned by [Provider] if config files
+// include each other, see [WithIncludeKey]
+var ErrIncludeCycle = errors.New("include cycle")
+
+// readIncludes merges the files listed by key inside the config file read
+// by v beneath it. Included files may include other files themselves,
+// relative paths are resolved relative to the including file.
+// Files included later take precedence over earlier ones and the
+// including file takes precedence over all of its includes.
+// Files exceeding maxSize are refused.
+func readIncludes(v *viper.Viper, key string, maxSize int64) error {
+	filename := v.ConfigFileUsed()
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return fmt.Errorf("include: %s", err)
+	}
+
+	files, err := collectIncludes(abs, v.GetStringSlice(key), key, maxSize, []string{abs})
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	// read the includes first to merge the config file onto them
+	for i, file := range files {
+		v.SetConfigFile(file)
+		if i == 0 {
+			err = v.ReadInConfig()
+		} else {
+			err = v.MergeInConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("include %q: %s", file, err)
+		}
+	}
+	v.SetConfigFile(filename)
+	if err := v.MergeInConfig(); err != nil {
+		return fmt.Errorf("include: merging %q: %s", filename, err)
+	}
+
+	return nil
+}
+
+// collectIncludes returns the files included by filename in merge order,
+// files included by an included file are listed before it.
+// stack lists the files including filename to detect cycles.
+func collectIncludes(
+	filename string,
+	includes []string,
+	key string,
+	maxSize int64,
+	stack []string,
+) ([]string, error) {
+	files := []string{}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(filename), include)
+		}
+		include = filepath.Clean(include)
+		if slices.Contains(stack, include) {
+			return nil, fmt.Errorf("%w: %s", ErrIncludeCycle,
+				strings.Join(append(slices.Clone(stack), include), " -> "))
+		}
+
+		iv := viper.New()
+		iv.SetFs(newNormalizedFs(maxSize))
+		iv.SetConfigFile(include)
+		if err := iv.ReadInConfig(); err != nil {
+			return nil, fmt.Errorf("include %q of %q: %w", include, filename, err)
+		}
+
+		nested, err := collectIncludes(include, iv.GetStringSlice(key), key, maxSize,
+			append(slices.Clone(stack), include))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, nested...)
+		files = append(files, include)
+	}
+
+	return files, nil
+}
+
+// unmarshalWithout decodes the settings of v into rawVal like
+// viper.Unmarshal but without the top-level key. It is used to hide
+// the include key from strict unmarshalling.
+func unmarshalWithout(v *viper.Viper, key string, rawVal any, opts ...viper.DecoderConfigOption) error {
+	settings := v.AllSettings()
+	delete(settings, strings.ToLower(key))
+
+	// same defaults as viper.Unmarshal
+	config := &mapstructure.DecoderConfig{
+		Result:           rawVal,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return err
+	}
+
+	return decoder.Decode(settings)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProviderInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "conf"), 0755))
+	filename := writeConfig(t, dir, "config.yaml", `
+include: [conf/base.yaml]
+port: 9090
+`)
+	// relative to conf/base.yaml
+	writeConfig(t, dir, "conf/base.yaml", `
+include: [defaults.yaml]
+host: base.example.com
+`)
+	writeConfig(t, dir, "conf/defaults.yaml", `
+host: defaults.example.com
+port: 1
+name: defaults
+`)
+
+	provider := newTestProvider[profileConfig](filename)
+	cfg, err := provider.Config(
+		configfx.WithIncludeKey("include"),
+		configfx.WithStrictUnmarshal(), // the include key is no unknown key
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.Port)               // config.yaml
+	assert.Equal(t, "base.example.com", cfg.Host) // conf/base.yaml
+	assert.Equal(t, "defaults", cfg.Name)         // conf/defaults.yaml
+
+	// loading again yields the same result
+	cfg, err = provider.Config(configfx.WithIncludeKey("include"))
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "defaults", cfg.Name)
+}
+
+func TestProviderIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "a.yaml", "include: [b.yaml]\n")
+	writeConfig(t, dir, "b.yaml", "include: [a.yaml]\n")
+	writeConfig(t, dir, "missing.yaml", "include: [nothere.yaml]\n")
+
+	_, err := newTestProvider[profileConfig](filepath.Join(dir, "a.yaml")).
+		Config(configfx.WithIncludeKey("include"))
+	assert.ErrorIs(t, err, configfx.ErrIncludeCycle)
+	assert.ErrorContains(t, err, "a.yaml -> "+filepath.Join(dir, "b.yaml")+" -> "+filepath.Join(dir, "a.yaml"))
+
+	_, err = newTestProvider[profileConfig](filepath.Join(dir, "missing.yaml")).
+		Config(configfx.WithIncludeKey("include"))
+	assert.ErrorContains(t, err, `include "`+filepath.Join(dir, "nothere.yaml")+`"`)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// LockFile takes an exclusive advisory lock on filename or error.
+// It blocks until the lock is acquired and returns a func to release it.
+// Use it around read-modify-write cycles of config files to prevent
+// lost updates and torn writes by concurrent processes:
+//
+//	unlock, err := configfx.LockFile(v.ConfigFileUsed())
+//	if err != nil {
+//		return err
+//	}
+//	defer unlock()
+func LockFile(filename string) (unlock func() error, err error) {
+	f, err := os.OpenFile(filename, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open %q for locking: %s", filename, err)
+	}
+
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock %q: %s", filename, err)
+	}
+
+	return func() error {
+		return errors.Join(unlockFile(f), f.Close())
+	}, nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockFile(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", "key: value\n")
+
+	unlock, err := configfx.LockFile(filename)
+	require.NoError(t, err)
+
+	// a second lock shall block until the first one is released
+	locked := make(chan struct{})
+	go func() {
+		unlock, err := configfx.LockFile(filename)
+		if assert.NoError(t, err) {
+			assert.NoError(t, unlock())
+		}
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("second lock did not block")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, unlock())
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("second lock was not acquired after unlock")
+	}
+}
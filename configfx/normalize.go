@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/afero"
+)
+
+// _bom is the UTF-8 byte order mark
+var _bom = []byte("\xef\xbb\xbf")
+
+// normalizeConfig strips a leading UTF-8 BOM from b and
+// converts CRLF line endings to LF. Some parsers like json and
+// toml fail on config files edited using Windows editors otherwise.
+func normalizeConfig(b []byte) []byte {
+	b = bytes.TrimPrefix(b, _bom)
+	return bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+}
+
+// DefaultMaxSize is the default maximum size of config files in bytes
+const DefaultMaxSize int64 = 10 << 20
+
+// ErrConfigTooLarge is returned when reading a config file
+// exceeding the maximum size
+var ErrConfigTooLarge = errors.New("config file too large")
+
+// readConfig reads all of r named name refusing to read more than
+// maxSize bytes. A maxSize <= 0 does not limit the size.
+func readConfig(r io.Reader, name string, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(r)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > maxSize {
+		return nil, fmt.Errorf("%w: %q exceeds %d bytes", ErrConfigTooLarge, name, maxSize)
+	}
+
+	return b, nil
+}
+
+// normalizedFs is an afero.Fs serving files opened for reading
+// through normalizeConfig. It is used as filesystem of viper
+// instances reading config files and refuses files exceeding maxSize.
+type normalizedFs struct {
+	afero.Fs
+	maxSize int64
+}
+
+// newNormalizedFs returns a normalizedFs backed by the os filesystem
+// limiting files to maxSize bytes
+func newNormalizedFs(maxSize int64) afero.Fs {
+	return &normalizedFs{Fs: afero.NewOsFs(), maxSize: maxSize}
+}
+
+// Open implements afero.Fs
+func (fs *normalizedFs) Open(name string) (afero.File, error) {
+	f, err := fs.Fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return f, err
+	}
+
+	b, err := readConfig(f, name, fs.maxSize)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &normalizedFile{
+		File:   f,
+		reader: bytes.NewReader(normalizeConfig(b)),
+	}, nil
+}
+
+// normalizedFile is an afero.File reading its normalized content
+type normalizedFile struct {
+	afero.File
+	reader *bytes.Reader
+}
+
+// Read implements io.Reader
+func (f *normalizedFile) Read(p []byte) (int, error) {
+	return f.reader.Read(p)
+}
+
+// ReadAt implements io.ReaderAt
+func (f *normalizedFile) ReadAt(p []byte, off int64) (int, error) {
+	return f.reader.ReadAt(p, off)
+}
+
+// Seek implements io.Seeker
+func (f *normalizedFile) Seek(offset int64, whence int) (int64, error) {
+	return f.reader.Seek(offset, whence)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bom = "\xef\xbb\xbf"
+
+func TestProviderBOMAndCRLF(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "config.yaml", content: bom + "host: example.com\r\nport: 9090\r\n"},
+		{name: "config.json", content: bom + "{\r\n  \"host\": \"example.com\",\r\n  \"port\": 9090\r\n}\r\n"},
+		{name: "config.toml", content: bom + "host = \"example.com\"\r\nport = 9090\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeConfig(t, dir, tt.name, tt.content)
+
+			cfg, err := newDirProvider[profileConfig](dir).Config()
+			require.NoError(t, err)
+			assert.Equal(t, "example.com", cfg.Host)
+			assert.Equal(t, 9090, cfg.Port)
+		})
+	}
+}
@@ -16,13 +16,22 @@ limitations under the License.
 
 package configfx
 
-import "github.com/fsnotify/fsnotify"
+import (
+	"slices"
+
+	"github.com/fsnotify/fsnotify"
+)
 
 // configOptions stores options for With*() funcs
 type configOptions struct {
-	readInConfig   bool
-	overlays       []*Overlay
-	onConfigChange func(in fsnotify.Event)
+	readInConfig    bool
+	overlays        []*Overlay
+	onConfigChange  onChangeCallbacks
+	strictUnmarshal bool
+	defaulterOrder  DefaulterOrder
+	secretResolvers secretResolvers
+	indexedEnv      bool
+	includeKey      string
 }
 
 // ConfigOption is a func to adjust options of *configOptions for later
@@ -32,8 +41,7 @@ type ConfigOption func(*configOptions)
 // defaultConfigOptions returns the default *configOptions
 func defaultConfigOptions() *configOptions {
 	opts := &configOptions{
-		overlays:       make([]*Overlay, 0),
-		onConfigChange: nil,
+		overlays: make([]*Overlay, 0),
 	}
 
 	WithReadInConfig(true)(opts)
@@ -63,8 +71,161 @@ func WithOverlays(overlays ...*Overlay) ConfigOption {
 
 // WithOnConfigChange adds the callback to all viper instances.
 // This callback will be invoked whenever there is a config change.
+// Callbacks of all Config calls are chained in the order they have been
+// passed, passing the same option again does not add it twice.
+// Closing the provider stops watching the config.
 func WithOnConfigChange(callback func(in fsnotify.Event)) ConfigOption {
+	onChange := &onChangeCallback{callback: callback}
+	return func(o *configOptions) {
+		o.onConfigChange = o.onConfigChange.add(onChange)
+	}
+}
+
+// withoutOnConfigChange drops the callbacks of previous
+// [WithOnConfigChange] options, used by wrapping providers
+// invoking them on their own
+func withoutOnConfigChange() ConfigOption {
+	return func(o *configOptions) {
+		o.onConfigChange = nil
+	}
+}
+
+// WithStrictUnmarshal fails [Config] on unknown keys in the config source
+// and on fields of T which were not set by it.
+// Use it to catch typos in config keys at startup instead of silently
+// falling back to defaults. Note that every field of T must be present
+// in the config source, `default:""` tags do not count as set.
+func WithStrictUnmarshal() ConfigOption {
+	return func(o *configOptions) {
+		o.strictUnmarshal = true
+	}
+}
+
+// WithDefaulterOrder selects when [CustomDefaulter] is invoked,
+// defaults to [DefaultsBeforeUnmarshal].
+func WithDefaulterOrder(order DefaulterOrder) ConfigOption {
 	return func(o *configOptions) {
-		o.onConfigChange = callback
+		o.defaulterOrder = order
+	}
+}
+
+// WithSecretResolver resolves string values of the config starting with
+// any of prefixes using r after unmarshalling. Prefixes default to
+// [DefaultSecretPrefix]. It can be given multiple times to support
+// multiple secret stores, the first matching prefix is used.
+//
+//	configfx.WithSecretResolver(vaultResolver, "secret://", "vault://"),
+//	configfx.WithSecretResolver(configfx.EnvSecretResolver{}, "env://"),
+func WithSecretResolver(r SecretResolver, prefixes ...string) ConfigOption {
+	if len(prefixes) == 0 {
+		prefixes = []string{DefaultSecretPrefix}
+	}
+
+	return func(o *configOptions) {
+		o.secretResolvers = append(o.secretResolvers, secretResolver{
+			resolver: r,
+			prefixes: slices.Clone(prefixes),
+		})
+	}
+}
+
+// WithIndexedEnv allows overriding scalar values of list elements using
+// environment variables addressing them by index, like
+// MYAPP_ROUTES_0_PATH=/new for the key routes.0.path using the env prefix
+// MYAPP of the source. Only elements and fields present in the config
+// source can be overridden, lists can't be extended this way.
+func WithIndexedEnv() ConfigOption {
+	return func(o *configOptions) {
+		o.indexedEnv = true
+	}
+}
+
+// WithIncludeKey enables including other config files listed by the
+// top-level key of the config file, e.g. "include" for:
+//
+//	include: [base.yaml, extra.yaml]
+//
+// Relative paths are resolved relative to the including file, which may
+// include other files themselves. Files listed later take precedence and
+// the including file takes precedence over all of its includes.
+// Included files are not watched by [WithOnConfigChange].
+// Cycles are reported using [ErrIncludeCycle].
+func WithIncludeKey(key string) ConfigOption {
+	return func(o *configOptions) {
+		o.includeKey = key
+	}
+}
+
+// sourceFileOptions stores options for [SourceFileOption] funcs
+type sourceFileOptions struct {
+	searchPaths      []string
+	workingDirSearch bool
+	envAllowlist     []string
+	maxSize          int64
+	configType       string
+}
+
+// SourceFileOption is a func to adjust options of *sourceFileOptions for later
+// usage during [NewSourceFileWithOptions].
+type SourceFileOption func(*sourceFileOptions)
+
+// defaultSourceFileOptions returns the default *sourceFileOptions
+func defaultSourceFileOptions() *sourceFileOptions {
+	return &sourceFileOptions{
+		searchPaths:      make([]string, 0),
+		workingDirSearch: true,
+		maxSize:          DefaultMaxSize,
+	}
+}
+
+// WithSearchPaths overrides the paths to search for the config file.
+// [DefaultFileSearchPaths] are used if no paths are given.
+func WithSearchPaths(paths ...string) SourceFileOption {
+	return func(o *sourceFileOptions) {
+		o.searchPaths = append(o.searchPaths, paths...)
+	}
+}
+
+// WithoutWorkingDirSearch removes the working directory "." from
+// the search paths.
+// This prevents picking up an unrelated config file by accident
+// when running the binary from within a foreign directory.
+func WithoutWorkingDirSearch() SourceFileOption {
+	return func(o *sourceFileOptions) {
+		o.workingDirSearch = false
+	}
+}
+
+// WithEnvAllowlist restricts environment overrides to the given config
+// keys like "webserver.port" instead of considering every variable
+// starting with the env prefix.
+// Use it if the env prefix is generic to prevent surprising overrides
+// by ambient environment variables. List elements of [WithIndexedEnv]
+// are overridden only below the keys given.
+func WithEnvAllowlist(keys ...string) SourceFileOption {
+	return func(o *sourceFileOptions) {
+		o.envAllowlist = append(o.envAllowlist, keys...)
+	}
+}
+
+// WithMaxSize refuses to read config files larger than maxSize bytes,
+// including profile config files. It defaults to [DefaultMaxSize],
+// a maxSize <= 0 disables the limit.
+// The size is checked while reading, before any parsing takes place.
+func WithMaxSize(maxSize int64) SourceFileOption {
+	return func(o *sourceFileOptions) {
+		o.maxSize = maxSize
+	}
+}
+
+// WithConfigType sets the format of explicit config files given by
+// --config-file, e.g. "yaml". Use it to parse files without or with a
+// misleading extension or configs read from stdin using --config-file -.
+// If unset, the format of files with an extension unknown to viper is
+// detected from their content.
+// Searched config files are always parsed by their extension.
+func WithConfigType(configType string) SourceFileOption {
+	return func(o *sourceFileOptions) {
+		o.configType = configType
 	}
 }
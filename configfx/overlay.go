@@ -18,7 +18,9 @@ package configfx
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -31,12 +33,25 @@ type Overlay struct {
 	// Filename is the full filepath to the overlay config
 	Filename string `mapstructure:"filename" default:""`
 
+	// Data is an in-memory overlay config used instead of reading Filename.
+	// It is mutually exclusive with Filename.
+	Data map[string]any `mapstructure:"data"`
+
 	// From is the mapstructure path to the element which shall be used
 	From string `mapstructure:"from" default:""`
 
-	// To defines mapstructure paths where the [From] element gets injected
+	// To defines mapstructure paths where the [From] element gets injected.
+	// List elements can be selected using [key=value] or positional [N]:
+	//   to:
+	//   - "policy.rules.[name=replace-subject].match"
+	//   - "policy.rules.[0].match"
 	To []string `mapstructure:"to" default:"[]"`
 
+	// MergeKey is the element field identifying list elements selected
+	// by positional [N] operators in [To] paths.
+	// [key=value] operators always use key as merge key.
+	MergeKey string `mapstructure:"mergeKey" default:""`
+
 	// viper is used internally to read and parse the overlay config file
 	viper *viper.Viper
 
@@ -47,98 +62,155 @@ type Overlay struct {
 // ApplyTo loads the overlay from the filesystem and
 // merges it with vip *Viper and cfg or error.
 // Overlay config files are searched using full- and relative to main config file path.
-func (s *Overlay) applyTo(vip *viper.Viper, cfg any) error {
-	// remove file extension
-	extension := filepath.Ext(s.Filename)
-	filename := s.Filename[0 : len(s.Filename)-len(extension)]
-
+// The format is taken from the extension of Filename, if it is missing
+// the format is auto-detected by searching for any supported extension.
+// String values may reference environment variables using
+// ${ENV_VAR} or ${ENV_VAR:-default}.
+// Overlay config files larger than maxSize bytes are refused.
+func (s *Overlay) applyTo(vip *viper.Viper, cfg any, maxSize int64) error {
 	// fresh viper to read in overlay
 	s.viper = viper.New()
-	s.viper.SetConfigName(filename)
-	s.viper.AddConfigPath(filepath.Dir(vip.ConfigFileUsed()))
-	s.viper.AddConfigPath(".")
-	err := s.viper.ReadInConfig()
-	if err != nil {
-		return fmt.Errorf("reading overlay config %q failed: %s", s.Filename, err)
+	s.viper.SetFs(newNormalizedFs(maxSize))
+	switch {
+	case s.Data != nil && len(s.Filename) > 0:
+		return fmt.Errorf("overlay config %q must not define both filename and data", s.name())
+
+	case s.Data != nil:
+		// in-memory overlay, merged to normalize keys like files
+		if err := s.viper.MergeConfigMap(s.Data); err != nil {
+			return fmt.Errorf("reading overlay config %q failed: %s", s.name(), err)
+		}
+
+	default:
+		if err := s.configure(filepath.Dir(vip.ConfigFileUsed())); err != nil {
+			return err
+		}
+		if err := s.viper.ReadInConfig(); err != nil {
+			return fmt.Errorf("reading overlay config %q failed: %s", s.name(), err)
+		}
 	}
 
 	// retrieve the from key
 	fromPath := strings.Split(s.From, ".")
 	fromSlice := s.viper.AllSettings()
 	var from any
-	for _, elem := range fromPath {
+	for i, elem := range fromPath {
 		// retrieve path element
 		var ok bool
 		from, ok = fromSlice[elem]
 		if !ok {
-			return fmt.Errorf("referenced from field %q in path %q not found in overlay %q", elem, s.From, s.Filename)
+			return fmt.Errorf("referenced from field %q in path %q not found in overlay %q", elem, s.From, s.name())
+		}
+		if i == len(fromPath)-1 {
+			break
 		}
 
-		// check if it is a map for next iter
-		if cast, ok := from.(map[string]any); ok {
-			fromSlice = cast
+		// it must be a map for next iter
+		fromSlice, ok = from.(map[string]any)
+		if !ok {
+			return fmt.Errorf("referenced from field %q in path %q of overlay %q is of type %T, expected a map",
+				elem, s.From, s.name(), from)
 		}
 	}
 	// sanity check
 	if from == nil {
-		return fmt.Errorf("referenced from path %q is nil in overlay %q", s.From, s.Filename)
+		return fmt.Errorf("referenced from path %q is nil in overlay %q", s.From, s.name())
+	}
+
+	// substitute ${ENV_VAR} and ${ENV_VAR:-default} in string values
+	from, err := expandEnv(from)
+	if err != nil {
+		return fmt.Errorf("expanding environment in overlay %q failed: %s", s.name(), err)
+	}
+
+	// schema of cfg describing how to merge its fields
+	schema, err := strategicpatch.NewPatchMetaFromStruct(cfg)
+	if err != nil {
+		return fmt.Errorf("building patch schema of overlay config %q failed: %s", s.name(), err)
 	}
 
 	for _, path := range s.To {
-		// forge a config from values inside overlay by adding the desired path in front
-		forged := from
-		apath := strings.Split(path, ".")
-		for i := len(apath) - 1; i >= 0; i-- {
-			key := apath[i]
-			if strings.Contains(key, "[") {
-				// whenever we encounter [] operator we need to parse it
-				// this allows for syntax like this:
-				//   to:
-				//   - "policy.rules.[name=replace-subject].match.header.regex.[name=test].value"
-				trimmed := strings.TrimRight(strings.TrimLeft(key, "["), "]")
-				a, b, ok := strings.Cut(trimmed, "=")
-				if a != "name" {
-					return fmt.Errorf("[] operator in %q can only be used against name field", s.Filename)
-				}
-				if ok {
-					// [a=b]
-					v, ok := forged.(map[string]any)
-					if !ok {
-						return fmt.Errorf("[] operator in %q can only be used on map types", s.Filename)
-					}
-
-					// add the name=selector to existing map and wrap it inside a slice
-					v[a] = b
-					forged = []any{
-						v,
-					}
-				}
-
-			} else {
-				// otherwise we can easily add it as a map path
-				forged = map[string]any{
-					key: forged,
-				}
-			}
+		// parse the path and resolve its selectors against the current config
+		segments, err := parseOverlayPath(path)
+		if err != nil {
+			return fmt.Errorf("overlay config %q: %s", s.name(), err)
 		}
-		mforged, ok := forged.(map[string]any)
-		if !ok {
-			return fmt.Errorf("merging overlay config %q failed due to map cast", s.Filename)
+		if err := resolveOverlayPath(segments, vip.AllSettings(), s.MergeKey); err != nil {
+			return fmt.Errorf("overlay config %q path %q: %s", s.name(), path, err)
+		}
+
+		// forge a patch document from values inside overlay
+		forged, forgedSchema, err := forgeOverlayPatch(segments, from, schema)
+		if err != nil {
+			return fmt.Errorf("overlay config %q path %q: %s", s.name(), path, err)
 		}
 
 		// using Kubernetes strategic merge patch from forged patch documents
-		patch, err := strategicpatch.StrategicMergeMapPatch(
-			vip.AllSettings(), mforged, cfg)
+		patch, err := strategicpatch.StrategicMergeMapPatchUsingLookupPatchMeta(
+			vip.AllSettings(), forged, forgedSchema)
 		if err != nil {
-			return fmt.Errorf("building patch of overlay config %q failed: %s", s.Filename, err)
+			return fmt.Errorf("building patch of overlay config %q failed: %s", s.name(), err)
 		}
 
 		// merge into current viper configuration
 		err = vip.MergeConfigMap(patch)
 		if err != nil {
-			return fmt.Errorf("merging overlay config %q failed: %s", s.Filename, err)
+			return fmt.Errorf("merging overlay config %q failed: %s", s.name(), err)
 		}
 	}
 
 	return nil
 }
+
+// configure sets up s.viper to read Filename searched in configDir and
+// the working directory. It honors the extension of Filename to
+// select the config type and auto-detects it if missing.
+func (s *Overlay) configure(configDir string) error {
+	extension := filepath.Ext(s.Filename)
+
+	// no extension given: search for any supported extension
+	if len(extension) == 0 {
+		s.viper.SetConfigName(s.Filename)
+		s.viper.AddConfigPath(configDir)
+		s.viper.AddConfigPath(".")
+		return nil
+	}
+
+	// extension given: use its config type and this exact file
+	configType := strings.ToLower(extension[1:])
+	if !slices.Contains(viper.SupportedExts, configType) {
+		return fmt.Errorf("overlay config %q has unsupported extension, supported: %s",
+			s.Filename, strings.Join(viper.SupportedExts, ", "))
+	}
+	s.viper.SetConfigType(configType)
+
+	candidates := []string{s.Filename}
+	if !filepath.IsAbs(s.Filename) {
+		candidates = []string{
+			filepath.Join(configDir, s.Filename),
+			s.Filename,
+		}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			s.viper.SetConfigFile(candidate)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("overlay config %q not found in %q or working directory",
+		s.Filename, configDir)
+}
+
+// name returns a name of the overlay for use in messages
+func (s *Overlay) name() string {
+	if s.Data != nil {
+		return "<data>"
+	}
+	return s.Filename
+}
+
+// watchable returns true if the overlay is read from a file
+func (s *Overlay) watchable() bool {
+	return s.Data == nil
+}
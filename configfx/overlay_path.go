@@ -0,0 +1,270 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// overlaySegment is a single element of an overlay `to` path
+type overlaySegment struct {
+	// key is the map key of this segment if not a selector
+	key string
+
+	// selector denotes a [key=value] or [N] segment selecting a list element
+	selector bool
+	// selectorKey is the element field to match for [key=value] selectors
+	selectorKey string
+	// selectorValue is the element value to match for [key=value] selectors
+	selectorValue any
+	// index is the list position of [N] selectors or -1
+	index int
+}
+
+// parseOverlayPath splits path into segments.
+// Supported segments are map keys, [key=value] and [N] selectors:
+//
+//	policy.rules.[name=replace-subject].match.header.regex.[id=3].value
+//	policy.rules.[0].match
+func parseOverlayPath(path string) ([]*overlaySegment, error) {
+	segments := []*overlaySegment{}
+	for _, elem := range strings.Split(path, ".") {
+		if !strings.HasPrefix(elem, "[") {
+			segments = append(segments, &overlaySegment{key: elem, index: -1})
+			continue
+		}
+
+		if !strings.HasSuffix(elem, "]") || len(segments) == 0 || segments[len(segments)-1].selector {
+			return nil, fmt.Errorf("invalid [] operator %q in path %q", elem, path)
+		}
+		trimmed := elem[1 : len(elem)-1]
+
+		// [key=value]
+		if key, value, ok := strings.Cut(trimmed, "="); ok {
+			if len(key) == 0 {
+				return nil, fmt.Errorf("[] operator %q in path %q is missing a key", elem, path)
+			}
+			segments = append(segments, &overlaySegment{
+				selector:      true,
+				selectorKey:   key,
+				selectorValue: value,
+				index:         -1,
+			})
+			continue
+		}
+
+		// [N]
+		index, err := strconv.Atoi(trimmed)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("[] operator %q in path %q must be [key=value] or [N]", elem, path)
+		}
+		segments = append(segments, &overlaySegment{
+			selector: true,
+			index:    index,
+		})
+	}
+
+	return segments, nil
+}
+
+// resolveOverlayPath resolves the selectors of segments against settings.
+// Positional [N] selectors are rewritten to [mergeKey=value] using the value
+// of mergeKey found in the N-th element. Selector values of existing elements
+// are replaced by their original value to keep their type for merging.
+func resolveOverlayPath(segments []*overlaySegment, settings any, mergeKey string) error {
+	current := settings
+	for _, seg := range segments {
+		if !seg.selector {
+			m, _ := current.(map[string]any)
+			current = m[seg.key]
+			continue
+		}
+
+		list, _ := current.([]any)
+		current = nil
+
+		// [N]
+		if seg.index >= 0 {
+			if len(mergeKey) == 0 {
+				return fmt.Errorf("[%d] operator requires a mergeKey", seg.index)
+			}
+			if seg.index >= len(list) {
+				return fmt.Errorf("[%d] operator is out of range of %d elements", seg.index, len(list))
+			}
+			elem, ok := list[seg.index].(map[string]any)
+			if !ok || elem[mergeKey] == nil {
+				return fmt.Errorf("[%d] operator references an element without mergeKey %q", seg.index, mergeKey)
+			}
+			seg.selectorKey, seg.selectorValue = mergeKey, elem[mergeKey]
+			current = elem
+			continue
+		}
+
+		// [key=value]
+		for _, e := range list {
+			elem, ok := e.(map[string]any)
+			if !ok || elem[seg.selectorKey] == nil {
+				continue
+			}
+			if fmt.Sprint(elem[seg.selectorKey]) == fmt.Sprint(seg.selectorValue) {
+				seg.selectorValue = elem[seg.selectorKey]
+				current = elem
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// forgeOverlayPatch wraps value into a patch document following segments.
+// It returns the patch and a schema declaring merge keys for all selected lists.
+func forgeOverlayPatch(
+	segments []*overlaySegment,
+	value any,
+	schema strategicpatch.LookupPatchMeta,
+) (map[string]any, strategicpatch.LookupPatchMeta, error) {
+	// forge a config from values inside overlay by adding the desired path in front
+	forged := value
+	for i := len(segments) - 1; i >= 0; i-- {
+		seg := segments[i]
+		if !seg.selector {
+			forged = map[string]any{
+				seg.key: forged,
+			}
+			continue
+		}
+
+		v, ok := forged.(map[string]any)
+		if !ok {
+			return nil, nil, fmt.Errorf("[] operator can only be used on map types")
+		}
+
+		// add the key=selector to a copy of the map and wrap it inside a slice
+		v = maps.Clone(v)
+		v[seg.selectorKey] = seg.selectorValue
+		forged = []any{v}
+	}
+
+	patch, ok := forged.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("path must start with a map key")
+	}
+
+	// build the merge key tree of selected lists
+	root := &overlayPatchMeta{schema: schema, children: map[string]*overlayPatchMeta{}}
+	node := root
+	for _, seg := range segments {
+		if seg.selector {
+			// the previous segment denotes the list
+			node.mergeKey = seg.selectorKey
+			continue
+		}
+		child, ok := node.children[seg.key]
+		if !ok {
+			child = &overlayPatchMeta{children: map[string]*overlayPatchMeta{}}
+			node.children[seg.key] = child
+		}
+		node = child
+	}
+
+	return patch, root, nil
+}
+
+// overlayPatchMeta wraps a strategicpatch.LookupPatchMeta and declares
+// the merge strategy and merge key for lists selected by overlay paths.
+// A nil schema is treated as unknown, merging maps and replacing lists.
+type overlayPatchMeta struct {
+	schema   strategicpatch.LookupPatchMeta
+	children map[string]*overlayPatchMeta
+	mergeKey string
+}
+
+// ensure overlayPatchMeta implements strategicpatch.LookupPatchMeta
+var _ strategicpatch.LookupPatchMeta = &overlayPatchMeta{}
+
+// LookupPatchMetadataForStruct implements strategicpatch.LookupPatchMeta
+func (m *overlayPatchMeta) LookupPatchMetadataForStruct(
+	key string,
+) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	return m.lookup(key, false)
+}
+
+// LookupPatchMetadataForSlice implements strategicpatch.LookupPatchMeta
+func (m *overlayPatchMeta) LookupPatchMetadataForSlice(
+	key string,
+) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	return m.lookup(key, true)
+}
+
+// Name implements strategicpatch.LookupPatchMeta
+func (m *overlayPatchMeta) Name() string {
+	if m.schema == nil {
+		return "overlay"
+	}
+	return m.schema.Name()
+}
+
+// lookup returns the wrapped sub schema and patch meta of key
+func (m *overlayPatchMeta) lookup(
+	key string,
+	slice bool,
+) (strategicpatch.LookupPatchMeta, strategicpatch.PatchMeta, error) {
+	var (
+		sub  strategicpatch.LookupPatchMeta
+		meta strategicpatch.PatchMeta
+		err  error
+	)
+	if m.schema != nil {
+		if slice {
+			sub, meta, err = m.schema.LookupPatchMetadataForSlice(key)
+		} else {
+			sub, meta, err = m.schema.LookupPatchMetadataForStruct(key)
+		}
+	}
+
+	child, ok := m.children[key]
+	if !ok {
+		if err != nil {
+			return nil, strategicpatch.PatchMeta{}, err
+		}
+		if sub == nil {
+			return &overlayPatchMeta{}, meta, nil
+		}
+		return sub, meta, nil
+	}
+
+	// paths selected by the overlay continue with an unknown schema
+	// if the struct does not describe them
+	if err != nil {
+		sub, meta = nil, strategicpatch.PatchMeta{}
+	}
+	if slice && len(child.mergeKey) > 0 {
+		meta.SetPatchStrategies([]string{"merge"})
+		meta.SetPatchMergeKey(child.mergeKey)
+	}
+
+	return &overlayPatchMeta{
+		schema:   sub,
+		children: child.children,
+	}, meta, nil
+}
@@ -0,0 +1,377 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type overlayConfig struct {
+	Webserver struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	} `mapstructure:"webserver"`
+}
+
+func TestOverlayJSON(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", `
+webserver:
+  host: localhost
+  port: 8080
+`)
+	writeConfig(t, dir, "overlay.json", `{
+  "server": {"port": 9090}
+}`)
+	// same name using another format must not be picked up
+	writeConfig(t, dir, "overlay.yaml", `
+server:
+  port: 7070
+`)
+
+	cfg, err := newTestProvider[overlayConfig](filename).Config(
+		configfx.WithOverlays(&configfx.Overlay{
+			Filename: "overlay.json",
+			From:     "server",
+			To:       []string{"webserver"},
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, 9090, cfg.Webserver.Port)
+	assert.Equal(t, "localhost", cfg.Webserver.Host)
+}
+
+func TestOverlayUnsupportedExtension(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+webserver:
+  port: 8080
+`)
+
+	_, err := newTestProvider[overlayConfig](filename).Config(
+		configfx.WithOverlays(&configfx.Overlay{
+			Filename: "overlay.unknown",
+			From:     "server",
+			To:       []string{"webserver"},
+		}),
+	)
+	assert.ErrorContains(t, err, "unsupported extension")
+}
+
+func TestOverlayFromNonMap(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", `
+webserver:
+  port: 8080
+`)
+	writeConfig(t, dir, "overlay.yaml", `
+servers:
+  - port: 9090
+server:
+  port: 9090
+`)
+
+	tests := []struct {
+		name string
+		from string
+		err  string
+	}{
+		{
+			name: "slice",
+			from: "servers.port",
+			err:  `referenced from field "servers" in path "servers.port" of overlay "overlay.yaml" is of type []interface {}`,
+		},
+		{
+			name: "scalar",
+			from: "server.port.value",
+			err:  `referenced from field "port" in path "server.port.value" of overlay "overlay.yaml" is of type int`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestProvider[overlayConfig](filename).Config(
+				configfx.WithOverlays(&configfx.Overlay{
+					Filename: "overlay.yaml",
+					From:     tt.from,
+					To:       []string{"webserver"},
+				}),
+			)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+type selectorItem struct {
+	ID    string `mapstructure:"id"`
+	Name  string `mapstructure:"name"`
+	Value int    `mapstructure:"value"`
+}
+
+type selectorConfig struct {
+	Items []*selectorItem `mapstructure:"items"`
+}
+
+func TestOverlaySelectors(t *testing.T) {
+	tests := []struct {
+		to       string
+		mergeKey string
+	}{
+		{to: "items.[id=def]"},
+		{to: "items.[1]", mergeKey: "id"},
+		{to: "items.[name=second]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.to, func(t *testing.T) {
+			dir := t.TempDir()
+			filename := writeConfig(t, dir, "config.yaml", `
+items:
+- id: abc
+  name: first
+  value: 1
+- id: def
+  name: second
+  value: 2
+`)
+			writeConfig(t, dir, "overlay.yaml", `
+patch:
+  value: 42
+`)
+
+			cfg, err := newTestProvider[selectorConfig](filename).Config(
+				configfx.WithOverlays(&configfx.Overlay{
+					Filename: "overlay.yaml",
+					From:     "patch",
+					To:       []string{test.to},
+					MergeKey: test.mergeKey,
+				}),
+			)
+			require.NoError(t, err)
+
+			// only the selected element is patched, others are kept
+			require.Len(t, cfg.Items, 2)
+			assert.Equal(t, selectorItem{ID: "abc", Name: "first", Value: 1}, *cfg.Items[0])
+			assert.Equal(t, selectorItem{ID: "def", Name: "second", Value: 42}, *cfg.Items[1])
+		})
+	}
+}
+
+func TestOverlayPositionalWithoutMergeKey(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", `
+items:
+- id: abc
+`)
+	writeConfig(t, dir, "overlay.yaml", `
+patch:
+  value: 42
+`)
+
+	_, err := newTestProvider[selectorConfig](filename).Config(
+		configfx.WithOverlays(&configfx.Overlay{
+			Filename: "overlay.yaml",
+			From:     "patch",
+			To:       []string{"items.[0]"},
+		}),
+	)
+	assert.ErrorContains(t, err, "requires a mergeKey")
+}
+
+type envOverlayConfig struct {
+	Database struct {
+		User     string `mapstructure:"user"`
+		Password string `mapstructure:"password"`
+		Port     int    `mapstructure:"port"`
+	} `mapstructure:"database"`
+}
+
+func TestOverlayEnvSubstitution(t *testing.T) {
+	overlay := `
+db:
+  user: ${OVERLAY_TEST_USER:-admin}
+  password: ${OVERLAY_TEST_PASSWORD}
+  port: 5432
+`
+	tests := []struct {
+		name     string
+		env      map[string]string
+		user     string
+		password string
+		err      string
+	}{
+		{
+			name:     "set",
+			env:      map[string]string{"OVERLAY_TEST_USER": "app", "OVERLAY_TEST_PASSWORD": "secret"},
+			user:     "app",
+			password: "secret",
+		},
+		{
+			name:     "unset with default",
+			env:      map[string]string{"OVERLAY_TEST_PASSWORD": "secret"},
+			user:     "admin",
+			password: "secret",
+		},
+		{
+			name: "unset without default",
+			env:  map[string]string{},
+			err:  `"OVERLAY_TEST_PASSWORD" is not set`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, name := range []string{"OVERLAY_TEST_USER", "OVERLAY_TEST_PASSWORD"} {
+				if value, ok := test.env[name]; ok {
+					t.Setenv(name, value)
+				} else {
+					t.Setenv(name, "")
+					require.NoError(t, os.Unsetenv(name))
+				}
+			}
+
+			dir := t.TempDir()
+			filename := writeConfig(t, dir, "config.yaml", `
+database:
+  port: 3306
+`)
+			writeConfig(t, dir, "overlay.yaml", overlay)
+
+			cfg, err := newTestProvider[envOverlayConfig](filename).Config(
+				configfx.WithOverlays(&configfx.Overlay{
+					Filename: "overlay.yaml",
+					From:     "db",
+					To:       []string{"database"},
+				}),
+			)
+			if len(test.err) > 0 {
+				assert.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.user, cfg.Database.User)
+			assert.Equal(t, test.password, cfg.Database.Password)
+			assert.Equal(t, 5432, cfg.Database.Port) // non-strings untouched
+		})
+	}
+}
+
+func TestOverlayData(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", `
+webserver:
+  host: localhost
+  port: 8080
+`)
+	writeConfig(t, dir, "overlay.yaml", `
+server:
+  port: 9090
+`)
+
+	// file based overlay for reference
+	fromFile, err := newTestProvider[overlayConfig](filename).Config(
+		configfx.WithOverlays(&configfx.Overlay{
+			Filename: "overlay.yaml",
+			From:     "server",
+			To:       []string{"webserver"},
+		}),
+	)
+	require.NoError(t, err)
+
+	// in-memory overlay
+	fromData, err := newTestProvider[overlayConfig](filename).Config(
+		configfx.WithOverlays(&configfx.Overlay{
+			Data: map[string]any{
+				"Server": map[string]any{"port": 9090},
+			},
+			From: "server",
+			To:   []string{"webserver"},
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, fromFile, fromData)
+	assert.Equal(t, 9090, fromData.Webserver.Port)
+	assert.Equal(t, "localhost", fromData.Webserver.Host)
+
+	// filename and data are mutually exclusive
+	_, err = newTestProvider[overlayConfig](filename).Config(
+		configfx.WithOverlays(&configfx.Overlay{
+			Filename: "overlay.yaml",
+			Data:     map[string]any{},
+		}),
+	)
+	assert.ErrorContains(t, err, "must not define both")
+}
+
+type postValidatedConfig struct {
+	Webserver struct {
+		Port int `mapstructure:"port"`
+	} `mapstructure:"webserver"`
+	Metrics struct {
+		Port int `mapstructure:"port"`
+	} `mapstructure:"metrics"`
+}
+
+var errPortConflict = errors.New("webserver and metrics use the same port")
+
+// PostValidate implements configfx.PostValidator
+func (c *postValidatedConfig) PostValidate(context.Context) error {
+	if c.Webserver.Port == c.Metrics.Port {
+		return errPortConflict
+	}
+	return nil
+}
+
+func TestOverlayPostValidate(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", `
+webserver:
+  port: 8080
+metrics:
+  port: 9090
+`)
+
+	// config returns the config using an overlay setting the webserver port
+	config := func(port string) (*postValidatedConfig, error) {
+		writeConfig(t, dir, "overlay.yaml", "server:\n  port: "+port+"\n")
+		return newTestProvider[postValidatedConfig](filename).Config(
+			configfx.WithOverlays(&configfx.Overlay{
+				Filename: "overlay.yaml",
+				From:     "server",
+				To:       []string{"webserver"},
+			}),
+		)
+	}
+
+	cfg, err := config("7070")
+	require.NoError(t, err)
+	assert.Equal(t, 7070, cfg.Webserver.Port)
+
+	// the base config is valid, only the overlay introduces the conflict
+	cfg, err = config("9090")
+	assert.Nil(t, cfg)
+	assert.ErrorIs(t, err, errPortConflict)
+}
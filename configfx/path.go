@@ -0,0 +1,112 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetPath returns the value addressed by the dotted key inside settings
+// as returned by viper.AllSettings. Numeric segments index into lists,
+// e.g. "routes.0.path". It returns false if key can't be resolved.
+func GetPath(settings map[string]any, key string) (any, bool) {
+	var node any = settings
+	for _, part := range strings.Split(strings.ToLower(key), ".") {
+		v := reflect.ValueOf(node)
+		switch v.Kind() {
+		case reflect.Map:
+			elem := v.MapIndex(reflect.ValueOf(part))
+			if !elem.IsValid() {
+				return nil, false
+			}
+			node = elem.Interface()
+
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= v.Len() {
+				return nil, false
+			}
+			node = v.Index(index).Interface()
+
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+// SetPath sets value at the dotted key inside settings as returned by
+// viper.AllSettings. Numeric segments index into lists, an index equal to
+// the length of a list appends to it. Missing maps are created.
+func SetPath(settings map[string]any, key string, value any) error {
+	_, err := setPath(settings, strings.Split(strings.ToLower(key), "."), value)
+	if err != nil {
+		return fmt.Errorf("set %q: %s", key, err)
+	}
+
+	return nil
+}
+
+// setPath sets value at parts inside node and returns the updated node
+func setPath(node any, parts []string, value any) (any, error) {
+	if len(parts) == 0 {
+		return value, nil
+	}
+	part := parts[0]
+
+	switch n := node.(type) {
+	case nil:
+		// missing elements are created as maps
+		return setPath(map[string]any{}, parts, value)
+
+	case map[string]any:
+		child, err := setPath(n[part], parts[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[part] = child
+		return n, nil
+	}
+
+	v := reflect.ValueOf(node)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%q addresses a %T", part, node)
+	}
+	index, err := strconv.Atoi(part)
+	if err != nil || index < 0 || index > v.Len() {
+		return nil, fmt.Errorf("invalid index %q of list with %d elements", part, v.Len())
+	}
+
+	// copy the list to allow elements of any type
+	list := make([]any, v.Len(), v.Len()+1)
+	for i := range v.Len() {
+		list[i] = v.Index(i).Interface()
+	}
+	if index == len(list) {
+		list = append(list, nil)
+	}
+
+	list[index], err = setPath(list[index], parts[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPath(t *testing.T) {
+	settings := map[string]any{
+		"webserver": map[string]any{"port": 8080},
+		"routes": []any{
+			map[string]any{"path": "/"},
+			map[string]any{"path": "/example"},
+		},
+		"tags": []string{"a", "b"},
+	}
+
+	tests := []struct {
+		key   string
+		value any
+		found bool
+	}{
+		{key: "webserver.port", value: 8080, found: true},
+		{key: "Routes.1.Path", value: "/example", found: true},
+		{key: "tags.0", value: "a", found: true},
+		{key: "routes.2.path"},
+		{key: "routes.first.path"},
+		{key: "webserver.port.number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, found := configfx.GetPath(settings, tt.key)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.value, value)
+		})
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	settings := map[string]any{
+		"routes": []any{
+			map[string]any{"path": "/", "content": "hello"},
+		},
+		"tags": []string{"a"},
+	}
+
+	require.NoError(t, configfx.SetPath(settings, "routes.0.path", "/index"))
+	require.NoError(t, configfx.SetPath(settings, "routes.1.path", "/new"))
+	require.NoError(t, configfx.SetPath(settings, "tags.0", "b"))
+	require.NoError(t, configfx.SetPath(settings, "webserver.host", "localhost"))
+
+	assert.Equal(t, map[string]any{
+		"routes": []any{
+			map[string]any{"path": "/index", "content": "hello"},
+			map[string]any{"path": "/new"},
+		},
+		"tags":      []any{"b"},
+		"webserver": map[string]any{"host": "localhost"},
+	}, settings)
+
+	assert.ErrorContains(t, configfx.SetPath(settings, "routes.3.path", "x"),
+		`invalid index "3" of list with 2 elements`)
+	assert.ErrorContains(t, configfx.SetPath(settings, "webserver.host.name", "x"),
+		`"name" addresses a string`)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProfiledSource denotes sources which support config profiles.
+// A profile is a config file next to the base config file named
+// <configName>.<profile>.<ext> which is merged onto the base config.
+//
+// In order of decreasing precedence config values are taken from:
+// - environment variables
+// - the profile config file
+// - the base config file
+// - `default:""` struct tags
+type ProfiledSource interface {
+	// Profile shall return the selected profile or an empty string
+	Profile() string
+}
+
+// ProfileFilename returns the filename of profile belonging to
+// the config file filename: <dir>/<name>.<profile>.<ext>
+func ProfileFilename(filename, profile string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + profile + ext
+}
+
+// mergeProfile merges the profile config file onto the config
+// previously read by v or error. Files exceeding maxSize are refused.
+func mergeProfile(v *viper.Viper, profile string, maxSize int64) error {
+	filename := ProfileFilename(v.ConfigFileUsed(), profile)
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("profile %q: %s", profile, err)
+	}
+	defer f.Close()
+	b, err := readConfig(f, filename, maxSize)
+	if err != nil {
+		return fmt.Errorf("profile %q: %w", profile, err)
+	}
+
+	if err := v.MergeConfig(bytes.NewReader(normalizeConfig(b))); err != nil {
+		return fmt.Errorf("profile %q: merging %q: %s", profile, filename, err)
+	}
+
+	return nil
+}
@@ -17,24 +17,179 @@ limitations under the License.
 package configfx
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/choopm/stdfx/loggingfx/slogfx"
-	"github.com/creasty/defaults"
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"go.uber.org/fx"
 )
 
 // Provider defines an interface for abstract config providers
 type Provider[T any] interface {
-	// Config shall return the generic config or error
+	// Config shall return the generic config or error.
+	// The config shall depend on opts, implementations caching it
+	// must not return a config parsed using different opts.
 	Config(opts ...ConfigOption) (*T, error)
 	// Viper shall return the viper instance
 	Viper() *viper.Viper
 }
 
+// ContextProvider denotes providers supporting the cancellation of
+// reading the config using a context, see [ConfigContext].
+type ContextProvider[T any] interface {
+	Provider[T]
+
+	// ConfigContext shall return the generic config or error
+	// aborting once ctx is done.
+	ConfigContext(ctx context.Context, opts ...ConfigOption) (*T, error)
+}
+
+// ContextSource denotes sources reading the config into viper themselves
+// honoring a context instead of using viper.ReadInConfig, e.g. remote
+// sources which might be slow.
+type ContextSource interface {
+	// ReadConfigContext shall read the config into v or return an
+	// error wrapping ctx.Err() once ctx is done.
+	ReadConfigContext(ctx context.Context, v *viper.Viper) error
+}
+
+// fileSource denotes sources building a *viper.Viper reading the config
+// file without env bindings, used to tell the file apart from overrides
+type fileSource interface {
+	fileViper(opts ...viper.Option) *viper.Viper
+}
+
+// FileSettings returns the settings found in the config file of provider
+// without defaults, env, flags or overlays. Files included using
+// [WithIncludeKey] by the most recent Config call are merged and the
+// include key is removed. It fails for providers not reading a file.
+func FileSettings[T any](provider Provider[T]) (map[string]any, error) {
+	reader, ok := provider.(interface {
+		fileSettings() (map[string]any, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("provider %T does not read a config file", provider)
+	}
+
+	return reader.fileSettings()
+}
+
+// ConfigFile is a config file read by a provider
+type ConfigFile struct {
+	// Name is the path of the file as reported by viper.ConfigFileUsed
+	Name string
+	// Type is the format the file is parsed with, e.g. yaml
+	Type string
+	// Content is the content of the file as parsed by viper, without
+	// a leading BOM and using LF line endings
+	Content []byte
+}
+
+// fileLister denotes sources listing the config files read into a
+// *viper.Viper built by them
+type fileLister interface {
+	configFiles(v *viper.Viper) ([]ConfigFile, error)
+}
+
+// ErrNoConfigFile is returned by [ConfigFiles] for providers
+// and sources not reading a config file
+var ErrNoConfigFile = errors.New("no config file")
+
+// ConfigFiles returns the config files of provider as read by viper,
+// honoring stdin and the size limit of the source. Sources merging
+// several files return all of them, files included using [WithIncludeKey]
+// and profiles are omitted. It fails using [ErrNoConfigFile] for
+// providers not reading a file or using the error of ctx once it is done.
+// Files which block reading, like stdin or FIFOs, keep being read in the
+// background after ctx is done.
+func ConfigFiles[T any](ctx context.Context, provider Provider[T]) ([]ConfigFile, error) {
+	lister, ok := provider.(interface {
+		configFiles() ([]ConfigFile, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("%w: provider %T", ErrNoConfigFile, provider)
+	}
+
+	type result struct {
+		files []ConfigFile
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		files, err := lister.configFiles()
+		done <- result{files, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.files, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CloseOnStop closes provider once lc stops, stopping the watchers
+// started by [WithOnConfigChange] and by sources like [SourceDir].
+// Usage example:
+//
+//	fx.Invoke(configfx.CloseOnStop[Config]),
+func CloseOnStop[T any](lc fx.Lifecycle, provider Provider[T]) {
+	lc.Append(fx.StopHook(func() error {
+		if closer, ok := provider.(io.Closer); ok {
+			return closer.Close()
+		}
+		return nil
+	}))
+}
+
+// EnvAllowlist returns the config keys which might be overridden by
+// environment variables of provider, see [WithEnvAllowlist].
+// It returns nil if any key might be overridden.
+func EnvAllowlist[T any](provider Provider[T]) []string {
+	if restricted, ok := provider.(interface{ envAllowlist() []string }); ok {
+		return restricted.envAllowlist()
+	}
+	return nil
+}
+
+// ConfigContext returns the config of provider using opts or the error of
+// ctx once it is done. Providers not implementing [ContextProvider] keep
+// reading the config in the background after ctx is done.
+func ConfigContext[T any](ctx context.Context, provider Provider[T], opts ...ConfigOption) (*T, error) {
+	if cp, ok := provider.(ContextProvider[T]); ok {
+		return cp.ConfigContext(ctx, opts...)
+	}
+
+	type result struct {
+		config *T
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		config, err := provider.Config(opts...)
+		done <- result{config, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.config, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // providerImpl implements Provider[T]
 type providerImpl[T any] struct {
 	source Source[T]
@@ -43,19 +198,46 @@ type providerImpl[T any] struct {
 	viper      *viper.Viper
 	viperMutex sync.Mutex
 
-	viperWatchOnce sync.Once
+	// onChange are the callbacks of WithOnConfigChange of all Config calls
+	onChange      onChangeCallbacks
+	onChangeMutex sync.Mutex
+
+	// watcher watches the config file once a callback has been added
+	watcher      *fsnotify.Watcher
+	watching     bool
+	watcherMutex sync.Mutex
+
+	// pflags are the flags bound using BindFlags by key,
+	// bound again to every new viper instance
+	pflags map[string]*pflag.Flag
+
+	// includeKey is the include key of the most recent Config call
+	includeKey atomic.Value
 }
 
-// ensure providerImpl[T] implements Provider[T]
-var _ Provider[any] = &providerImpl[any]{}
+// ensure providerImpl[T] implements ContextProvider[T] and io.Closer
+var (
+	_ ContextProvider[any] = &providerImpl[any]{}
+	_ io.Closer            = &providerImpl[any]{}
+)
 
 // NewProvider returns a config provider to fetch the config.
 // Internally the config source is provided by viper and parsed the
 // moment one does call Provider[T].Config().
+// It defines the root flags adjusting config loading of source,
+// like --env-prefix and --config-file of [NewSourceFile].
 func NewProvider[T any](
 	source Source[T], // construct using [NewSourceFile]
 	log *slog.Logger, // logger for use with viper of source
 ) Provider[T] {
+	defineFlags(source)
+
+	return newProvider[T](source, log)
+}
+
+// newProvider returns a config provider of source
+// without defining root flags
+func newProvider[T any](source Source[T], log *slog.Logger) *providerImpl[T] {
 	return &providerImpl[T]{
 		source: source,
 		log:    log.With(slog.String("context", "config-provider")),
@@ -67,6 +249,17 @@ func NewProvider[T any](
 // Internally it requests a Viper instance from the ConfigSource[T]
 // to then unmarshall it onto *T using mapstructure and default tags.
 func (s *providerImpl[T]) Config(opts ...ConfigOption) (*T, error) {
+	return s.ConfigContext(context.Background(), opts...)
+}
+
+// ConfigContext implements ContextProvider[T].
+// It behaves like Config but aborts once ctx is done. The context is
+// passed to sources implementing [ContextSource] and to secret resolvers.
+func (s *providerImpl[T]) ConfigContext(ctx context.Context, opts ...ConfigOption) (*T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// apply any given opts
 	cOpts := defaultConfigOptions()
 	for _, option := range opts {
@@ -79,59 +272,264 @@ func (s *providerImpl[T]) Config(opts ...ConfigOption) (*T, error) {
 	// set default values by struct tags `default:""` on t
 	// viper will override what is present afterwards
 	s.log.Debug("setting defaults")
-	if err := defaults.Set(t); err != nil {
+	if err := setDefaults(t); err != nil {
 		return nil, fmt.Errorf("setting config defaults: %s", err)
 	}
+	if cOpts.defaulterOrder == DefaultsBeforeUnmarshal {
+		if err := customDefaults(t, s.log); err != nil {
+			return nil, err
+		}
+	}
 
 	// build default decoders
 	decoders := DefaultDecoders()
-	// check if T implements CustomDecoder
-	if ctype, ok := any(t).(CustomDecoder); ok {
-		// T implements CustomDecoder and therefore
-		// has a custom func DecodeHook(), use it:
-		s.log.Debug("found custom config DecodeHook()")
-		decoders = append(decoders, ctype.DecodeHook())
+	// check if T or any nested field implements CustomDecoder
+	if hooks := collectDecodeHooks(t); len(hooks) > 0 {
+		// they have a custom func DecodeHook(), use them:
+		s.log.Debug("found custom config DecodeHook()",
+			slog.Int("count", len(hooks)))
+		decoders = append(decoders, hooks...)
 	}
 
 	// get viper instance
 	v := s.Viper()
-	if cOpts.onConfigChange != nil {
-		v.OnConfigChange(cOpts.onConfigChange)
-		s.viperWatchOnce.Do(v.WatchConfig)
-	}
 
 	if cOpts.readInConfig {
-		// let viper read the config from source
-		if err := v.ReadInConfig(); err != nil {
+		s.includeKey.Store(cOpts.includeKey)
+		if err := s.readConfig(ctx, v, cOpts.includeKey); err != nil {
 			s.releaseViper()
-			return nil, fmt.Errorf("read config: %s", err)
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+	}
+
+	// chain the callbacks and watch the config read
+	if len(cOpts.onConfigChange) > 0 {
+		s.addOnChange(cOpts.onConfigChange)
+		if err := s.watch(v); err != nil {
+			return nil, fmt.Errorf("watch config: %s", err)
 		}
 	}
 
+	// sources not honoring ctx might have returned after it was done
+	if err := ctx.Err(); err != nil {
+		s.releaseViper()
+		return nil, err
+	}
+
 	// apply any overlays
 	for _, overlay := range cOpts.overlays {
-		if err := overlay.applyTo(v, t); err != nil {
+		if err := overlay.applyTo(v, t, s.maxSize()); err != nil {
 			return nil, fmt.Errorf("apply overlay: %s", err)
 		}
-		if cOpts.onConfigChange != nil {
-			overlay.viper.OnConfigChange(cOpts.onConfigChange)
+		if len(cOpts.onConfigChange) > 0 && overlay.watchable() {
+			overlay.viper.OnConfigChange(s.callOnChange)
 			overlay.viperWatchOnce.Do(overlay.viper.WatchConfig)
 		}
 	}
 
+	// override list elements by indexed env variables
+	if cOpts.indexedEnv {
+		if err := applyIndexedEnv(v, s.envAllowlist(), os.LookupEnv); err != nil {
+			return nil, fmt.Errorf("indexed env: %s", err)
+		}
+	}
+
 	// decode config using viper and struct tags `mapstructure:""`
 	s.log.Debug("unmarshalling config using viper")
-	err := v.Unmarshal(t, viper.DecodeHook(
-		mapstructure.ComposeDecodeHookFunc(decoders...),
-	))
+	dOpts := []viper.DecoderConfigOption{
+		viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(decoders...)),
+	}
+	if cOpts.strictUnmarshal {
+		dOpts = append(dOpts, func(c *mapstructure.DecoderConfig) {
+			c.ErrorUnused = true
+			c.ErrorUnset = true
+		})
+	}
+	unmarshal := v.Unmarshal
+	if len(cOpts.includeKey) > 0 {
+		unmarshal = func(rawVal any, opts ...viper.DecoderConfigOption) error {
+			return unmarshalWithout(v, cOpts.includeKey, rawVal, opts...)
+		}
+	}
+	err := unmarshal(t, dOpts...)
 	if err != nil {
 		s.releaseViper()
 		return nil, fmt.Errorf("unmarshal config: %s", err)
 	}
 
+	// set defaults on elements of slices and maps defined by the source
+	if err := setElementDefaults(reflect.ValueOf(t)); err != nil {
+		return nil, fmt.Errorf("setting config element defaults: %s", err)
+	}
+	if cOpts.defaulterOrder == DefaultsAfterUnmarshal {
+		if err := customDefaults(t, s.log); err != nil {
+			return nil, err
+		}
+	}
+
+	// check requiredWith and mutuallyExclusive field constraints
+	if err := validateConstraints(reflect.ValueOf(t), ""); err != nil {
+		return nil, fmt.Errorf("config constraints: %s", err)
+	}
+
+	// resolve secret references of string values
+	if len(cOpts.secretResolvers) > 0 {
+		s.log.Debug("resolving config secrets")
+		err := cOpts.secretResolvers.resolve(ctx, reflect.ValueOf(t), "")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// validate the fully resolved config
+	if ctype, ok := any(t).(PostValidator); ok {
+		s.log.Debug("found custom config PostValidate()")
+		if err := ctype.PostValidate(ctx); err != nil {
+			return nil, fmt.Errorf("post validate config: %w", err)
+		}
+	}
+
 	return t, nil
 }
 
+// fileSettings returns the settings found in the config file of the source,
+// see [FileSettings]
+func (s *providerImpl[T]) fileSettings() (map[string]any, error) {
+	var v *viper.Viper
+	if fs, ok := s.source.(fileSource); ok {
+		v = fs.fileViper(s.viperOptions()...)
+	} else {
+		v = s.source.Viper(s.viperOptions()...)
+	}
+
+	includeKey, _ := s.includeKey.Load().(string)
+	if err := s.readConfig(context.Background(), v, includeKey); err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	settings := v.AllSettings()
+	delete(settings, strings.ToLower(includeKey))
+
+	return settings, nil
+}
+
+// configFiles returns the config files read by the source,
+// see [ConfigFiles]
+func (s *providerImpl[T]) configFiles() ([]ConfigFile, error) {
+	lister, ok := s.source.(fileLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: source %T", ErrNoConfigFile, s.source)
+	}
+
+	v, err := readSource[T](s.source)
+	if err != nil {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+
+	return lister.configFiles(v)
+}
+
+// readConfig reads the config of the source into v and merges the files
+// included using includeKey and the selected profile. Config and
+// fileSettings use it to see the same settings.
+func (s *providerImpl[T]) readConfig(ctx context.Context, v *viper.Viper, includeKey string) error {
+	// let viper or the source read the config
+	readInConfig := v.ReadInConfig
+	if fragmented, ok := s.source.(FragmentedSource); ok {
+		readInConfig = func() error { return fragmented.ReadFragments(v) }
+	}
+	if ctxSource, ok := s.source.(ContextSource); ok {
+		readInConfig = func() error { return ctxSource.ReadConfigContext(ctx, v) }
+	}
+	if err := readInConfig(); err != nil {
+		return err
+	}
+
+	return s.mergeConfig(v, includeKey)
+}
+
+// mergeConfig merges the files included using includeKey and the selected
+// profile onto the config read into v. Config changes noticed by viper
+// use it as viper only reads the config file again.
+func (s *providerImpl[T]) mergeConfig(v *viper.Viper, includeKey string) error {
+	// merge the config files included by the config file
+	if _, isFragmented := s.source.(FragmentedSource); len(includeKey) > 0 && !isFragmented {
+		if err := readIncludes(v, includeKey, s.maxSize()); err != nil {
+			return err
+		}
+	}
+
+	// merge the profile config onto the base config if selected
+	if ctype, ok := s.source.(ProfiledSource); ok && len(ctype.Profile()) > 0 {
+		s.log.Debug("merging config profile",
+			slog.String("profile", ctype.Profile()))
+		if err := mergeProfile(v, ctype.Profile(), s.maxSize()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close implements io.Closer.
+// It stops watching the config file and closes the source
+// if it implements io.Closer.
+func (s *providerImpl[T]) Close() error {
+	err := s.stopWatching()
+	if closer, ok := s.source.(io.Closer); ok {
+		return errors.Join(err, closer.Close())
+	}
+	return err
+}
+
+// envAllowlist returns the config keys which might be overridden
+// by the environment or nil if any key might be overridden
+func (s *providerImpl[T]) envAllowlist() []string {
+	if restricted, ok := s.source.(EnvRestrictedSource); ok {
+		return restricted.EnvAllowlist()
+	}
+	return nil
+}
+
+// maxSize returns the maximum size of config files of the source
+func (s *providerImpl[T]) maxSize() int64 {
+	if limited, ok := s.source.(SizeLimitedSource); ok {
+		return limited.MaxSize()
+	}
+	return DefaultMaxSize
+}
+
+// MustConfig returns the config of provider using opts and panics on errors.
+// It is meant for top-level code like main funcs where the only way of
+// handling a broken config is to abort. Libraries and commands should
+// use the error returned by Provider[T].Config instead.
+//
+//	cfg := configfx.MustConfig(provider)
+func MustConfig[T any](provider Provider[T], opts ...ConfigOption) *T {
+	cfg, err := provider.Config(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("config: %s", err))
+	}
+
+	return cfg
+}
+
+// customDefaults invokes SetDefaults() if t implements [CustomDefaulter]
+func customDefaults(t any, log *slog.Logger) error {
+	ctype, ok := t.(CustomDefaulter)
+	if !ok {
+		return nil
+	}
+
+	// T implements CustomDefaulter and therefore
+	// has a custom func SetDefaults(), use it:
+	log.Debug("found custom config SetDefaults()")
+	if err := ctype.SetDefaults(); err != nil {
+		return fmt.Errorf("setting custom config defaults: %s", err)
+	}
+
+	return nil
+}
+
 // releaseViper should be called when viper needs to be freed after errors.
 // This might be the case for any decorator attempt on reading the config,
 // thus blocking future parsing attempts after e.g. cobra flags have been read.
@@ -154,9 +552,37 @@ func (s *providerImpl[T]) Viper() *viper.Viper {
 		return s.viper
 	}
 
-	// build viper instance using default options
-	// for all config sources
-	vOpts := []viper.Option{
+	s.viper = s.source.Viper(s.viperOptions()...)
+	for key, flag := range s.pflags {
+		if err := s.viper.BindPFlag(key, flag); err != nil {
+			s.log.Warn("failed to bind flag",
+				slog.String("key", key),
+				slog.Any("error", err))
+		}
+	}
+
+	return s.viper
+}
+
+// bindPFlag binds flag to key of the current and any future viper instance
+func (s *providerImpl[T]) bindPFlag(key string, flag *pflag.Flag) error {
+	v := s.Viper()
+
+	s.viperMutex.Lock()
+	defer s.viperMutex.Unlock()
+
+	if s.pflags == nil {
+		s.pflags = map[string]*pflag.Flag{}
+	}
+	s.pflags[key] = flag
+
+	return v.BindPFlag(key, flag)
+}
+
+// viperOptions returns the default options of viper instances
+// for all config sources
+func (s *providerImpl[T]) viperOptions() []viper.Option {
+	return []viper.Option{
 		// viper logs using Info by default, therefore we wrap
 		// it into a separate logger which logs to debug instead
 		viper.WithLogger(slogfx.AtLevel(
@@ -164,7 +590,4 @@ func (s *providerImpl[T]) Viper() *viper.Viper {
 			slog.LevelDebug,
 		)),
 	}
-	s.viper = s.source.Viper(vOpts...)
-
-	return s.viper
 }
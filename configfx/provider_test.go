@@ -0,0 +1,427 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileSource is a configfx.Source[T] reading an explicit config file.
+// It is used instead of configfx.NewSourceFile which registers global flags.
+type fileSource[T any] struct {
+	filename string
+}
+
+// Viper implements configfx.Source[T]
+func (s *fileSource[T]) Viper(opts ...viper.Option) *viper.Viper {
+	v := viper.NewWithOptions(opts...)
+	v.SetConfigFile(s.filename)
+	return v
+}
+
+// writeConfig writes content to a file name inside dir and returns its path
+func writeConfig(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	filename := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+
+	return filename
+}
+
+// newTestProvider returns a configfx.Provider[T] reading filename
+func newTestProvider[T any](filename string) configfx.Provider[T] {
+	return configfx.NewProvider[T](
+		&fileSource[T]{filename: filename},
+		slog.New(slog.DiscardHandler),
+	)
+}
+
+type SharedFragment struct {
+	Tags    []string          `mapstructure:"tags" default:"[\"a\",\"b\"]"`
+	Limits  map[string]int    `mapstructure:"limits" default:"{\"cpu\":2}"`
+	Labels  map[string]string `mapstructure:"labels" default:"{}"`
+	Retries int               `mapstructure:"retries" default:"3"`
+}
+
+type embeddingConfig struct {
+	*SharedFragment  `mapstructure:",squash"`
+	loggingfx.Config `mapstructure:",squash"`
+
+	Anonymous struct {
+		Name  string `mapstructure:"name" default:"anon"`
+		Ports []int  `mapstructure:"ports" default:"[80,443]"`
+	} `mapstructure:"anonymous"`
+}
+
+func TestProviderEmbeddedDefaults(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+retries: 5
+level: debug
+`)
+
+	cfg, err := newTestProvider[embeddingConfig](filename).Config()
+	require.NoError(t, err)
+
+	// embedded by pointer
+	require.NotNil(t, cfg.SharedFragment)
+	assert.Equal(t, 5, cfg.Retries)
+	assert.Equal(t, []string{"a", "b"}, cfg.Tags)
+	assert.Equal(t, map[string]int{"cpu": 2}, cfg.Limits)
+	assert.NotNil(t, cfg.Labels)
+
+	// embedded by value
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, "stdout", cfg.Output)
+	assert.Equal(t, "text", cfg.Format)
+
+	// anonymous struct
+	assert.Equal(t, "anon", cfg.Anonymous.Name)
+	assert.Equal(t, []int{80, 443}, cfg.Anonymous.Ports)
+}
+
+// profiledSource is a fileSource[T] implementing configfx.ProfiledSource
+type profiledSource[T any] struct {
+	fileSource[T]
+	profile string
+}
+
+// Profile implements configfx.ProfiledSource
+func (s *profiledSource[T]) Profile() string {
+	return s.profile
+}
+
+type profileConfig struct {
+	Host string `mapstructure:"host" default:"localhost"`
+	Port int    `mapstructure:"port" default:"80"`
+	Name string `mapstructure:"name" default:"app"`
+}
+
+func TestProviderProfile(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "app.yaml", `
+host: base.example.com
+port: 8080
+`)
+	writeConfig(t, dir, "app.prod.yaml", `
+host: prod.example.com
+`)
+	t.Setenv("APP_PORT", "9090")
+
+	source := &profiledSource[profileConfig]{
+		fileSource: fileSource[profileConfig]{filename: filename},
+		profile:    "prod",
+	}
+	provider := configfx.NewProvider[profileConfig](source, slog.New(slog.DiscardHandler))
+	v := provider.Viper()
+	v.SetEnvPrefix("APP")
+	v.AutomaticEnv()
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod.example.com", cfg.Host) // profile overrides base
+	assert.Equal(t, 9090, cfg.Port)               // env overrides profile
+	assert.Equal(t, "app", cfg.Name)              // defaults
+	assert.Equal(t, filepath.Join(dir, "app.prod.yaml"),
+		configfx.ProfileFilename(filename, "prod"))
+
+	// missing profile files are reported
+	source.profile = "missing"
+	_, err = configfx.NewProvider[profileConfig](source, slog.New(slog.DiscardHandler)).Config()
+	assert.ErrorContains(t, err, `profile "missing"`)
+}
+
+func TestProviderProfileReload(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "app.yaml", `
+host: base.example.com
+port: 8080
+`)
+	writeConfig(t, dir, "app.prod.yaml", `
+host: prod.example.com
+`)
+
+	source := &profiledSource[profileConfig]{
+		fileSource: fileSource[profileConfig]{filename: filename},
+		profile:    "prod",
+	}
+	provider := configfx.NewProvider[profileConfig](source, slog.New(slog.DiscardHandler))
+
+	hosts := make(chan string, 16)
+	_, err := provider.Config(configfx.WithOnConfigChange(func(fsnotify.Event) {
+		hosts <- provider.Viper().GetString("host")
+	}))
+	require.NoError(t, err)
+
+	// the file settings include the profile
+	settings, err := configfx.FileSettings(provider)
+	require.NoError(t, err)
+	assert.Equal(t, "prod.example.com", settings["host"])
+
+	// viper reading the changed config file keeps the profile
+	writeConfig(t, dir, "app.yaml", `
+host: base.example.com
+port: 9090
+`)
+	select {
+	case host := <-hosts:
+		assert.Equal(t, "prod.example.com", host)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change callback was not invoked")
+	}
+	assert.Equal(t, 9090, provider.Viper().GetInt("port"))
+}
+
+func TestProviderStrictUnmarshal(t *testing.T) {
+	dir := t.TempDir()
+
+	// all keys known and set
+	filename := writeConfig(t, dir, "valid.yaml", `
+host: example.com
+port: 8080
+name: app
+`)
+	cfg, err := newTestProvider[profileConfig](filename).Config(configfx.WithStrictUnmarshal())
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", cfg.Host)
+
+	// unknown key is reported
+	filename = writeConfig(t, dir, "unknown.yaml", `
+host: example.com
+port: 8080
+name: app
+hots: typo.example.com
+`)
+	_, err = newTestProvider[profileConfig](filename).Config()
+	require.NoError(t, err)
+	_, err = newTestProvider[profileConfig](filename).Config(configfx.WithStrictUnmarshal())
+	assert.ErrorContains(t, err, "hots")
+}
+
+func TestProviderOverlayReload(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeConfig(t, dir, "config.yaml", `
+webserver:
+  host: localhost
+  port: 8080
+`)
+	writeConfig(t, dir, "overlay.yaml", `
+server:
+  port: 9090
+`)
+
+	changes := make(chan fsnotify.Event, 16)
+	opts := []configfx.ConfigOption{
+		configfx.WithOverlays(&configfx.Overlay{
+			Filename: "overlay.yaml",
+			From:     "server",
+			To:       []string{"webserver"},
+		}),
+		configfx.WithOnConfigChange(func(in fsnotify.Event) {
+			changes <- in
+		}),
+	}
+
+	provider := newTestProvider[overlayConfig](filename)
+	cfg, err := provider.Config(opts...)
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.Webserver.Port)
+
+	// simulate an edit of the overlay file
+	writeConfig(t, dir, "overlay.yaml", `
+server:
+  port: 7070
+`)
+	select {
+	case in := <-changes:
+		assert.Equal(t, filepath.Join(dir, "overlay.yaml"), in.Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change callback was not invoked")
+	}
+
+	// reloading with the same opts picks up the change
+	cfg, err = provider.Config(opts...)
+	require.NoError(t, err)
+	assert.Equal(t, 7070, cfg.Webserver.Port)
+	assert.Equal(t, "localhost", cfg.Webserver.Host)
+}
+
+func TestProviderHotReload(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+host: example.com
+port: 8080
+`)
+
+	changes := make(chan fsnotify.Event, 16)
+	provider := newTestProvider[profileConfig](filename)
+	_, err := provider.Config(configfx.WithOnConfigChange(func(in fsnotify.Event) {
+		changes <- in
+	}))
+	require.NoError(t, err)
+
+	writeConfig(t, filepath.Dir(filename), "config.yaml", `
+host: example.com
+port: 9090
+`)
+	select {
+	case in := <-changes:
+		assert.Equal(t, filename, in.Name)
+		assert.True(t, in.Has(fsnotify.Write) || in.Has(fsnotify.Create), in.Op.String())
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change callback was not invoked")
+	}
+
+	// viper re-reads the file before invoking the callback
+	assert.Equal(t, 9090, provider.Viper().GetInt("port"))
+}
+
+func TestProviderOnConfigChangeChained(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", "port: 8080\n")
+
+	first, second := make(chan fsnotify.Event, 16), make(chan fsnotify.Event, 16)
+	reused := configfx.WithOnConfigChange(func(in fsnotify.Event) {
+		first <- in
+	})
+	provider := newTestProvider[profileConfig](filename)
+	_, err := provider.Config(reused)
+	require.NoError(t, err)
+	_, err = provider.Config(reused, configfx.WithOnConfigChange(func(in fsnotify.Event) {
+		second <- in
+	}))
+	require.NoError(t, err)
+
+	// later callbacks don't replace earlier ones, reused options are added once
+	writeConfig(t, filepath.Dir(filename), "config.yaml", "port: 9090\n")
+	for _, changes := range []chan fsnotify.Event{first, second} {
+		select {
+		case <-changes:
+		case <-time.After(5 * time.Second):
+			t.Fatal("config change callback was not invoked")
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, len(second), len(first), "reused callback was added twice")
+
+	// closing the provider stops watching
+	require.NoError(t, provider.(io.Closer).Close())
+	writeConfig(t, filepath.Dir(filename), "config.yaml", "port: 7070\n")
+	select {
+	case in := <-first:
+		t.Fatalf("config change callback invoked after close: %s", in)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+type defaulterConfig struct {
+	Port        int    `mapstructure:"port" default:"8080"`
+	MetricsPort int    `mapstructure:"metricsPort"`
+	Name        string `mapstructure:"name"`
+}
+
+// SetDefaults implements configfx.CustomDefaulter
+func (c *defaulterConfig) SetDefaults() error {
+	if c.MetricsPort == 0 {
+		c.MetricsPort = c.Port + 1
+	}
+	if len(c.Name) == 0 {
+		c.Name = "derived"
+	}
+	return nil
+}
+
+func TestProviderCustomDefaulter(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+port: 9000
+name: configured
+`)
+
+	// before unmarshal: derived from tag defaults, config overrides
+	cfg, err := newTestProvider[defaulterConfig](filename).Config()
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, 8081, cfg.MetricsPort)
+	assert.Equal(t, "configured", cfg.Name)
+
+	// after unmarshal: derived from configured values
+	cfg, err = newTestProvider[defaulterConfig](filename).Config(
+		configfx.WithDefaulterOrder(configfx.DefaultsAfterUnmarshal),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.Port)
+	assert.Equal(t, 9001, cfg.MetricsPort)
+	assert.Equal(t, "configured", cfg.Name)
+}
+
+type elementRoute struct {
+	Path    string `mapstructure:"path"`
+	Method  string `mapstructure:"method" default:"GET"`
+	Status  int    `mapstructure:"status" default:"200"`
+	Content any    `mapstructure:"content"`
+}
+
+type elementConfig struct {
+	Routes   []*elementRoute         `mapstructure:"routes" default:"[]"`
+	Backends map[string]elementRoute `mapstructure:"backends" default:"{}"`
+}
+
+func TestProviderElementDefaults(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "config.yaml", `
+routes:
+  - path: /full
+    method: POST
+    status: 201
+    content: created
+  - path: /minimal
+backends:
+  api:
+    path: /api
+`)
+
+	cfg, err := newTestProvider[elementConfig](filename).Config()
+	require.NoError(t, err)
+
+	require.Len(t, cfg.Routes, 2)
+	assert.Equal(t, &elementRoute{Path: "/full", Method: "POST", Status: 201, Content: "created"}, cfg.Routes[0])
+	assert.Equal(t, &elementRoute{Path: "/minimal", Method: "GET", Status: 200}, cfg.Routes[1])
+	assert.Equal(t, elementRoute{Path: "/api", Method: "GET", Status: 200}, cfg.Backends["api"])
+}
+
+func TestMustConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	filename := writeConfig(t, dir, "config.yaml", "host: example.com\n")
+	cfg := configfx.MustConfig(newTestProvider[profileConfig](filename))
+	assert.Equal(t, "example.com", cfg.Host)
+
+	filename = writeConfig(t, dir, "broken.yaml", "host: [\n")
+	assert.Panics(t, func() {
+		configfx.MustConfig(newTestProvider[profileConfig](filename))
+	})
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// DefaultSecretPrefix is the prefix of secret references
+// used by [WithSecretResolver] if none is given
+const DefaultSecretPrefix = "secret://"
+
+// SecretResolver resolves secret references found in config values.
+// Config values like `password: secret://vault/db/password` are
+// replaced by the result of Resolve using the reference without prefix,
+// `vault/db/password` in this example.
+type SecretResolver interface {
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// EnvSecretResolver is a [SecretResolver] returning the value of the
+// environment variable ref. Unset variables are reported as error.
+//
+//	configfx.WithSecretResolver(configfx.EnvSecretResolver{}, "env://")
+type EnvSecretResolver struct{}
+
+// ensure EnvSecretResolver implements SecretResolver
+var _ SecretResolver = EnvSecretResolver{}
+
+// Resolve implements SecretResolver
+func (EnvSecretResolver) Resolve(_ context.Context, ref string) (string, error) {
+	value, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", ref)
+	}
+
+	return value, nil
+}
+
+// secretResolver stores a SecretResolver along with its prefixes
+type secretResolver struct {
+	resolver SecretResolver
+	prefixes []string
+}
+
+// secretResolvers resolves secret references using the first matching prefix
+type secretResolvers []secretResolver
+
+// resolveString returns the resolved secret of s and true
+// if s starts with a prefix of any resolver.
+func (r secretResolvers) resolveString(ctx context.Context, s string) (string, bool, error) {
+	for _, sr := range r {
+		for _, prefix := range sr.prefixes {
+			ref, ok := strings.CutPrefix(s, prefix)
+			if !ok {
+				continue
+			}
+			value, err := sr.resolver.Resolve(ctx, ref)
+			return value, true, err
+		}
+	}
+
+	return s, false, nil
+}
+
+// resolve replaces all secret references of strings found in v.
+// Unexported struct fields are not touched.
+func (r secretResolvers) resolve(ctx context.Context, v reflect.Value, key string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return r.resolve(ctx, v.Elem(), key)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		// values inside interfaces are not addressable, resolve a copy
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := r.resolve(ctx, elem, key); err != nil {
+			return err
+		}
+		if v.CanSet() {
+			v.Set(elem)
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+			if name == "-" {
+				continue
+			}
+			fieldKey := key
+			if !strings.Contains(opts, "squash") {
+				if len(name) == 0 {
+					name = field.Name
+				}
+				fieldKey = joinKey(key, name)
+			}
+			if err := r.resolve(ctx, v.Field(i), fieldKey); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if err := r.resolve(ctx, v.Index(i), fmt.Sprintf("%s[%d]", key, i)); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// map elements are not addressable, resolve a copy
+			elem := reflect.New(v.Type().Elem()).Elem()
+			elem.Set(iter.Value())
+			if err := r.resolve(ctx, elem, joinKey(key, fmt.Sprint(iter.Key().Interface()))); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+
+	case reflect.String:
+		value, ok, err := r.resolveString(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("resolve secret of %q: %s", key, err)
+		}
+		if ok && v.CanSet() {
+			v.SetString(value)
+		}
+	}
+
+	return nil
+}
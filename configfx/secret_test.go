@@ -0,0 +1,92 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeResolver is a configfx.SecretResolver resolving refs of secrets
+type fakeResolver struct {
+	secrets map[string]string
+}
+
+// Resolve implements configfx.SecretResolver
+func (r *fakeResolver) Resolve(_ context.Context, ref string) (string, error) {
+	value, ok := r.secrets[ref]
+	if !ok {
+		return "", fmt.Errorf("unknown secret %s", ref)
+	}
+	return value, nil
+}
+
+type secretConfig struct {
+	Database struct {
+		User     string `mapstructure:"user"`
+		Password string `mapstructure:"password"`
+	} `mapstructure:"database"`
+
+	Tokens  []string          `mapstructure:"tokens"`
+	Headers map[string]string `mapstructure:"headers"`
+	Extra   any               `mapstructure:"extra"`
+}
+
+func TestProviderSecretResolver(t *testing.T) {
+	filename := writeConfig(t, t.TempDir(), "secret.yaml", `
+database:
+  user: admin
+  password: secret://db/password
+tokens:
+- secret://api/token
+- plain
+headers:
+  authorization: secret://api/token
+extra:
+  nested:
+    key: env://STDFX_TEST_SECRET
+`)
+	t.Setenv("STDFX_TEST_SECRET", "from-env")
+	resolver := &fakeResolver{secrets: map[string]string{
+		"db/password": "hunter2",
+		"api/token":   "token",
+	}}
+
+	cfg, err := newTestProvider[secretConfig](filename).Config(
+		configfx.WithSecretResolver(resolver),
+		configfx.WithSecretResolver(configfx.EnvSecretResolver{}, "env://"),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "admin", cfg.Database.User)
+	assert.Equal(t, "hunter2", cfg.Database.Password)
+	assert.Equal(t, []string{"token", "plain"}, cfg.Tokens)
+	assert.Equal(t, map[string]string{"authorization": "token"}, cfg.Headers)
+	assert.Equal(t, map[string]any{"nested": map[string]any{"key": "from-env"}}, cfg.Extra)
+
+	// unknown references fail naming the key
+	resolver.secrets = map[string]string{}
+	_, err = newTestProvider[secretConfig](filename).Config(
+		configfx.WithSecretResolver(resolver),
+	)
+	assert.ErrorContains(t, err, `resolve secret of "database.password": unknown secret db/password`)
+}
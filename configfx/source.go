@@ -17,11 +17,17 @@ limitations under the License.
 package configfx
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/choopm/stdfx/globals"
+	"github.com/spf13/afero"
 	"github.com/spf13/viper"
 )
 
@@ -32,6 +38,22 @@ type Source[T any] interface {
 	Viper(opts ...viper.Option) *viper.Viper
 }
 
+// SizeLimitedSource denotes sources limiting the size of config files.
+// The limit is applied to files read besides viper like profiles,
+// sources not implementing it are limited to [DefaultMaxSize].
+type SizeLimitedSource interface {
+	// MaxSize shall return the maximum size of config files in bytes
+	MaxSize() int64
+}
+
+// EnvRestrictedSource denotes sources restricting environment overrides
+// to some config keys, see [WithEnvAllowlist].
+type EnvRestrictedSource interface {
+	// EnvAllowlist shall return the config keys which might be overridden
+	// by environment variables or nil if any key might be overridden
+	EnvAllowlist() []string
+}
+
 // SourceFile is a config source using files
 type SourceFile[T any] struct {
 	Source[T]
@@ -43,6 +65,19 @@ type SourceFile[T any] struct {
 	configName string
 	// searchPaths are additional paths to use when looking for configName
 	searchPaths []string
+	// envAllowlist restricts environment overrides to these keys if set
+	envAllowlist []string
+	// maxSize limits the size of config files in bytes
+	maxSize int64
+	// configType is the format of explicit config files if set
+	configType string
+
+	// stdin is read if the config file is "-", defaults to os.Stdin
+	stdin io.Reader
+	// stdinOnce guards reading stdin into stdinData once
+	stdinOnce sync.Once
+	stdinData []byte
+	stdinErr  error
 
 	// flagEnvPrefix for use as a flag with viper autoenv
 	flagEnvPrefix *string
@@ -50,50 +85,131 @@ type SourceFile[T any] struct {
 	flagConfigPath *string
 	// flagConfigPath for use as a flag to provide an absolute config path
 	flagAbsolutePath *string
+	// flagProfile for use as a flag to select a config profile
+	flagProfile *string
+}
+
+// ensure SourceFile[T] implements ProfiledSource, EnvRestrictedSource
+// and flaggedSource
+var (
+	_ ProfiledSource      = &SourceFile[any]{}
+	_ EnvRestrictedSource = &SourceFile[any]{}
+	_ flaggedSource       = &SourceFile[any]{}
+)
+
+// sourceFlags are the root flags adjusting config loading.
+// They are defined once per provider and shared by all of its sources.
+// Flags not applying to a source are nil.
+type sourceFlags struct {
+	envPrefix  *string
+	configPath *string
+	configFile *string
+	profile    *string
+}
+
+// flaggedSource denotes sources adjusted by root flags.
+// [NewProvider] defines the flags of its source, nested sources
+// like the ones of [NewSourceMulti] receive them from their parent.
+type flaggedSource interface {
+	// defaultEnvPrefix shall return the default of --env-prefix
+	defaultEnvPrefix() string
+	// fileFlags shall return true if the source reads
+	// --config-path, --config-file and --profile
+	fileFlags() bool
+	// setFlags shall adjust config loading using flags
+	setFlags(flags *sourceFlags)
+}
+
+// defineFlags defines the root flags of source if it is a flaggedSource
+// and passes them to it
+func defineFlags(source any) {
+	flagged, ok := source.(flaggedSource)
+	if !ok {
+		return
+	}
+
+	flags := &sourceFlags{
+		envPrefix: globals.RootString(
+			"env-prefix", "e", flagged.defaultEnvPrefix(),
+			"Environment prefix to use when overriding config via AutomaticEnv"),
+	}
+	if flagged.fileFlags() {
+		flags.configPath = globals.RootString(
+			"config-path", "c", globals.RootFlagConfigPathDefault,
+			"Config search directory. "+
+				"Expected to contain the config file "+
+				"with any supported extension: "+
+				strings.Join(viper.SupportedExts, "|"))
+		flags.configFile = globals.RootString(
+			"config-file", "f", "",
+			"Absolute path to config file to use, - reads stdin. "+
+				"Takes precedence over -c, --config-path")
+		flags.profile = globals.RootString(
+			"profile", "", "",
+			"Config profile to merge onto the config file, "+
+				"example: --profile prod reads '<config>.prod.<ext>'")
+	}
+	flagged.setFlags(flags)
 }
 
 // NewSourceFile returns a Source constructor based on a config file.
 // configName specifies the file to search for in default paths.
 // A developer can optionally override searchPaths.
-// userFlags can be used to allow adjustment of config loading by
-// users using cobra.Command.PersistentFlags for example.
+// Users can adjust config loading using the root flags --env-prefix,
+// --config-path, --config-file and --profile defined by [NewProvider].
 func NewSourceFile[T any](
 	configName string,
 	searchPaths ...string,
 ) func(*slog.Logger) Source[T] {
+	return NewSourceFileWithOptions[T](configName,
+		WithSearchPaths(searchPaths...),
+	)
+}
+
+// NewSourceFileWithOptions returns a Source constructor based on a config file.
+// It behaves like [NewSourceFile] but allows tuning the source using opts.
+func NewSourceFileWithOptions[T any](
+	configName string,
+	opts ...SourceFileOption,
+) func(*slog.Logger) Source[T] {
+	// apply any given opts
+	sOpts := defaultSourceFileOptions()
+	for _, option := range opts {
+		option(sOpts)
+	}
+
 	return func(log *slog.Logger) Source[T] {
 		// get default env prefix from configName
 		defEnvPrefix := DefaultEnvironmentPrefix(configName)
+		configPath := globals.RootFlagConfigPathDefault
 
 		// use default searchPaths if nothing was provided by library user
+		searchPaths := sOpts.searchPaths
 		if len(searchPaths) == 0 {
 			searchPaths = DefaultFileSearchPaths(configName)
 		}
 
+		// drop the working directory if requested
+		if !sOpts.workingDirSearch {
+			searchPaths = slices.DeleteFunc(slices.Clone(searchPaths), isWorkingDir)
+		}
+
 		return &SourceFile[T]{
 			// general
 			log: log.With(slog.String("context", "config-file")),
 
 			// config file specific
-			configName:  configName,
-			searchPaths: searchPaths,
-
-			// globalFlags for adjustment of config loading
-			flagEnvPrefix: globals.RootFlags.StringP(
-				"env-prefix", "e", defEnvPrefix,
-				"Environment prefix to use when overriding config via AutomaticEnv"),
-			flagConfigPath: globals.RootFlags.StringP(
-				"config-path", "c", globals.RootFlagConfigPathDefault,
-				"Config search directory. "+
-					"Expected to contain a '"+configName+"' config file "+
-					"with any supported extension,\nexamples: "+
-					configName+".<"+
-					strings.Join(viper.SupportedExts, "|")+
-					">"),
-			flagAbsolutePath: globals.RootFlags.StringP(
-				"config-file", "f", "",
-				"Absolute path to config file to use. "+
-					"Takes precedence over -c, --config-path"),
+			configName:   configName,
+			searchPaths:  searchPaths,
+			envAllowlist: sOpts.envAllowlist,
+			maxSize:      sOpts.maxSize,
+			configType:   sOpts.configType,
+
+			// local defaults until defineFlags passes the root flags
+			flagEnvPrefix:    &defEnvPrefix,
+			flagConfigPath:   &configPath,
+			flagAbsolutePath: new(string),
+			flagProfile:      new(string),
 		}
 	}
 }
@@ -102,11 +218,36 @@ func NewSourceFile[T any](
 // It returns a fresh *Viper with opts to read from using a [Provider[T]].
 func (s *SourceFile[T]) Viper(
 	opts ...viper.Option,
+) *viper.Viper {
+	v := s.fileViper(opts...)
+
+	// environment overrides
+	s.log.Debug("enabling config env replacer",
+		"env-prefix", s.flagEnvPrefix,
+	)
+	v.SetEnvPrefix(*s.flagEnvPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	if len(s.envAllowlist) > 0 {
+		s.log.Debug("restricting config env to allowlist",
+			"keys", s.envAllowlist,
+		)
+	}
+	bindEnv(v, s.envAllowlist)
+
+	return v
+}
+
+// fileViper implements fileSource.
+// It returns a fresh *Viper with opts reading the config file without env.
+func (s *SourceFile[T]) fileViper(
+	opts ...viper.Option,
 ) *viper.Viper {
 	// Construct viper using passed default options
 	v := viper.NewWithOptions(
 		opts...,
 	)
+	fs := newNormalizedFs(s.maxSize)
+	v.SetFs(fs)
 
 	// strip extension if given and not using absConfigFile
 	ext := filepath.Ext(s.configName)
@@ -118,23 +259,30 @@ func (s *SourceFile[T]) Viper(
 		s.configName = s.configName[:len(s.configName)-len(ext)]
 	}
 
-	// environment overrides
-	s.log.Debug("enabling config env replacer",
-		"env-prefix", s.flagEnvPrefix,
-	)
-	v.AutomaticEnv()
-	v.SetEnvPrefix(*s.flagEnvPrefix)
-	v.SetEnvKeyReplacer(strings.NewReplacer(
-		".", "_",
-		"-", "_",
-	))
-
 	if len(*s.flagAbsolutePath) > 0 {
 		// use this file explicitly
 		s.log.Debug("using explicit config file",
 			"filepath", *s.flagAbsolutePath)
 
-		v.SetConfigFile(*s.flagAbsolutePath)
+		filename := *s.flagAbsolutePath
+		if filename == "-" {
+			// serve the config read from stdin as file
+			filename = StdinFilename
+			fs = s.stdinFs()
+			v.SetFs(fs)
+		}
+
+		v.SetConfigFile(filename)
+		configType := s.explicitConfigType(fs, filename)
+		if len(configType) == 0 && filename == StdinFilename {
+			// let viper read stdin to report errors instead of the type
+			configType = "yaml"
+		}
+		if len(configType) > 0 {
+			s.log.Debug("using config type of explicit config file",
+				"config-type", configType)
+			v.SetConfigType(configType)
+		}
 
 	} else {
 		s.log.Debug("using auto-search of config file",
@@ -154,7 +302,219 @@ func (s *SourceFile[T]) Viper(
 		for _, path := range s.searchPaths {
 			v.AddConfigPath(path)
 		}
+
+		// warn if the working directory would win the search
+		if len(*s.flagConfigPath) == 0 && slices.ContainsFunc(s.searchPaths, isWorkingDir) {
+			s.warnWorkingDirConfig()
+		}
 	}
 
 	return v
 }
+
+// Profile implements ProfiledSource.
+// It returns the profile selected using the --profile flag.
+func (s *SourceFile[T]) Profile() string {
+	return *s.flagProfile
+}
+
+// defaultEnvPrefix implements flaggedSource
+func (s *SourceFile[T]) defaultEnvPrefix() string {
+	return DefaultEnvironmentPrefix(s.configName)
+}
+
+// fileFlags implements flaggedSource
+func (s *SourceFile[T]) fileFlags() bool {
+	return true
+}
+
+// setFlags implements flaggedSource
+func (s *SourceFile[T]) setFlags(flags *sourceFlags) {
+	s.flagEnvPrefix = flags.envPrefix
+	s.flagConfigPath = flags.configPath
+	s.flagAbsolutePath = flags.configFile
+	s.flagProfile = flags.profile
+}
+
+// MaxSize implements SizeLimitedSource.
+// It returns the maximum size of config files set using [WithMaxSize].
+func (s *SourceFile[T]) MaxSize() int64 {
+	return s.maxSize
+}
+
+// EnvAllowlist implements EnvRestrictedSource.
+// It returns the config keys set using [WithEnvAllowlist].
+func (s *SourceFile[T]) EnvAllowlist() []string {
+	return s.envAllowlist
+}
+
+// explicitConfigType returns the format to parse the explicit config file
+// filename of fs with. It is the type set using [WithConfigType] or the type
+// detected from the content of filename if its extension is unknown
+// to viper. An empty string leaves the detection to viper.
+func (s *SourceFile[T]) explicitConfigType(fs afero.Fs, filename string) string {
+	if len(s.configType) > 0 {
+		return s.configType
+	}
+	if slices.Contains(viper.SupportedExts, fileType(filename)) {
+		return ""
+	}
+
+	// errors are reported by viper reading the file
+	f, err := fs.Open(filename)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(f, head)
+
+	return sniffConfigType(normalizeConfig(head[:n]))
+}
+
+// configFiles implements fileLister.
+// It returns the config file read into v, from stdin if selected.
+func (s *SourceFile[T]) configFiles(v *viper.Viper) ([]ConfigFile, error) {
+	filename := v.ConfigFileUsed()
+	fs := newNormalizedFs(s.maxSize)
+	if filename == StdinFilename {
+		fs = s.stdinFs()
+	}
+	content, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	configType := fileType(filename)
+	if len(*s.flagAbsolutePath) > 0 {
+		if explicit := s.explicitConfigType(fs, filename); len(explicit) > 0 {
+			configType = explicit
+		} else if filename == StdinFilename {
+			configType = "yaml"
+		}
+	}
+
+	return []ConfigFile{{Name: filename, Type: configType, Content: content}}, nil
+}
+
+// fileType returns the lowercase extension of filename without dot
+func fileType(filename string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// StdinFilename is the config file reported by viper.ConfigFileUsed
+// for configs read from stdin using --config-file -
+const StdinFilename = "<stdin>"
+
+// stdinFs returns a filesystem serving the config read from stdin
+// as [StdinFilename]. Stdin is read once and served to every viper
+// instance, the config type is detected from its content unless
+// set using [WithConfigType].
+func (s *SourceFile[T]) stdinFs() afero.Fs {
+	s.stdinOnce.Do(func() {
+		stdin := s.stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		s.stdinData, s.stdinErr = readConfig(stdin, StdinFilename, s.maxSize)
+	})
+
+	fs := afero.NewMemMapFs()
+	if s.stdinErr != nil {
+		// report the error when reading the config
+		return &failingFs{Fs: fs, err: fmt.Errorf("reading config from stdin: %w", s.stdinErr)}
+	}
+	_ = afero.WriteFile(fs, StdinFilename, s.stdinData, 0644)
+
+	return &normalizedFs{Fs: fs, maxSize: s.maxSize}
+}
+
+// failingFs is an afero.Fs failing to open any file with err
+type failingFs struct {
+	afero.Fs
+	err error
+}
+
+// Open implements afero.Fs
+func (fs *failingFs) Open(string) (afero.File, error) {
+	return nil, fs.err
+}
+
+// OpenFile implements afero.Fs
+func (fs *failingFs) OpenFile(string, int, os.FileMode) (afero.File, error) {
+	return nil, fs.err
+}
+
+// Stat implements afero.Fs
+func (fs *failingFs) Stat(string) (os.FileInfo, error) {
+	return nil, fs.err
+}
+
+// sniffConfigType returns the format of the config data judging by its
+// first significant line or an empty string if unknown.
+// JSON objects, TOML tables and assignments and YAML mappings are detected.
+func sniffConfigType(data []byte) string {
+	for line := range strings.Lines(string(data)) {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "{"):
+			return "json"
+		case strings.HasPrefix(line, "["):
+			return "toml"
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "- "):
+			return "yaml"
+		}
+
+		// the first separator decides between `key = value` and `key: value`
+		equal, colon := strings.Index(line, "="), strings.Index(line, ":")
+		switch {
+		case equal >= 0 && (colon < 0 || equal < colon):
+			return "toml"
+		case colon >= 0:
+			return "yaml"
+		}
+		return ""
+	}
+
+	return ""
+}
+
+// warnWorkingDirConfig logs a warning if a config file matching configName
+// exists in the working directory. Such a file takes precedence over any
+// config directory and might belong to an unrelated project.
+func (s *SourceFile[T]) warnWorkingDirConfig() {
+	for _, ext := range viper.SupportedExts {
+		filename := s.configName + "." + ext
+		if _, err := os.Stat(filename); err != nil {
+			continue
+		}
+
+		s.log.Warn("using config file from working directory, "+
+			"use --config-path or --config-file to be explicit",
+			"filename", filename)
+		return
+	}
+}
+
+// isWorkingDir returns true if path points to the working directory
+func isWorkingDir(path string) bool {
+	return filepath.Clean(path) == "."
+}
+
+// bindEnv enables environment overrides of v. Any key is considered
+// using AutomaticEnv unless allowlist is given, which binds only the
+// keys of allowlist explicitly.
+func bindEnv(v *viper.Viper, allowlist []string) {
+	if len(allowlist) == 0 {
+		v.AutomaticEnv()
+		return
+	}
+
+	for _, key := range allowlist {
+		_ = v.BindEnv(key)
+	}
+}
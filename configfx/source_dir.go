@@ -0,0 +1,256 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// FragmentedSource denotes sources consisting of multiple config files
+// which are read and merged by the source itself instead of using
+// viper.ReadInConfig.
+type FragmentedSource interface {
+	// ReadFragments shall read all fragments into v, later ones
+	// overriding earlier ones.
+	ReadFragments(v *viper.Viper) error
+
+	// WatchFragments shall invoke onChange whenever fragments
+	// are added, removed or written.
+	WatchFragments(onChange func(in fsnotify.Event)) error
+}
+
+// SourceDir is a config source merging all config files of a directory
+type SourceDir[T any] struct {
+	// log defines the Logger instance to use
+	log *slog.Logger
+
+	// dir is the directory containing config fragments
+	dir string
+
+	// maxSize is the maximum size of config fragments in bytes
+	maxSize int64
+
+	// watchers are the watchers started by WatchFragments
+	watchers      []*fsnotify.Watcher
+	watchersMutex sync.Mutex
+}
+
+// ensure SourceDir[T] implements Source[T], FragmentedSource,
+// SizeLimitedSource and io.Closer
+var (
+	_ Source[any]       = &SourceDir[any]{}
+	_ FragmentedSource  = &SourceDir[any]{}
+	_ SizeLimitedSource = &SourceDir[any]{}
+	_ io.Closer         = &SourceDir[any]{}
+)
+
+// NewSourceDir returns a Source constructor reading all config files
+// inside dir like a conf.d directory.
+// Files are merged in lexical order of their names, later ones win.
+// Files without a supported extension, hidden files and
+// subdirectories are skipped. Of opts only [WithMaxSize] is honored.
+// Watchers of the directory are stopped using [CloseOnStop].
+// Usage example:
+//
+//	fx.Provide(func(log *slog.Logger) configfx.Provider[Config] {
+//		source := configfx.NewSourceDir[Config]("/etc/myapp/conf.d")(log)
+//		return configfx.NewProvider(source, log)
+//	}),
+//	fx.Invoke(configfx.CloseOnStop[Config]),
+func NewSourceDir[T any](dir string, opts ...SourceFileOption) func(*slog.Logger) Source[T] {
+	sOpts := defaultSourceFileOptions()
+	for _, option := range opts {
+		option(sOpts)
+	}
+
+	return func(log *slog.Logger) Source[T] {
+		return &SourceDir[T]{
+			log:     log.With(slog.String("context", "config-dir")),
+			dir:     dir,
+			maxSize: sOpts.maxSize,
+		}
+	}
+}
+
+// Viper implements Source[T]
+// It returns a fresh *Viper with opts to read from using a [Provider[T]].
+func (s *SourceDir[T]) Viper(
+	opts ...viper.Option,
+) *viper.Viper {
+	v := viper.NewWithOptions(opts...)
+	v.SetFs(newNormalizedFs(s.maxSize))
+
+	// point viper at the first fragment, so plain reads work as well
+	if fragments, err := s.fragments(); err == nil && len(fragments) > 0 {
+		v.SetConfigFile(fragments[0])
+	}
+
+	return v
+}
+
+// MaxSize implements SizeLimitedSource.
+// It returns the maximum size of config files set using [WithMaxSize].
+func (s *SourceDir[T]) MaxSize() int64 {
+	return s.maxSize
+}
+
+// ReadFragments implements FragmentedSource
+func (s *SourceDir[T]) ReadFragments(v *viper.Viper) error {
+	fragments, err := s.fragments()
+	if err != nil {
+		return err
+	}
+	if len(fragments) == 0 {
+		return fmt.Errorf("no config files found in %q", s.dir)
+	}
+
+	for i, fragment := range fragments {
+		s.log.Debug("reading config fragment",
+			slog.String("file", fragment))
+
+		v.SetConfigFile(fragment)
+		if i == 0 {
+			err = v.ReadInConfig()
+		} else {
+			err = v.MergeInConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("reading %q: %s", fragment, err)
+		}
+	}
+
+	return nil
+}
+
+// configFiles implements fileLister.
+// It returns all fragments in the order they are merged.
+func (s *SourceDir[T]) configFiles(*viper.Viper) ([]ConfigFile, error) {
+	fragments, err := s.fragments()
+	if err != nil {
+		return nil, err
+	}
+
+	fs := newNormalizedFs(s.maxSize)
+	files := []ConfigFile{}
+	for _, fragment := range fragments {
+		content, err := afero.ReadFile(fs, fragment)
+		if err != nil {
+			return nil, fmt.Errorf("reading %q: %s", fragment, err)
+		}
+		files = append(files, ConfigFile{
+			Name:    fragment,
+			Type:    fileType(fragment),
+			Content: content,
+		})
+	}
+
+	return files, nil
+}
+
+// WatchFragments implements FragmentedSource
+func (s *SourceDir[T]) WatchFragments(onChange func(in fsnotify.Event)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watching %q: %s", s.dir, err)
+	}
+	if err := watcher.Add(s.dir); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("watching %q: %s", s.dir, err)
+	}
+
+	// the watcher lives until Close is called
+	s.watchersMutex.Lock()
+	s.watchers = append(s.watchers, watcher)
+	s.watchersMutex.Unlock()
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if isFragment(filepath.Base(event.Name)) {
+					onChange(event)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.log.Error("watching config dir failed",
+					slog.String("dir", s.dir),
+					slog.Any("error", err))
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close implements io.Closer.
+// It stops all watchers started by WatchFragments.
+func (s *SourceDir[T]) Close() error {
+	s.watchersMutex.Lock()
+	defer s.watchersMutex.Unlock()
+
+	errs := []error{}
+	for _, watcher := range s.watchers {
+		errs = append(errs, watcher.Close())
+	}
+	s.watchers = nil
+
+	return errors.Join(errs...)
+}
+
+// fragments returns the sorted paths of config files inside s.dir
+func (s *SourceDir[T]) fragments() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading config dir: %s", err)
+	}
+
+	fragments := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !isFragment(entry.Name()) {
+			continue
+		}
+		fragments = append(fragments, filepath.Join(s.dir, entry.Name()))
+	}
+	slices.Sort(fragments)
+
+	return fragments, nil
+}
+
+// isFragment returns true if name is a visible file with a supported extension
+func isFragment(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return slices.Contains(viper.SupportedExts, fileType(name))
+}
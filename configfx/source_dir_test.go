@@ -0,0 +1,129 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+// newDirProvider returns a configfx.Provider[T] reading the fragments in dir
+func newDirProvider[T any](dir string, opts ...configfx.SourceFileOption) configfx.Provider[T] {
+	log := slog.New(slog.DiscardHandler)
+	return configfx.NewProvider[T](configfx.NewSourceDir[T](dir, opts...)(log), log)
+}
+
+func TestSourceDir(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "10-base.yaml", `
+host: base.example.com
+port: 8080
+`)
+	writeConfig(t, dir, "20-override.json", `{"port": 9090}`)
+	// skipped files
+	writeConfig(t, dir, "README.md", "# not a config")
+	writeConfig(t, dir, ".99-hidden.yaml", "port: 1")
+
+	cfg, err := newDirProvider[profileConfig](dir).Config()
+	require.NoError(t, err)
+
+	assert.Equal(t, "base.example.com", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)  // later fragment wins
+	assert.Equal(t, "app", cfg.Name) // defaults
+
+	// empty dirs are reported
+	_, err = newDirProvider[profileConfig](t.TempDir()).Config()
+	assert.ErrorContains(t, err, "no config files found")
+}
+
+func TestSourceDirWatch(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "10-base.yaml", `
+port: 8080
+`)
+
+	changes := make(chan fsnotify.Event, 16)
+	provider := newDirProvider[profileConfig](dir)
+	_, err := provider.Config(configfx.WithOnConfigChange(func(in fsnotify.Event) {
+		changes <- in
+	}))
+	require.NoError(t, err)
+
+	// adding a fragment is noticed
+	writeConfig(t, dir, "20-added.yaml", `
+port: 9090
+`)
+	select {
+	case in := <-changes:
+		assert.Equal(t, filepath.Join(dir, "20-added.yaml"), in.Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config change callback was not invoked")
+	}
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.Port)
+}
+
+func TestSourceDirMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "10-base.yaml", "host: base.example.com\n")
+	overlay := writeConfig(t, t.TempDir(), "overlay.yaml", "# exceeding 32 bytes\noverlay:\n  port: 9090\n")
+
+	_, err := newDirProvider[profileConfig](dir, configfx.WithMaxSize(8)).Config()
+	assert.ErrorContains(t, err, "config file too large")
+
+	// overlays are limited as well
+	_, err = newDirProvider[profileConfig](dir, configfx.WithMaxSize(32)).Config(
+		configfx.WithOverlays(&configfx.Overlay{Filename: overlay, From: "overlay.port", To: []string{"port"}}),
+	)
+	assert.ErrorContains(t, err, "config file too large")
+}
+
+func TestSourceDirCloseOnStop(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "10-base.yaml", "port: 8080\n")
+
+	changes := make(chan fsnotify.Event, 16)
+	provider := newDirProvider[profileConfig](dir)
+	_, err := provider.Config(configfx.WithOnConfigChange(func(in fsnotify.Event) {
+		changes <- in
+	}))
+	require.NoError(t, err)
+
+	lc := fxtest.NewLifecycle(t)
+	configfx.CloseOnStop(lc, provider)
+	require.NoError(t, lc.Start(context.Background()))
+	require.NoError(t, lc.Stop(context.Background()))
+
+	// stopped watchers don't notice changes
+	writeConfig(t, dir, "20-added.yaml", "port: 9090\n")
+	select {
+	case in := <-changes:
+		t.Fatalf("config change callback invoked after stop: %s", in)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
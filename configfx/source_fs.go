@@ -0,0 +1,155 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+)
+
+// SourceFS is a config source reading a config file of a fs.FS
+// like an embed.FS holding baked-in defaults
+type SourceFS[T any] struct {
+	// log defines the Logger instance to use
+	log *slog.Logger
+
+	// fsys is the filesystem containing name
+	fsys fs.FS
+	// name is the path of the config file inside fsys
+	name string
+
+	// flagEnvPrefix for use as a flag with viper autoenv
+	flagEnvPrefix *string
+}
+
+// ensure SourceFS[T] implements Source[T], FragmentedSource
+// and flaggedSource
+var (
+	_ Source[any]      = &SourceFS[any]{}
+	_ FragmentedSource = &SourceFS[any]{}
+	_ flaggedSource    = &SourceFS[any]{}
+)
+
+// NewSourceFS returns a Source constructor reading the config file name
+// of fsys, e.g. a config embedded using go:embed.
+// The format is derived from the extension of name.
+// Environment variables override values like they do for [NewSourceFile].
+// Changes are never reported as files of fsys can't be watched.
+// Combine it with [NewSourceMulti] to use it as the lowest-priority source
+// beneath an external config file.
+// Usage example:
+//
+//	//go:embed config.yaml
+//	var embedded embed.FS
+//
+//	fx.Provide(configfx.NewSourceFS[Config](embedded, "config.yaml")),
+func NewSourceFS[T any](fsys fs.FS, name string) func(*slog.Logger) Source[T] {
+	return func(log *slog.Logger) Source[T] {
+		s := &SourceFS[T]{
+			log:  log.With(slog.String("context", "config-fs")),
+			fsys: fsys,
+			name: name,
+		}
+
+		// local default until defineFlags passes the root flag
+		envPrefix := s.defaultEnvPrefix()
+		s.flagEnvPrefix = &envPrefix
+
+		return s
+	}
+}
+
+// defaultEnvPrefix implements flaggedSource.
+// It derives the prefix from name without directory and extension.
+func (s *SourceFS[T]) defaultEnvPrefix() string {
+	return DefaultEnvironmentPrefix(
+		strings.TrimSuffix(path.Base(s.name), path.Ext(s.name)))
+}
+
+// fileFlags implements flaggedSource
+func (s *SourceFS[T]) fileFlags() bool {
+	return false
+}
+
+// setFlags implements flaggedSource
+func (s *SourceFS[T]) setFlags(flags *sourceFlags) {
+	s.flagEnvPrefix = flags.envPrefix
+}
+
+// Viper implements Source[T]
+// It returns a fresh *Viper with opts to read from using a [Provider[T]].
+func (s *SourceFS[T]) Viper(
+	opts ...viper.Option,
+) *viper.Viper {
+	v := s.fileViper(opts...)
+
+	// environment overrides
+	v.SetEnvPrefix(*s.flagEnvPrefix)
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	bindEnv(v, nil)
+
+	return v
+}
+
+// fileViper implements fileSource.
+// It returns a fresh *Viper with opts reading the config file without env.
+func (s *SourceFS[T]) fileViper(
+	opts ...viper.Option,
+) *viper.Viper {
+	v := viper.NewWithOptions(opts...)
+	v.SetFs(s.normalizedFs())
+
+	s.log.Debug("using config file of filesystem",
+		"filepath", s.name)
+	v.SetConfigFile(s.name)
+
+	return v
+}
+
+// configFiles implements fileLister.
+// It returns the config file of fsys.
+func (s *SourceFS[T]) configFiles(*viper.Viper) ([]ConfigFile, error) {
+	content, err := afero.ReadFile(s.normalizedFs(), s.name)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ConfigFile{{Name: s.name, Type: fileType(s.name), Content: content}}, nil
+}
+
+// normalizedFs returns fsys serving normalized config files
+func (s *SourceFS[T]) normalizedFs() afero.Fs {
+	return &normalizedFs{Fs: afero.FromIOFS{FS: s.fsys}, maxSize: DefaultMaxSize}
+}
+
+// ReadFragments implements FragmentedSource.
+// It reads the config file like viper.ReadInConfig does.
+func (s *SourceFS[T]) ReadFragments(v *viper.Viper) error {
+	return v.ReadInConfig()
+}
+
+// WatchFragments implements FragmentedSource.
+// It does nothing as files of fs.FS can't be watched.
+func (s *SourceFS[T]) WatchFragments(func(in fsnotify.Event)) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/globals"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults/config.yaml": &fstest.MapFile{Data: []byte(`
+host: embedded.example.com
+port: 8080
+`)},
+	}
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[profileConfig](
+		configfx.NewSourceFS[profileConfig](fsys, "defaults/config.yaml")(log),
+		log,
+	)
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "embedded.example.com", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, "app", cfg.Name) // default
+
+	// missing files are reported
+	provider = configfx.NewProvider[profileConfig](
+		configfx.NewSourceFS[profileConfig](fsys, "missing.yaml")(log),
+		log,
+	)
+	_, err = provider.Config()
+	assert.ErrorContains(t, err, "missing.yaml")
+}
+
+func TestSourceFSEnv(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.yaml": &fstest.MapFile{Data: []byte("port: 8080\n")},
+	}
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[profileConfig](
+		configfx.NewSourceFS[profileConfig](fsys, "config.yaml")(log),
+		log,
+	)
+	prefix := globals.RootFlags.Lookup("env-prefix").Value.String()
+	require.NoError(t, globals.RootFlags.Set("env-prefix", "FSTEST"))
+	t.Cleanup(func() { _ = globals.RootFlags.Set("env-prefix", prefix) })
+	t.Setenv("FSTEST_PORT", "9090")
+
+	// watching is a no-op as fsys can't be watched
+	cfg, err := provider.Config(
+		configfx.WithOnConfigChange(func(fsnotify.Event) {}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 9090, cfg.Port)
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindEnvAllowlist(t *testing.T) {
+	t.Setenv("APP_WEBSERVER_PORT", "9090")
+	t.Setenv("APP_WEBSERVER_HOST", "ambient.example.com")
+
+	newViper := func(allowlist ...string) *viper.Viper {
+		v := viper.New()
+		v.SetEnvPrefix("APP")
+		v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+		_ = v.MergeConfigMap(map[string]any{
+			"webserver": map[string]any{"host": "localhost"},
+		})
+		v.SetDefault("webserver.port", 8080)
+		bindEnv(v, allowlist)
+		return v
+	}
+
+	// any key is considered without allowlist
+	v := newViper()
+	assert.Equal(t, 9090, v.GetInt("webserver.port"))
+	assert.Equal(t, "ambient.example.com", v.GetString("webserver.host"))
+
+	// only allowed keys are overridden
+	v = newViper("webserver.port")
+	assert.Equal(t, 9090, v.GetInt("webserver.port"))
+	assert.Equal(t, "localhost", v.GetString("webserver.host"))
+	assert.Contains(t, v.AllKeys(), "webserver.port")
+}
+
+func TestMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("host: localhost\n"), 0644))
+	require.NoError(t, os.WriteFile(ProfileFilename(filename, "prod"),
+		[]byte("host: "+strings.Repeat("x", 64)+"\n"), 0644))
+
+	v := viper.New()
+	v.SetFs(newNormalizedFs(32))
+	v.SetConfigFile(filename)
+	require.NoError(t, v.ReadInConfig())
+	assert.Equal(t, "localhost", v.GetString("host"))
+
+	// profiles are limited as well
+	assert.ErrorIs(t, mergeProfile(v, "prod", 32), ErrConfigTooLarge)
+	require.NoError(t, mergeProfile(v, "prod", 0))
+
+	v = viper.New()
+	v.SetFs(newNormalizedFs(8))
+	v.SetConfigFile(filename)
+	assert.ErrorIs(t, v.ReadInConfig(), ErrConfigTooLarge)
+}
+
+func TestSniffConfigType(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{data: "host: localhost\nport: 8080\n", want: "yaml"},
+		{data: "# comment\n\n---\nhost: localhost\n", want: "yaml"},
+		{data: "url: \"http://localhost\"\n", want: "yaml"},
+		{data: "  {\"host\": \"localhost\"}", want: "json"},
+		{data: "[webserver]\nhost = \"localhost\"\n", want: "toml"},
+		{data: "url = \"http://localhost\"\n", want: "toml"},
+		{data: "just some text\n", want: ""},
+		{data: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sniffConfigType([]byte(tt.data)), tt.data)
+	}
+}
+
+func TestSourceFileConfigType(t *testing.T) {
+	type config struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	}
+
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(yamlFile, []byte("host: localhost\nport: 8080\n"), 0644))
+	tomlFile := filepath.Join(dir, "config.conf")
+	require.NoError(t, os.WriteFile(tomlFile, []byte("host = \"localhost\"\nport = 8080\n"), 0644))
+
+	// load returns the config of the explicit file filename using configType
+	load := func(filename, configType string) (*config, error) {
+		empty := ""
+		source := &SourceFile[config]{
+			log:              slog.New(slog.DiscardHandler),
+			configType:       configType,
+			flagEnvPrefix:    &empty,
+			flagConfigPath:   &empty,
+			flagAbsolutePath: &filename,
+			flagProfile:      &empty,
+		}
+		return newProvider[config](source, slog.New(slog.DiscardHandler)).Config()
+	}
+
+	for _, tt := range []struct {
+		name       string
+		filename   string
+		configType string
+	}{
+		{name: "sniffed yaml", filename: yamlFile},
+		{name: "explicit yaml", filename: yamlFile, configType: "yaml"},
+		{name: "sniffed toml", filename: tomlFile},
+		{name: "explicit toml", filename: tomlFile, configType: "toml"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(tt.filename, tt.configType)
+			require.NoError(t, err)
+			assert.Equal(t, "localhost", cfg.Host)
+			assert.Equal(t, 8080, cfg.Port)
+		})
+	}
+
+	// the option takes precedence over the content
+	_, err := load(yamlFile, "json")
+	assert.Error(t, err)
+}
+
+func TestSourceFileStdin(t *testing.T) {
+	type config struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	}
+
+	// pipe a config like `generate-config | myapp --config-file - server`
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write([]byte("host: localhost\nport: 8080\n"))
+		_ = pw.Close()
+	}()
+
+	empty, stdin := "", "-"
+	source := &SourceFile[config]{
+		log:              slog.New(slog.DiscardHandler),
+		stdin:            pr,
+		flagEnvPrefix:    &empty,
+		flagConfigPath:   &empty,
+		flagAbsolutePath: &stdin,
+		flagProfile:      &empty,
+	}
+	provider := newProvider[config](source, slog.New(slog.DiscardHandler))
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+	assert.Equal(t, StdinFilename, provider.Viper().ConfigFileUsed())
+
+	// stdin is read once and served again on reloads
+	cfg, err = provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, 8080, cfg.Port)
+
+	// the config files report the content of stdin
+	files, err := ConfigFiles(context.Background(), provider)
+	require.NoError(t, err)
+	assert.Equal(t, []ConfigFile{{
+		Name:    StdinFilename,
+		Type:    "yaml",
+		Content: []byte("host: localhost\nport: 8080\n"),
+	}}, files)
+}
+
+func TestSourceFileStdinTooLarge(t *testing.T) {
+	type config struct {
+		Host string `mapstructure:"host"`
+	}
+
+	empty, stdin := "", "-"
+	source := &SourceFile[config]{
+		log:              slog.New(slog.DiscardHandler),
+		stdin:            strings.NewReader("host: localhost\n"),
+		maxSize:          4,
+		flagEnvPrefix:    &empty,
+		flagConfigPath:   &empty,
+		flagAbsolutePath: &stdin,
+		flagProfile:      &empty,
+	}
+
+	_, err := newProvider[config](source, slog.New(slog.DiscardHandler)).Config()
+	assert.ErrorContains(t, err, "reading config from stdin")
+	assert.NotErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestConfigFilesContext(t *testing.T) {
+	// stdin is never written, reading it blocks until the test is done
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	empty, stdin := "", "-"
+	source := &SourceFile[struct{}]{
+		log:              slog.New(slog.DiscardHandler),
+		stdin:            pr,
+		flagEnvPrefix:    &empty,
+		flagConfigPath:   &empty,
+		flagAbsolutePath: &stdin,
+		flagProfile:      &empty,
+	}
+	provider := newProvider[struct{}](source, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := ConfigFiles(ctx, provider)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestFileSettings(t *testing.T) {
+	type config struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port" default:"80"`
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "base.yaml"), []byte("host: base\nport: 8080\n"), 0644))
+	filename := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(filename, []byte("include: [base.yaml]\nhost: localhost\n"), 0644))
+
+	// env overrides are no part of the file
+	t.Setenv("APP_HOST", "example.com")
+
+	empty, prefix := "", "APP"
+	source := &SourceFile[config]{
+		log:              slog.New(slog.DiscardHandler),
+		flagEnvPrefix:    &prefix,
+		flagConfigPath:   &empty,
+		flagAbsolutePath: &filename,
+		flagProfile:      &empty,
+	}
+	provider := newProvider[config](source, slog.New(slog.DiscardHandler))
+
+	cfg, err := provider.Config(WithIncludeKey("include"))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", cfg.Host)
+
+	// the extensionless file is sniffed and includes are merged
+	settings, err := FileSettings(provider)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"host": "localhost", "port": 8080}, settings)
+
+	_, err = FileSettings(NewStaticProvider(cfg))
+	assert.ErrorContains(t, err, "does not read a config file")
+}
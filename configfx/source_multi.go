@@ -0,0 +1,267 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"slices"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// SourceMulti is a config source merging the config files of multiple
+// sources, later sources override earlier ones
+type SourceMulti[T any] struct {
+	// log defines the Logger instance to use
+	log *slog.Logger
+
+	// sources are merged in order
+	sources []Source[T]
+}
+
+// ensure SourceMulti[T] implements Source[T], FragmentedSource,
+// EnvRestrictedSource, flaggedSource and io.Closer
+var (
+	_ Source[any]         = &SourceMulti[any]{}
+	_ FragmentedSource    = &SourceMulti[any]{}
+	_ EnvRestrictedSource = &SourceMulti[any]{}
+	_ flaggedSource       = &SourceMulti[any]{}
+	_ io.Closer           = &SourceMulti[any]{}
+)
+
+// NewSourceMulti returns a Source constructor merging the config files of
+// sources in order, later ones override earlier ones. Sources without a
+// config file are skipped, at least one of them must provide one.
+// The last source provides env overrides and the config file modified by
+// the config command. Includes of [WithIncludeKey] are not supported.
+// Root flags like --env-prefix and --config-file are defined once for
+// all sources, the default env prefix is the one of the last source.
+// Usage example:
+//
+//	//go:embed config.yaml
+//	var embedded embed.FS
+//
+//	fx.Provide(configfx.NewSourceMulti(
+//		configfx.NewSourceFS[Config](embedded, "config.yaml"), // fallback
+//		configfx.NewSourceFile[Config]("config"),
+//	)),
+func NewSourceMulti[T any](sources ...func(*slog.Logger) Source[T]) func(*slog.Logger) Source[T] {
+	return func(log *slog.Logger) Source[T] {
+		s := &SourceMulti[T]{
+			log: log.With(slog.String("context", "config-multi")),
+		}
+		for _, source := range sources {
+			s.sources = append(s.sources, source(log))
+		}
+
+		return s
+	}
+}
+
+// Viper implements Source[T]
+// It returns a fresh *Viper of the last source with opts.
+func (s *SourceMulti[T]) Viper(
+	opts ...viper.Option,
+) *viper.Viper {
+	if len(s.sources) == 0 {
+		return viper.NewWithOptions(opts...)
+	}
+
+	return s.sources[len(s.sources)-1].Viper(opts...)
+}
+
+// defaultEnvPrefix implements flaggedSource.
+// It returns the default of the last source providing env overrides.
+func (s *SourceMulti[T]) defaultEnvPrefix() string {
+	if len(s.sources) == 0 {
+		return ""
+	}
+	if flagged, ok := s.sources[len(s.sources)-1].(flaggedSource); ok {
+		return flagged.defaultEnvPrefix()
+	}
+	return ""
+}
+
+// fileFlags implements flaggedSource.
+// It returns true if any source reads the file flags.
+func (s *SourceMulti[T]) fileFlags() bool {
+	return slices.ContainsFunc(s.sources, func(source Source[T]) bool {
+		flagged, ok := source.(flaggedSource)
+		return ok && flagged.fileFlags()
+	})
+}
+
+// setFlags implements flaggedSource.
+// It passes flags to all sources.
+func (s *SourceMulti[T]) setFlags(flags *sourceFlags) {
+	for _, source := range s.sources {
+		if flagged, ok := source.(flaggedSource); ok {
+			flagged.setFlags(flags)
+		}
+	}
+}
+
+// Close implements io.Closer.
+// It closes all sources implementing io.Closer.
+func (s *SourceMulti[T]) Close() error {
+	errs := []error{}
+	for _, source := range s.sources {
+		if closer, ok := source.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// EnvAllowlist implements EnvRestrictedSource.
+// It returns the allowlist of the last source providing the viper instance.
+func (s *SourceMulti[T]) EnvAllowlist() []string {
+	if len(s.sources) == 0 {
+		return nil
+	}
+	if restricted, ok := s.sources[len(s.sources)-1].(EnvRestrictedSource); ok {
+		return restricted.EnvAllowlist()
+	}
+	return nil
+}
+
+// ReadFragments implements FragmentedSource
+func (s *SourceMulti[T]) ReadFragments(v *viper.Viper) error {
+	found := []map[string]any{}
+	for _, source := range s.sources {
+		sv, err := readSource[T](source)
+		if isNotFound(err) {
+			s.log.Debug("skipping source without config file",
+				slog.String("source", fmt.Sprintf("%T", source)),
+				slog.Any("error", err))
+			found = append(found, nil)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		s.log.Debug("merging config file",
+			slog.String("file", sv.ConfigFileUsed()))
+		found = append(found, sv.AllSettings())
+	}
+	if !slices.ContainsFunc(found, func(m map[string]any) bool { return m != nil }) {
+		return fmt.Errorf("no config file found in %d sources", len(s.sources))
+	}
+
+	// read the last source into v to drop keys of previous reads,
+	// it is merged again to take precedence over the others
+	if top := len(s.sources) - 1; found[top] != nil {
+		readInConfig := v.ReadInConfig
+		if fragmented, ok := s.sources[top].(FragmentedSource); ok {
+			readInConfig = func() error { return fragmented.ReadFragments(v) }
+		}
+		if err := readInConfig(); err != nil {
+			return err
+		}
+	}
+	for _, settings := range found {
+		if err := v.MergeConfigMap(settings); err != nil {
+			return fmt.Errorf("merging config: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// configFiles implements fileLister.
+// It returns the config files of all sources providing one in the
+// order they are merged.
+func (s *SourceMulti[T]) configFiles(*viper.Viper) ([]ConfigFile, error) {
+	files := []ConfigFile{}
+	for _, source := range s.sources {
+		lister, ok := source.(fileLister)
+		if !ok {
+			continue
+		}
+		sv, err := readSource[T](source)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found, err := lister.configFiles(sv)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+
+	return files, nil
+}
+
+// WatchFragments implements FragmentedSource
+func (s *SourceMulti[T]) WatchFragments(onChange func(in fsnotify.Event)) error {
+	for _, source := range s.sources {
+		if fragmented, ok := source.(FragmentedSource); ok {
+			if err := fragmented.WatchFragments(onChange); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// sources without a config file can't be watched
+		sv, err := readSource[T](source)
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		sv.OnConfigChange(onChange)
+		sv.WatchConfig()
+	}
+
+	return nil
+}
+
+// readSource returns a viper instance of source which has read
+// its config file without env overrides
+func readSource[T any](source Source[T]) (*viper.Viper, error) {
+	var v *viper.Viper
+	if file, ok := source.(fileSource); ok {
+		v = file.fileViper()
+	} else {
+		v = source.Viper()
+	}
+
+	readInConfig := v.ReadInConfig
+	if fragmented, ok := source.(FragmentedSource); ok {
+		readInConfig = func() error { return fragmented.ReadFragments(v) }
+	}
+	if err := readInConfig(); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// isNotFound returns true if err denotes a missing config file
+func isNotFound(err error) bool {
+	var notFound viper.ConfigFileNotFoundError
+	return errors.As(err, &notFound) || errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/globals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceMulti(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.yaml": &fstest.MapFile{Data: []byte(`
+host: embedded.example.com
+port: 8080
+`)},
+	}
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[profileConfig](
+		configfx.NewSourceMulti(
+			configfx.NewSourceFS[profileConfig](fsys, "config.yaml"),
+			func(*slog.Logger) configfx.Source[profileConfig] {
+				return &fileSource[profileConfig]{filename: filename}
+			},
+		)(log),
+		log,
+	)
+
+	// the embedded config is used without an external file
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "embedded.example.com", cfg.Host)
+	assert.Equal(t, 8080, cfg.Port)
+
+	// the external file overrides embedded values
+	writeConfig(t, filepath.Dir(filename), "config.yaml", `
+host: external.example.com
+name: external
+`)
+	cfg, err = provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "external.example.com", cfg.Host) // external
+	assert.Equal(t, 8080, cfg.Port)                   // embedded
+	assert.Equal(t, "external", cfg.Name)             // external
+
+	// keys removed from the external file fall back to embedded ones
+	writeConfig(t, filepath.Dir(filename), "config.yaml", "port: 9090\n")
+	cfg, err = provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "embedded.example.com", cfg.Host)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "app", cfg.Name) // default
+
+	// at least one source must provide a config file
+	require.NoError(t, os.Remove(filename))
+	provider = configfx.NewProvider[profileConfig](
+		configfx.NewSourceMulti(
+			configfx.NewSourceFS[profileConfig](fsys, "missing.yaml"),
+			func(*slog.Logger) configfx.Source[profileConfig] {
+				return &fileSource[profileConfig]{filename: filename}
+			},
+		)(log),
+		log,
+	)
+	_, err = provider.Config()
+	assert.ErrorContains(t, err, "no config file found")
+}
+
+func TestSourceMultiFlags(t *testing.T) {
+	globals.ResetRootFlags()
+	t.Cleanup(globals.ResetRootFlags)
+
+	fsys := fstest.MapFS{
+		"defaults.yaml": &fstest.MapFile{Data: []byte(`
+host: embedded.example.com
+port: 8080
+`)},
+	}
+	dir := t.TempDir()
+	writeConfig(t, dir, "myapp.yaml", "name: external\n")
+	t.Setenv("MYAPP_PORT", "9090")
+
+	// nested sources share the root flags of the multi source
+	log := slog.New(slog.DiscardHandler)
+	provider := configfx.NewProvider[profileConfig](
+		configfx.NewSourceMulti(
+			configfx.NewSourceFS[profileConfig](fsys, "defaults.yaml"),
+			configfx.NewSourceFile[profileConfig]("other", dir),
+			configfx.NewSourceFile[profileConfig]("myapp", dir),
+		)(log),
+		log,
+	)
+
+	// the env prefix defaults to the one of the last source
+	// which provides env overrides
+	flag := globals.RootFlags.Lookup("env-prefix")
+	require.NotNil(t, flag)
+	assert.Equal(t, "MYAPP", flag.DefValue)
+
+	cfg, err := provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "embedded.example.com", cfg.Host) // embedded
+	assert.Equal(t, 9090, cfg.Port)                   // env
+	assert.Equal(t, "external", cfg.Name)             // external
+
+	// the file flags apply to the nested file sources
+	filename := writeConfig(t, t.TempDir(), "explicit.yaml", "name: explicit\n")
+	require.NoError(t, globals.RootFlags.Set("config-file", filename))
+	cfg, err = provider.Config()
+	require.NoError(t, err)
+	assert.Equal(t, "explicit", cfg.Name)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// staticProvider implements Provider[T] returning a fixed config
+type staticProvider[T any] struct {
+	config *T
+
+	viper     *viper.Viper
+	viperOnce sync.Once
+}
+
+// ensure staticProvider[T] implements Provider[T]
+var _ Provider[any] = &staticProvider[any]{}
+
+// NewStaticProvider returns a Provider[T] which always returns config.
+// It does not read any sources and ignores all ConfigOption.
+// Use it in tests to replace the provider of stdfx.ConfigFile:
+//
+//	fx.Decorate(func(configfx.Provider[mypkg.Config]) configfx.Provider[mypkg.Config] {
+//		return configfx.NewStaticProvider(&mypkg.Config{})
+//	}),
+func NewStaticProvider[T any](config *T) Provider[T] {
+	return &staticProvider[T]{
+		config: config,
+	}
+}
+
+// Config implements Provider[T]
+func (p *staticProvider[T]) Config(...ConfigOption) (*T, error) {
+	return p.config, nil
+}
+
+// Viper implements Provider[T].
+// The returned instance holds the settings of config.
+func (p *staticProvider[T]) Viper() *viper.Viper {
+	p.viperOnce.Do(func() {
+		p.viper = viper.New()
+		if settings, ok := encodeValue(reflect.ValueOf(p.config)).(map[string]any); ok {
+			_ = p.viper.MergeConfigMap(settings)
+		}
+	})
+
+	return p.viper
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	cfg := &strictConfig{Labels: map[string]string{"env": "test"}}
+	cfg.Webserver.Host = "localhost"
+	cfg.Webserver.Port = 8080
+
+	provider := configfx.NewStaticProvider(cfg)
+
+	got, err := provider.Config(configfx.WithReadInConfig(true))
+	require.NoError(t, err)
+	assert.Same(t, cfg, got)
+
+	assert.Equal(t, "localhost", provider.Viper().GetString("webserver.host"))
+	assert.Equal(t, 8080, provider.Viper().GetInt("webserver.port"))
+	assert.Equal(t, "test", provider.Viper().GetString("labels.env"))
+}
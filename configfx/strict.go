@@ -0,0 +1,168 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// ParseError is a structured error returned by strict config parsers
+type ParseError struct {
+	// Format is the config format being parsed, e.g. "json"
+	Format string
+	// Offset is the byte offset of the error in the input or -1 if unknown
+	Offset int64
+	// Key is the dotted key of the offending field if any
+	Key string
+	// Err is the underlying error
+	Err error
+}
+
+// Error implements error
+func (e *ParseError) Error() string {
+	msg := e.Format + ": " + e.Err.Error()
+	if len(e.Key) > 0 {
+		msg += fmt.Sprintf(" at key %q", e.Key)
+	}
+	if e.Offset >= 0 {
+		msg += fmt.Sprintf(" (offset %d)", e.Offset)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// ErrUnknownField is wrapped by [ParseError] for keys not found in T
+	ErrUnknownField = errors.New("unknown field")
+	// ErrTrailingData is wrapped by [ParseError] for content after the config
+	ErrTrailingData = errors.New("trailing data after config")
+)
+
+// StrictJSON parses b as a single JSON document and returns a *[ParseError]
+// for syntax errors, trailing content and keys unknown to T.
+// Keys are matched against `mapstructure:""` tags like [KeyType] does,
+// maps and interface fields accept any key.
+func StrictJSON[T any](b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	if err := strictJSONValue(dec, reflect.TypeFor[T](), ""); err != nil {
+		return err
+	}
+
+	// there must not be anything left
+	offset := dec.InputOffset()
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return &ParseError{Format: "json", Offset: offset, Err: ErrTrailingData}
+	}
+
+	return nil
+}
+
+// strictJSONValue reads the next value from dec checking its keys against typ.
+// A nil typ accepts any key.
+func strictJSONValue(dec *json.Decoder, typ reflect.Type, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return jsonParseError(dec, path, err)
+	}
+
+	// resolve the type to check keys against
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ != nil && typ.Kind() != reflect.Struct && typ.Kind() != reflect.Map &&
+		typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+		// interfaces accept anything, type mismatches are left to the decoder
+		typ = nil
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			offset := dec.InputOffset()
+			keyTok, err := dec.Token()
+			if err != nil {
+				return jsonParseError(dec, path, err)
+			}
+			key, _ := keyTok.(string)
+			keyPath := joinKey(path, key)
+
+			var child reflect.Type
+			if typ != nil {
+				var ok bool
+				child, ok = childType(typ, key)
+				if !ok {
+					return &ParseError{Format: "json", Offset: offset, Key: keyPath, Err: ErrUnknownField}
+				}
+			}
+			if err := strictJSONValue(dec, child, keyPath); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return jsonParseError(dec, path, err)
+		}
+
+	case json.Delim('['):
+		var elem reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elem = typ.Elem()
+		}
+		for i := 0; dec.More(); i++ {
+			if err := strictJSONValue(dec, elem, joinKey(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return jsonParseError(dec, path, err)
+		}
+	}
+
+	return nil
+}
+
+// jsonParseError wraps err of dec into a *ParseError
+func jsonParseError(dec *json.Decoder, path string, err error) error {
+	offset := dec.InputOffset()
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		offset = serr.Offset
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return &ParseError{Format: "json", Offset: offset, Key: path, Err: err}
+}
+
+// joinKey appends key to the dotted path
+func joinKey(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type strictConfig struct {
+	Webserver struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+	} `mapstructure:"webserver"`
+
+	Labels map[string]string `mapstructure:"labels"`
+	Extra  any               `mapstructure:"extra"`
+}
+
+func TestStrictJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		err    error
+		key    string
+		offset int64
+	}{
+		{
+			name:  "valid",
+			input: `{"webserver":{"host":"localhost","port":8080},"labels":{"a":"b"},"extra":{"any":[1]}}`,
+		},
+		{
+			name:   "unknown field",
+			input:  `{"webserver":{"host":"localhost","hots":"x"}}`,
+			err:    configfx.ErrUnknownField,
+			key:    "webserver.hots",
+			offset: 32,
+		},
+		{
+			name:   "trailing data",
+			input:  `{"webserver":{}} {"labels":{}}`,
+			err:    configfx.ErrTrailingData,
+			offset: 16,
+		},
+		{
+			name:   "syntax error",
+			input:  `{"webserver":{"host":}}`,
+			key:    "webserver.host",
+			offset: 22,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := configfx.StrictJSON[strictConfig]([]byte(tt.input))
+			if tt.offset == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			var perr *configfx.ParseError
+			require.True(t, errors.As(err, &perr), "expected *ParseError, got %v", err)
+			assert.Equal(t, "json", perr.Format)
+			assert.Equal(t, tt.offset, perr.Offset)
+			assert.Equal(t, tt.key, perr.Key)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -16,9 +16,43 @@ limitations under the License.
 
 package configfx
 
+import "context"
+
 // CustomValidator denotes types which implement a custom Validate()
 // for use with config validation.
+//
+// Common invariants between fields of a struct can be declared using
+// struct tags instead, which are checked by [Provider] after unmarshal:
+//
+//	CertFile string `mapstructure:"cert" requiredWith:"KeyFile"`
+//	KeyFile  string `mapstructure:"key" requiredWith:"CertFile"`
+//
+//	Token    string `mapstructure:"token" mutuallyExclusive:"auth"`
+//	Password string `mapstructure:"password" mutuallyExclusive:"auth"`
+//
+// requiredWith lists comma separated fields which must be set if the
+// tagged field is set. Of all fields sharing a mutuallyExclusive group
+// at most one may be set. A field is set if it is not the zero value.
 type CustomValidator interface {
 	// Validate shall return an error or nil when used during validation.
 	Validate() error
 }
+
+// PostValidator denotes types which implement a semantic PostValidate()
+// of the fully resolved config.
+//
+// Unlike [CustomValidator] it is called by [Provider] as the final step
+// of Config after overlays, defaults and secrets have been applied,
+// e.g. to check invariants between fields populated by different sources:
+//
+//	func (c *Config) PostValidate(ctx context.Context) error {
+//		if c.Webserver.Port == c.Metrics.Port {
+//			return fmt.Errorf("webserver and metrics must use different ports")
+//		}
+//		return nil
+//	}
+type PostValidator interface {
+	// PostValidate shall return an error or nil if the config is usable.
+	// ctx is the one given to [ConfigContext].
+	PostValidate(ctx context.Context) error
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfx
+
+import (
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// onChangeCallback is the callback of a [WithOnConfigChange] option.
+// Its address identifies the option to register it only once.
+type onChangeCallback struct {
+	callback func(in fsnotify.Event)
+}
+
+// onChangeCallbacks are callbacks of [WithOnConfigChange] options
+type onChangeCallbacks []*onChangeCallback
+
+// add returns c with callbacks appended which are not part of c
+func (c onChangeCallbacks) add(callbacks ...*onChangeCallback) onChangeCallbacks {
+	for _, callback := range callbacks {
+		if !slices.Contains(c, callback) {
+			c = append(c, callback)
+		}
+	}
+	return c
+}
+
+// call invokes all callbacks in order
+func (c onChangeCallbacks) call(in fsnotify.Event) {
+	for _, callback := range c {
+		callback.callback(in)
+	}
+}
+
+// addOnChange registers callbacks to be invoked on config changes
+func (s *providerImpl[T]) addOnChange(callbacks onChangeCallbacks) {
+	s.onChangeMutex.Lock()
+	defer s.onChangeMutex.Unlock()
+
+	s.onChange = s.onChange.add(callbacks...)
+}
+
+// callOnChange invokes all callbacks registered by addOnChange
+func (s *providerImpl[T]) callOnChange(in fsnotify.Event) {
+	s.onChangeMutex.Lock()
+	callbacks := slices.Clone(s.onChange)
+	s.onChangeMutex.Unlock()
+
+	callbacks.call(in)
+}
+
+// watch starts watching the config read into v once, invoking the
+// callbacks registered by addOnChange on changes until Close is called
+func (s *providerImpl[T]) watch(v *viper.Viper) error {
+	s.watcherMutex.Lock()
+	defer s.watcherMutex.Unlock()
+
+	if s.watching {
+		return nil
+	}
+
+	// fragmented sources watch their fragments until they are closed
+	if fragmented, ok := s.source.(FragmentedSource); ok {
+		if err := fragmented.WatchFragments(s.callOnChange); err != nil {
+			return err
+		}
+		s.watching = true
+		return nil
+	}
+
+	// configs not read from a file can't be watched
+	filename := v.ConfigFileUsed()
+	if len(filename) == 0 || filename == StdinFilename {
+		return nil
+	}
+
+	watcher, err := s.watchFile(v, filename)
+	if err != nil {
+		return err
+	}
+	s.watcher, s.watching = watcher, true
+
+	return nil
+}
+
+// watchFile starts a watcher reading filename into v again once it is
+// written or replaced. Like viper it watches the directory of filename
+// to notice atomic saves and symlink swaps, e.g. of kubernetes configmaps.
+func (s *providerImpl[T]) watchFile(v *viper.Viper, filename string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watching %q: %s", filename, err)
+	}
+	filename = filepath.Clean(filename)
+	if err := watcher.Add(filepath.Dir(filename)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("watching %q: %s", filename, err)
+	}
+
+	realFilename, _ := filepath.EvalSymlinks(filename)
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				currentFilename, _ := filepath.EvalSymlinks(filename)
+				written := filepath.Clean(event.Name) == filename &&
+					(event.Has(fsnotify.Write) || event.Has(fsnotify.Create))
+				swapped := currentFilename != "" && currentFilename != realFilename
+				if !written && !swapped {
+					continue
+				}
+				realFilename = currentFilename
+
+				// read the config file again and merge the others
+				includeKey, _ := s.includeKey.Load().(string)
+				if err := v.ReadInConfig(); err != nil {
+					s.log.Warn("failed to read changed config file",
+						slog.String("file", filename),
+						slog.Any("error", err))
+				} else if err := s.mergeConfig(v, includeKey); err != nil {
+					s.log.Warn("failed to merge config files",
+						slog.Any("error", err))
+				}
+				s.callOnChange(event)
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				s.log.Error("watching config file failed",
+					slog.String("file", filename),
+					slog.Any("error", err))
+			}
+		}
+	}()
+
+	return watcher, nil
+}
+
+// stopWatching stops the watcher started by watch
+func (s *providerImpl[T]) stopWatching() error {
+	s.watcherMutex.Lock()
+	defer s.watcherMutex.Unlock()
+
+	if s.watcher == nil {
+		return nil
+	}
+	err := s.watcher.Close()
+	s.watcher = nil
+
+	return err
+}
@@ -19,6 +19,9 @@ package stdfx
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"slices"
+	"sync"
 
 	"go.uber.org/fx"
 )
@@ -29,8 +32,20 @@ type shutdownerContextValue fx.Shutdowner
 // shutdownerContextKey is used to inject fx.Shutdowner into Context
 var shutdownerContextKey = &shutdownerContextKeyType{}
 
-// ErrContextMissingShutdowner can be returned by [Shutdown]
-var ErrContextMissingShutdowner = errors.New("context is missing shutdowner")
+type lifecycleContextKeyType struct{}
+type lifecycleContextValue fx.Lifecycle
+
+// lifecycleContextKey is used to inject fx.Lifecycle into Context
+var lifecycleContextKey = &lifecycleContextKeyType{}
+
+var (
+	// ErrContextMissingShutdowner can be returned by [Shutdown]
+	// and [ShutdownerFromContext]
+	ErrContextMissingShutdowner = errors.New("context is missing shutdowner")
+
+	// ErrContextMissingLifecycle can be returned by [LifecycleFromContext]
+	ErrContextMissingLifecycle = errors.New("context is missing lifecycle")
+)
 
 // withShutdowner injects shutdowner into ctx for use with [Shutdown]
 func withShutdowner(
@@ -44,8 +59,10 @@ func withShutdowner(
 	)
 }
 
-// shutdownerFromContext returns a fx.Shutdowner from ctx or error
-func shutdownerFromContext(ctx context.Context) (fx.Shutdowner, error) {
+// ShutdownerFromContext returns the fx.Shutdowner injected into ctx
+// by [Commander] or [ErrContextMissingShutdowner].
+// Use it to shutdown the fx.App from within a command.
+func ShutdownerFromContext(ctx context.Context) (fx.Shutdowner, error) {
 	v := ctx.Value(shutdownerContextKey)
 	if v == nil {
 		return nil, ErrContextMissingShutdowner
@@ -57,15 +74,94 @@ func shutdownerFromContext(ctx context.Context) (fx.Shutdowner, error) {
 	return val, nil
 }
 
+// withLifecycle injects lc into ctx for use with [LifecycleFromContext]
+func withLifecycle(
+	ctx context.Context,
+	lc fx.Lifecycle,
+) context.Context {
+	return context.WithValue(
+		ctx,
+		lifecycleContextKey,
+		lifecycleContextValue(lc),
+	)
+}
+
+// LifecycleFromContext returns the fx.Lifecycle injected into ctx
+// by [Commander] or [ErrContextMissingLifecycle].
+// Use it to register hooks of resources created within a command.
+// Since the fx.App has started already, OnStart hooks are run immediately
+// and OnStop hooks are run in reverse order after the command returned.
+func LifecycleFromContext(ctx context.Context) (fx.Lifecycle, error) {
+	v := ctx.Value(lifecycleContextKey)
+	if v == nil {
+		return nil, ErrContextMissingLifecycle
+	}
+	val, ok := v.(lifecycleContextValue)
+	if !ok {
+		return nil, ErrContextMissingLifecycle
+	}
+	return val, nil
+}
+
 // Shutdown uses fx.Shutdowner from ctx to shutdown a fx.App using exitCode.
 // This works when [Commander] was used to start it.
 // You can use this to shutdown an application unaware of fx during runtime.
 // It might return [ErrContextMissingShutdowner] in which case it is up to you
 // to directly call [os.Exit] or panic.
 func Shutdown(ctx context.Context, exitCode int) error {
-	shutdowner, err := shutdownerFromContext(ctx)
+	shutdowner, err := ShutdownerFromContext(ctx)
 	if err != nil {
 		return err
 	}
 	return shutdowner.Shutdown(fx.ExitCode(exitCode))
 }
+
+// commandLifecycle is a fx.Lifecycle for use by running commands.
+// fx does not run hooks appended after the app has started, therefore
+// OnStart hooks are run immediately and OnStop hooks are run by stop.
+type commandLifecycle struct {
+	shutdowner fx.Shutdowner
+	log        *slog.Logger
+
+	mutex sync.Mutex
+	stops []func(context.Context) error
+}
+
+// ensure commandLifecycle implements fx.Lifecycle
+var _ fx.Lifecycle = &commandLifecycle{}
+
+// Append implements fx.Lifecycle.
+// A failing OnStart hook shuts down the app using exit code 1.
+func (l *commandLifecycle) Append(hook fx.Hook) {
+	if hook.OnStart != nil {
+		if err := hook.OnStart(context.Background()); err != nil {
+			l.log.Error("failed to start lifecycle hook of command",
+				slog.Any("error", err))
+			_ = l.shutdowner.Shutdown(fx.ExitCode(1))
+			return
+		}
+	}
+
+	if hook.OnStop != nil {
+		l.mutex.Lock()
+		l.stops = append(l.stops, hook.OnStop)
+		l.mutex.Unlock()
+	}
+}
+
+// stop runs all OnStop hooks in reverse order using ctx
+func (l *commandLifecycle) stop(ctx context.Context) error {
+	l.mutex.Lock()
+	stops := slices.Clone(l.stops)
+	l.stops = nil
+	l.mutex.Unlock()
+
+	var errs []error
+	for _, stop := range slices.Backward(stops) {
+		if err := stop(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+func TestFromContextMissing(t *testing.T) {
+	_, err := stdfx.ShutdownerFromContext(context.Background())
+	assert.ErrorIs(t, err, stdfx.ErrContextMissingShutdowner)
+
+	_, err = stdfx.LifecycleFromContext(context.Background())
+	assert.ErrorIs(t, err, stdfx.ErrContextMissingLifecycle)
+
+	assert.ErrorIs(t, stdfx.Shutdown(context.Background(), 0), stdfx.ErrContextMissingShutdowner)
+}
+
+func TestFromContextCommander(t *testing.T) {
+	var (
+		shutdowner fx.Shutdowner
+		lc         fx.Lifecycle
+		errs       = make(chan error, 2)
+	)
+	cmd := &cobra.Command{
+		Use: "inspect",
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			shutdowner, err = stdfx.ShutdownerFromContext(cmd.Context())
+			errs <- err
+			lc, err = stdfx.LifecycleFromContext(cmd.Context())
+			errs <- err
+		},
+	}
+	cmd.SetArgs([]string{})
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(cmd, slog.New(slog.DiscardHandler)),
+		fx.Invoke(stdfx.CommanderWithOptions(
+			stdfx.WithStartBackoff(50*time.Millisecond),
+		)),
+	)
+	require.NoError(t, app.Start(context.Background()))
+	defer app.Stop(context.Background()) // nolint:errcheck
+
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+	assert.NotNil(t, shutdowner)
+	assert.NotNil(t, lc)
+}
+
+func TestLifecycleFromContextHook(t *testing.T) {
+	events := make(chan string, 3)
+	cmd := &cobra.Command{
+		Use: "serve",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lc, err := stdfx.LifecycleFromContext(cmd.Context())
+			if err != nil {
+				return err
+			}
+			lc.Append(fx.Hook{
+				OnStart: func(context.Context) error {
+					events <- "start"
+					return nil
+				},
+				OnStop: func(context.Context) error {
+					events <- "stop"
+					return nil
+				},
+			})
+
+			<-cmd.Context().Done()
+			events <- "returned"
+			return nil
+		},
+	}
+	cmd.SetArgs([]string{})
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(cmd, slog.New(slog.DiscardHandler)),
+		fx.Invoke(stdfx.CommanderWithOptions(
+			stdfx.WithStartBackoff(50*time.Millisecond),
+		)),
+	)
+	require.NoError(t, app.Start(context.Background()))
+	assert.Equal(t, "start", <-events)
+	require.NoError(t, app.Stop(context.Background()))
+
+	// hooks of the command run after it returned
+	assert.Equal(t, "returned", <-events)
+	assert.Equal(t, "stop", <-events)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/healthfx"
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+// ErrDoctorFailed is returned by [DoctorCommand] if any check failed
+var ErrDoctorFailed = errors.New("doctor checks failed")
+
+// DoctorCheck is a named diagnostic run by [DoctorCommand]
+type DoctorCheck struct {
+	// Name is printed in the report
+	Name string
+	// Check shall return an error if the check fails
+	Check func(ctx context.Context) error
+}
+
+// AutoDoctorCheck annotates a [DoctorCheck] constructor to
+// contribute its check to [DoctorCommand].
+// Usage example:
+//
+//	fx.Provide(
+//		stdfx.AutoDoctorCheck(func(db *sql.DB) stdfx.DoctorCheck {
+//			return stdfx.DoctorCheck{Name: "database", Check: db.PingContext}
+//		}),
+//	),
+func AutoDoctorCheck(f any) any {
+	return fx.Annotate(
+		f,
+		fx.ResultTags(`group:"doctor-checks"`),
+	)
+}
+
+// doctorOptions stores options for [DoctorOption] funcs
+type doctorOptions struct {
+	allowRoot bool
+	dirs      []string
+	envVars   []string
+}
+
+// DoctorOption is a func to adjust options of *doctorOptions for later
+// usage during [DoctorCommand].
+type DoctorOption func(*doctorOptions)
+
+// WithDoctorAllowRoot skips the check for running as root
+func WithDoctorAllowRoot() DoctorOption {
+	return func(o *doctorOptions) {
+		o.allowRoot = true
+	}
+}
+
+// WithDoctorDirs checks that the directories dirs exist
+func WithDoctorDirs(dirs ...string) DoctorOption {
+	return func(o *doctorOptions) {
+		o.dirs = append(o.dirs, dirs...)
+	}
+}
+
+// WithDoctorEnv checks that the environment variables names are present
+func WithDoctorEnv(names ...string) DoctorOption {
+	return func(o *doctorOptions) {
+		o.envVars = append(o.envVars, names...)
+	}
+}
+
+// DoctorParams are the dependencies of the constructor of [DoctorCommand].
+// Checks are contributed using [AutoDoctorCheck], probes of an optional
+// *healthfx.HealthRegistry are run as checks as well.
+type DoctorParams[T any] struct {
+	fx.In
+
+	Log            *slog.Logger
+	ConfigProvider configfx.Provider[T]
+	Checks         []DoctorCheck            `group:"doctor-checks"`
+	Registry       *healthfx.HealthRegistry `optional:"true"`
+}
+
+// DefaultDoctorTimeout is the default for the --timeout flag of [DoctorCommand]
+const DefaultDoctorTimeout = 10 * time.Second
+
+// DoctorCommand returns a *cobra.Command constructor running environment
+// checks and printing a pass/fail report. It checks that:
+//   - the config loads and validates
+//   - the log output is writable
+//   - the process does not run as root unless allowed
+//   - directories and environment variables given by opts exist
+//   - any contributed [DoctorCheck] and health probe passes
+//
+// The command fails with [ErrDoctorFailed] if any check failed.
+func DoctorCommand[T any](opts ...DoctorOption) func(p DoctorParams[T]) *cobra.Command {
+	// apply any given opts
+	dOpts := &doctorOptions{}
+	for _, option := range opts {
+		option(dOpts)
+	}
+
+	return func(p DoctorParams[T]) *cobra.Command {
+		var timeout time.Duration
+		cmd := &cobra.Command{
+			Use:   "doctor",
+			Short: "run environment checks and print a report",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				checks := doctorChecks(p, dOpts)
+
+				failed := 0
+				for _, check := range checks {
+					err := runDoctorCheck(cmd.Context(), timeout, check)
+					if err != nil {
+						failed++
+						fmt.Fprintf(cmd.OutOrStdout(), "FAIL  %s: %s\n", check.Name, err)
+						continue
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "PASS  %s\n", check.Name)
+				}
+
+				if failed > 0 {
+					return fmt.Errorf("%w: %d of %d", ErrDoctorFailed, failed, len(checks))
+				}
+				return nil
+			},
+		}
+		cmd.Flags().DurationVar(&timeout, "timeout", DefaultDoctorTimeout,
+			"maximum duration of each check, 0 disables it")
+
+		return cmd
+	}
+}
+
+// doctorChecks returns the builtin and contributed checks of p and opts
+func doctorChecks[T any](p DoctorParams[T], opts *doctorOptions) []DoctorCheck {
+	checks := []DoctorCheck{
+		{
+			Name: "config",
+			Check: func(ctx context.Context) error {
+				return validateConfig(ctx, p.Log, p.ConfigProvider)
+			},
+		},
+		{
+			Name: "log output",
+			Check: func(context.Context) error {
+				cfg, err := p.ConfigProvider.Config()
+				if err != nil {
+					return err
+				}
+				ctype, ok := any(cfg).(loggingfx.ConfigWithLogging)
+				if !ok {
+					return nil
+				}
+				return checkLogOutput(ctype.LoggingConfig().Output)
+			},
+		},
+	}
+
+	if !opts.allowRoot {
+		checks = append(checks, DoctorCheck{
+			Name:  "unprivileged",
+			Check: func(context.Context) error { return Unprivileged() },
+		})
+	}
+	for _, dir := range opts.dirs {
+		checks = append(checks, DoctorCheck{
+			Name: "directory " + dir,
+			Check: func(context.Context) error {
+				info, err := os.Stat(dir)
+				if err != nil {
+					return err
+				}
+				if !info.IsDir() {
+					return fmt.Errorf("%q is not a directory", dir)
+				}
+				return nil
+			},
+		})
+	}
+	for _, name := range opts.envVars {
+		checks = append(checks, DoctorCheck{
+			Name: "env " + name,
+			Check: func(context.Context) error {
+				if _, ok := os.LookupEnv(name); !ok {
+					return fmt.Errorf("%s is not set", name)
+				}
+				return nil
+			},
+		})
+	}
+
+	// contributed checks
+	checks = append(checks, p.Checks...)
+	if p.Registry != nil {
+		checks = append(checks, DoctorCheck{
+			Name: "health probes",
+			Check: func(ctx context.Context) error {
+				failed := p.Registry.Check(ctx)
+				errs := []error{}
+				for _, name := range slices.Sorted(maps.Keys(failed)) {
+					errs = append(errs, fmt.Errorf("%s: %s", name, failed[name]))
+				}
+				return errors.Join(errs...)
+			},
+		})
+	}
+
+	return checks
+}
+
+// runDoctorCheck runs check bound by timeout
+func runDoctorCheck(ctx context.Context, timeout time.Duration, check DoctorCheck) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// checks may ignore ctx, do not wait for them beyond it
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// checkLogOutput returns an error if output is a file which can't be written
+func checkLogOutput(output string) error {
+	switch output {
+	case "", "stdout", "stderr":
+		return nil
+	}
+
+	f, err := os.OpenFile(output, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/healthfx"
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// doctorSource is a configfx.Source[T] reading an explicit config file
+type doctorSource[T any] struct {
+	filename string
+}
+
+// Viper implements configfx.Source[T]
+func (s *doctorSource[T]) Viper(opts ...viper.Option) *viper.Viper {
+	v := viper.NewWithOptions(opts...)
+	v.SetConfigFile(s.filename)
+	return v
+}
+
+type doctorConfig struct {
+	Log loggingfx.Config `mapstructure:"log"`
+}
+
+// LoggingConfig implements loggingfx.ConfigWithLogging
+func (c *doctorConfig) LoggingConfig() loggingfx.Config {
+	return c.Log
+}
+
+// runDoctor executes the doctor command of config content using opts and
+// returns its report and error output
+func runDoctor(
+	t *testing.T,
+	content string,
+	checks []stdfx.DoctorCheck,
+	registry *healthfx.HealthRegistry,
+	opts ...stdfx.DoctorOption,
+) (string, string, error) {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0644))
+
+	log := slog.New(slog.DiscardHandler)
+	cmd := stdfx.DoctorCommand[doctorConfig](opts...)(stdfx.DoctorParams[doctorConfig]{
+		Log: log,
+		ConfigProvider: configfx.NewProvider[doctorConfig](
+			&doctorSource[doctorConfig]{filename: filename}, log),
+		Checks:   checks,
+		Registry: registry,
+	})
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	cmd.SetArgs([]string{})
+
+	err := cmd.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestDoctorCommandPass(t *testing.T) {
+	t.Setenv("DOCTOR_TOKEN", "secret")
+	registry := healthfx.NewHealthRegistry()
+	registry.Register("cache", func(context.Context) error { return nil })
+
+	report, errOut, err := runDoctor(t, "log:\n  output: stdout\n",
+		[]stdfx.DoctorCheck{{
+			Name:  "custom",
+			Check: func(context.Context) error { return nil },
+		}},
+		registry,
+		stdfx.WithDoctorAllowRoot(),
+		stdfx.WithDoctorDirs(t.TempDir()),
+		stdfx.WithDoctorEnv("DOCTOR_TOKEN"),
+	)
+	require.NoError(t, err)
+	assert.Empty(t, errOut)
+
+	for _, name := range []string{"config", "log output", "directory", "env DOCTOR_TOKEN", "custom", "health probes"} {
+		assert.Contains(t, report, "PASS  "+name)
+	}
+	assert.NotContains(t, report, "FAIL")
+	assert.NotContains(t, report, "unprivileged")
+}
+
+func TestDoctorCommandFail(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	registry := healthfx.NewHealthRegistry()
+	registry.Register("database", func(context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	report, errOut, err := runDoctor(t, "log:\n  output: "+filepath.Join(missing, "app.log")+"\n",
+		[]stdfx.DoctorCheck{{
+			Name:  "custom",
+			Check: func(context.Context) error { return errors.New("broken") },
+		}},
+		registry,
+		stdfx.WithDoctorAllowRoot(),
+		stdfx.WithDoctorDirs(missing),
+		stdfx.WithDoctorEnv("DOCTOR_MISSING_VARIABLE"),
+	)
+	assert.ErrorIs(t, err, stdfx.ErrDoctorFailed)
+	assert.Contains(t, errOut, "Error: doctor checks failed: 6 of 6")
+
+	assert.Contains(t, report, "FAIL  config: invalid log.output")
+	assert.Contains(t, report, "FAIL  log output")
+	assert.Contains(t, report, "FAIL  directory "+missing)
+	assert.Contains(t, report, "FAIL  env DOCTOR_MISSING_VARIABLE")
+	assert.Contains(t, report, "FAIL  custom: broken")
+	assert.Contains(t, report, "FAIL  health probes: database: connection refused")
+}
@@ -17,23 +17,43 @@ limitations under the License.
 package stdfx
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 )
 
 // ContainerEntrypointDefaultTools are the default tools for [ContainerEntrypoint]
 var ContainerEntrypointDefaultTools = []string{"sh", "/bin/sh", "bash", "/bin/bash"}
 
+const (
+	// ContainerEntrypointToolsEnv is the environment variable to override
+	// the tools of [ContainerEntrypoint] using a comma separated list.
+	// An empty value disables chaining to any tool.
+	ContainerEntrypointToolsEnv = "STDFX_ENTRYPOINT_TOOLS"
+
+	// ContainerEntrypointWildcardEnv is the environment variable to disable
+	// the wildcard tool '*' of [ContainerEntrypoint] by setting it to false.
+	ContainerEntrypointWildcardEnv = "STDFX_ENTRYPOINT_WILDCARD"
+)
+
 // ContainerEntrypoint might be used with [fx.Invoke] and tooling when the calling
 // go program is packaged into a container where it is used as the entrypoint.
 // This will execute any value of `tools` when given as the first argument and if found in $PATH.
 // If tools is empty it will use a default list: [ContainerEntrypointDefaultTools].
 // A special value of '*' allows for any tool.
+// The tools can be overridden per deployment using [ContainerEntrypointToolsEnv]
+// and the wildcard can be disabled using [ContainerEntrypointWildcardEnv]
+// for hardened images.
 // There is extra handling when the first argument is the binary name itself:
-// For such cases that argument is silbently shifted out and execution continues.
+// For such cases that argument is shifted out and execution continues.
+// The decision taken is logged at debug level.
 //
 // Example usage:
 //   - fx.Invoke(stdfx.ContainerEntrypoint())
@@ -45,16 +65,155 @@ var ContainerEntrypointDefaultTools = []string{"sh", "/bin/sh", "bash", "/bin/ba
 //   - docker run --rm -it ghcr.io/choopm/myproject:latest bash -i
 //   - docker run --rm -it ghcr.io/choopm/myproject:latest whoami
 //   - docker run --rm -it ghcr.io/choopm/myproject:latest myproject -c ...
-func ContainerEntrypoint(tools ...string) func() {
+func ContainerEntrypoint(tools ...string) func(log *slog.Logger) {
+	return ContainerEntrypointSecure(WithEntrypointTools(tools...))
+}
+
+// ErrEntrypointRejected is logged by [ContainerEntrypointSecure]
+// when a tool violates its allow rules
+var ErrEntrypointRejected = errors.New("tool rejected")
+
+// entrypointOptions stores options for [EntrypointOption] funcs
+type entrypointOptions struct {
+	tools         []string
+	absolutePaths bool
+	dirs          []string
+	dryRun        bool
+	exec          func(argv0 string, argv []string, envv []string) error
+	errorHandler  func(err error)
+	env           []string
+	envFilters    []func(entry string) bool
+}
+
+// EntrypointOption is a func to adjust options of *entrypointOptions for later
+// usage during [ContainerEntrypointSecure].
+type EntrypointOption func(*entrypointOptions)
+
+// WithEntrypointTools sets the allowed tools, see [ContainerEntrypoint].
+// [ContainerEntrypointDefaultTools] are used if no tools are given.
+func WithEntrypointTools(tools ...string) EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.tools = append(o.tools, tools...)
+	}
+}
+
+// WithEntrypointAbsolutePaths only allows tools given by an absolute path,
+// tools are never looked up in $PATH.
+func WithEntrypointAbsolutePaths() EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.absolutePaths = true
+	}
+}
+
+// WithEntrypointDirs only allows tools residing inside dirs
+// after resolving them using $PATH and symlinks.
+func WithEntrypointDirs(dirs ...string) EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.dirs = append(o.dirs, dirs...)
+	}
+}
+
+// WithEntrypointDryRun logs the resolved path and arguments of a tool
+// instead of chaining to it. Execution continues afterwards.
+// Use it to debug the entrypoint decisions of an image.
+func WithEntrypointDryRun() EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithEntrypointExec replaces the func used to chain to a tool,
+// defaults to syscall.Exec. It is mainly useful for testing.
+func WithEntrypointExec(exec func(argv0 string, argv []string, envv []string) error) EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.exec = exec
+	}
+}
+
+// WithEntrypointErrorHandler passes errors of looking up or chaining to
+// a tool to handler instead of panicking. Execution continues after
+// handler returns.
+func WithEntrypointErrorHandler(handler func(err error)) EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.errorHandler = handler
+	}
+}
+
+// WithEntrypointEnv replaces the environment passed to the tool by env
+// in the form "key=value", defaults to syscall.Environ().
+func WithEntrypointEnv(env []string) EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.env = slices.Clone(env)
+	}
+}
+
+// WithEntrypointEnvFilter only passes entries "key=value" of the environment
+// to the tool for which filter returns true. It can be given multiple
+// times, entries must pass all filters.
+func WithEntrypointEnvFilter(filter func(entry string) bool) EntrypointOption {
+	return func(o *entrypointOptions) {
+		o.envFilters = append(o.envFilters, filter)
+	}
+}
+
+// ContainerEntrypointSecure works like [ContainerEntrypoint] but applies
+// explicit allow rules given by opts to harden images against $PATH injection.
+// Rejected tools are logged and execution continues without chaining.
+//
+// Example usage:
+//   - fx.Invoke(stdfx.ContainerEntrypointSecure(
+//     stdfx.WithEntrypointTools("*"),
+//     stdfx.WithEntrypointDirs("/bin", "/usr/bin"),
+//     ))
+//   - fx.Invoke(stdfx.ContainerEntrypointSecure(
+//     stdfx.WithEntrypointTools("/bin/sh"),
+//     stdfx.WithEntrypointAbsolutePaths(),
+//     ))
+func ContainerEntrypointSecure(opts ...EntrypointOption) func(log *slog.Logger) {
+	return ContainerEntrypointWithOptions(opts...)
+}
+
+// ContainerEntrypointWithOptions works like [ContainerEntrypoint] using opts.
+// Besides the allow rules of [ContainerEntrypointSecure] it supports
+// a dry-run, replacing the exec func and handling errors instead of panicking.
+//
+// Example usage:
+//   - fx.Invoke(stdfx.ContainerEntrypointWithOptions(
+//     stdfx.WithEntrypointTools("sh"),
+//     stdfx.WithEntrypointDryRun(),
+//     ))
+//   - fx.Invoke(stdfx.ContainerEntrypointWithOptions(
+//     stdfx.WithEntrypointErrorHandler(func(err error) { ... }),
+//     ))
+func ContainerEntrypointWithOptions(opts ...EntrypointOption) func(log *slog.Logger) {
+	// apply any given opts
+	eOpts := &entrypointOptions{
+		exec: syscall.Exec,
+		errorHandler: func(err error) {
+			panic(err)
+		},
+	}
+	for _, option := range opts {
+		option(eOpts)
+	}
+
 	// use default tools if nothing was provided
-	if len(tools) == 0 {
-		tools = ContainerEntrypointDefaultTools
+	if len(eOpts.tools) == 0 {
+		eOpts.tools = ContainerEntrypointDefaultTools
 	}
 
 	// return constructor
-	return func() {
+	return func(log *slog.Logger) {
+		log = log.With(slog.String("context", "entrypoint"))
 		if len(os.Args) < 2 {
 			// only care when atleast one argument was given to cli
+			log.Debug("no arguments given, continuing")
+			return
+		}
+
+		tools, err := entrypointTools(eOpts.tools)
+		if err != nil {
+			eOpts.errorHandler(err)
 			return
 		}
 
@@ -64,22 +223,126 @@ func ContainerEntrypoint(tools ...string) func() {
 		switch {
 		case os.Args[1] == filepath.Base(os.Args[0]):
 			// First argument is the same as binary name -> remove it, continue
+			log.Debug("first argument is the binary name, shifting it out",
+				slog.String("binary", os.Args[1]))
 			os.Args = append(os.Args[0:0], os.Args[1:]...)
 
 		case wildcardTool || slices.Contains(tools, os.Args[1]):
 			// Chain to the first argument given by looking it up in $PATH.
-			path, err := exec.LookPath(os.Args[1])
-			if err != nil && wildcardTool {
+			path, err := resolveEntrypointTool(os.Args[1], eOpts)
+			if errors.Is(err, ErrEntrypointRejected) {
+				log.Warn("rejected chaining to tool, continuing",
+					slog.String("tool", os.Args[1]),
+					slog.Any("error", err))
+				break
+			} else if err != nil && wildcardTool {
 				// wildcard tool is allowed, so the failing lookup might be
 				// caused by first argument not being any tool, continue
+				log.Debug("first argument is no tool in $PATH, continuing",
+					slog.String("argument", os.Args[1]))
 				break
 			} else if err != nil {
-				panic(err)
+				eOpts.errorHandler(err)
+				break
+			}
+			if eOpts.dryRun {
+				log.Info("dry-run, not chaining to tool",
+					slog.String("tool", os.Args[1]),
+					slog.String("path", path),
+					slog.Any("args", os.Args[2:]))
+				break
 			}
-			err = syscall.Exec(path, os.Args[1:], syscall.Environ())
+			log.Debug("chaining to tool",
+				slog.String("tool", os.Args[1]),
+				slog.String("path", path),
+				slog.Any("args", os.Args[2:]))
+			err = eOpts.exec(path, os.Args[1:], entrypointEnv(eOpts))
 			if err != nil {
-				panic(err)
+				eOpts.errorHandler(err)
 			}
+
+		default:
+			log.Debug("first argument is no allowed tool, continuing",
+				slog.String("argument", os.Args[1]),
+				slog.Any("tools", tools))
 		}
 	}
 }
+
+// resolveEntrypointTool returns the path of tool if it passes the allow
+// rules of opts, an error wrapping [ErrEntrypointRejected] if not,
+// or any other error if the lookup fails.
+func resolveEntrypointTool(tool string, opts *entrypointOptions) (string, error) {
+	if opts.absolutePaths && !filepath.IsAbs(tool) {
+		return "", fmt.Errorf("%w: %q is no absolute path", ErrEntrypointRejected, tool)
+	}
+
+	path, err := exec.LookPath(tool)
+	if err != nil {
+		return "", err
+	}
+	if len(opts.dirs) == 0 {
+		return path, nil
+	}
+
+	// compare directories after resolving symlinks to prevent escapes
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	for _, dir := range opts.dirs {
+		if evaluated, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = evaluated
+		}
+		if filepath.Dir(resolved) == filepath.Clean(dir) {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %q resolves to %q outside of %s",
+		ErrEntrypointRejected, tool, resolved, strings.Join(opts.dirs, ", "))
+}
+
+// entrypointEnv returns the environment passed to tools using opts
+func entrypointEnv(opts *entrypointOptions) []string {
+	env := opts.env
+	if env == nil {
+		env = syscall.Environ()
+	}
+
+	return slices.DeleteFunc(slices.Clone(env), func(entry string) bool {
+		for _, filter := range opts.envFilters {
+			if !filter(entry) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// entrypointTools returns tools adjusted by [ContainerEntrypointToolsEnv]
+// and [ContainerEntrypointWildcardEnv] or error.
+func entrypointTools(tools []string) ([]string, error) {
+	if env, ok := os.LookupEnv(ContainerEntrypointToolsEnv); ok {
+		tools = []string{}
+		for _, tool := range strings.Split(env, ",") {
+			if tool = strings.TrimSpace(tool); len(tool) > 0 {
+				tools = append(tools, tool)
+			}
+		}
+	}
+
+	if env, ok := os.LookupEnv(ContainerEntrypointWildcardEnv); ok {
+		wildcard, err := strconv.ParseBool(env)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", ContainerEntrypointWildcardEnv, err)
+		}
+		if !wildcard {
+			tools = slices.DeleteFunc(slices.Clone(tools), func(tool string) bool {
+				return tool == "*"
+			})
+		}
+	}
+
+	return tools, nil
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntrypointTools(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "compile time default",
+			expected: []string{"sh", "*"},
+		},
+		{
+			name:     "env override",
+			env:      map[string]string{ContainerEntrypointToolsEnv: "bash, whoami,"},
+			expected: []string{"bash", "whoami"},
+		},
+		{
+			name:     "env disables tools",
+			env:      map[string]string{ContainerEntrypointToolsEnv: ""},
+			expected: []string{},
+		},
+		{
+			name:     "wildcard disabled",
+			env:      map[string]string{ContainerEntrypointWildcardEnv: "false"},
+			expected: []string{"sh"},
+		},
+		{
+			name: "wildcard disabled for env override",
+			env: map[string]string{
+				ContainerEntrypointToolsEnv:    "*,sh",
+				ContainerEntrypointWildcardEnv: "0",
+			},
+			expected: []string{"sh"},
+		},
+		{
+			name: "invalid wildcard value",
+			env:  map[string]string{ContainerEntrypointWildcardEnv: "maybe"},
+			err:  "invalid " + ContainerEntrypointWildcardEnv,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{ContainerEntrypointToolsEnv, ContainerEntrypointWildcardEnv} {
+				t.Setenv(key, "")
+				require.NoError(t, os.Unsetenv(key))
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			tools, err := entrypointTools([]string{"sh", "*"})
+			if len(tt.err) > 0 {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tools)
+		})
+	}
+}
+
+func TestContainerEntrypointLogging(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	tests := []struct {
+		name string
+		args []string
+		log  string
+		rest []string
+	}{
+		{
+			name: "shift binary name",
+			args: []string{"/bin/app", "app", "serve"},
+			log:  "first argument is the binary name",
+			rest: []string{"app", "serve"},
+		},
+		{
+			name: "fall through",
+			args: []string{"/bin/app", "serve"},
+			log:  "first argument is no allowed tool",
+			rest: []string{"/bin/app", "serve"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(ContainerEntrypointToolsEnv, "sh")
+			out := &bytes.Buffer{}
+			log := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			os.Args = slices.Clone(tt.args)
+			ContainerEntrypoint()(log)
+
+			assert.Contains(t, out.String(), tt.log)
+			assert.Equal(t, tt.rest, os.Args)
+		})
+	}
+}
+
+func TestResolveEntrypointTool(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found in $PATH")
+	}
+	resolved, err := filepath.EvalSymlinks(sh)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		tool     string
+		opts     []EntrypointOption
+		rejected bool
+	}{
+		{name: "unrestricted", tool: "sh"},
+		{name: "absolute required", tool: "sh", opts: []EntrypointOption{WithEntrypointAbsolutePaths()}, rejected: true},
+		{name: "absolute given", tool: sh, opts: []EntrypointOption{WithEntrypointAbsolutePaths()}},
+		{name: "allowed dir", tool: "sh", opts: []EntrypointOption{WithEntrypointDirs(filepath.Dir(resolved))}},
+		{name: "foreign dir", tool: "sh", opts: []EntrypointOption{WithEntrypointDirs(t.TempDir())}, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &entrypointOptions{}
+			for _, option := range tt.opts {
+				option(opts)
+			}
+
+			path, err := resolveEntrypointTool(tt.tool, opts)
+			if tt.rejected {
+				assert.ErrorIs(t, err, ErrEntrypointRejected)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, path)
+		})
+	}
+}
+
+func TestContainerEntrypointWithOptions(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found in $PATH")
+	}
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	t.Setenv(ContainerEntrypointToolsEnv, "sh,stdfx-missing-tool")
+
+	var (
+		path string
+		argv []string
+	)
+	fakeExec := func(argv0 string, args []string, _ []string) error {
+		path, argv = argv0, args
+		return nil
+	}
+
+	// exec
+	os.Args = []string{"/bin/app", "sh", "-c", "true"}
+	ContainerEntrypointWithOptions(WithEntrypointExec(fakeExec))(slog.New(slog.DiscardHandler))
+	assert.Equal(t, sh, path)
+	assert.Equal(t, []string{"sh", "-c", "true"}, argv)
+
+	// dry-run
+	path, argv = "", nil
+	out := &bytes.Buffer{}
+	ContainerEntrypointWithOptions(
+		WithEntrypointExec(fakeExec),
+		WithEntrypointDryRun(),
+	)(slog.New(slog.NewTextHandler(out, nil)))
+	assert.Empty(t, path)
+	assert.Nil(t, argv)
+	assert.Contains(t, out.String(), "dry-run")
+	assert.Contains(t, out.String(), "path="+sh)
+
+	// error handler
+	var handled error
+	os.Args = []string{"/bin/app", "stdfx-missing-tool"}
+	assert.NotPanics(t, func() {
+		ContainerEntrypointWithOptions(
+			WithEntrypointExec(fakeExec),
+			WithEntrypointErrorHandler(func(err error) { handled = err }),
+		)(slog.New(slog.DiscardHandler))
+	})
+	assert.ErrorIs(t, handled, exec.ErrNotFound)
+	assert.Empty(t, path)
+}
+
+func TestContainerEntrypointEnv(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found in $PATH")
+	}
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+	t.Setenv(ContainerEntrypointToolsEnv, "sh")
+	t.Setenv("STDFX_TEST_SECRET", "hidden")
+
+	// run returns the env passed to the tool using opts
+	run := func(opts ...EntrypointOption) []string {
+		var env []string
+		os.Args = []string{"/bin/app", "sh"}
+		ContainerEntrypointWithOptions(append(opts,
+			WithEntrypointExec(func(_ string, _ []string, envv []string) error {
+				env = envv
+				return nil
+			}),
+		)...)(slog.New(slog.DiscardHandler))
+		return env
+	}
+
+	// default passes through the environment
+	assert.Contains(t, run(), "STDFX_TEST_SECRET=hidden")
+
+	// filter
+	noSecrets := func(entry string) bool {
+		return !strings.HasPrefix(entry, "STDFX_TEST_SECRET=")
+	}
+	env := run(WithEntrypointEnvFilter(noSecrets))
+	assert.NotContains(t, env, "STDFX_TEST_SECRET=hidden")
+	assert.NotEmpty(t, env)
+
+	// explicit env with filter
+	assert.Equal(t, []string{"A=1", "B=2"}, run(
+		WithEntrypointEnv([]string{"A=1", "STDFX_TEST_SECRET=x", "B=2"}),
+		WithEntrypointEnvFilter(noSecrets),
+	))
+}
@@ -17,7 +17,7 @@ limitations under the License.
 package main
 
 import (
-	"sync"
+	"log/slog"
 
 	"go.uber.org/fx"
 	"k8s.io/utils/diff"
@@ -26,7 +26,6 @@ import (
 	"github.com/choopm/stdfx/configfx"
 	"github.com/choopm/stdfx/examples/webserver"
 	"github.com/choopm/stdfx/loggingfx/zerologfx"
-	"github.com/fsnotify/fsnotify"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -44,6 +43,7 @@ func main() {
 
 		// viper configuration
 		fx.Provide(stdfx.ConfigFile[webserver.Config]("webserver")),
+		fx.Invoke(configfx.CloseOnStop[webserver.Config]), // stop watching on exit
 
 		// cobra commands
 		fx.Provide(
@@ -63,6 +63,7 @@ func main() {
 // serverCommand returns a *cobra.Command to start the server from a ConfigProvider
 func serverCommand(
 	configProvider configfx.Provider[webserver.Config],
+	slogger *slog.Logger,
 ) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "server",
@@ -81,70 +82,45 @@ func serverCommand(
 			}
 			log.Logger = *logger
 
-			var server *webserver.Server
-
-			// build config options
+			// re-create config with opts (overlays, config change)
 			opts := []configfx.ConfigOption{
 				configfx.WithOverlays(cfg.Config.Overlays...),
 			}
+			config := configfx.NewAtomic[webserver.Config](nil)
 			if cfg.Config.HotReload {
-				cfgSwap := sync.Mutex{}
-
-				// callback for hot-reloading of config
-				opts = append(opts, configfx.WithOnConfigChange(func(in fsnotify.Event) {
-					// we only care for config writes
-					if in.Op != fsnotify.Write {
-						return
-					}
-
-					// synchronize config swapping
-					cfgSwap.Lock()
-					defer cfgSwap.Unlock()
-
-					log.Debug().
-						Msg("config file has changed on disk - reloading config")
-
-					// re-create config with opts (overlays, config change)
-					newcfg, err := configProvider.Config(opts...)
-					if err != nil {
-						log.Error().Err(err).
-							Msg("new config file can't be parsed")
-						return
-					}
-					// check config
-					err = newcfg.Validate()
-					if err != nil {
-						log.Error().Err(err).
-							Msg("new config file has errors")
-						return
-					}
-
-					changelog := diff.ObjectReflectDiff(cfg, newcfg)
-					*cfg = *newcfg // this replaces the config
-
-					log.Info().
-						Msgf("updated config, changelog: %s", changelog)
-
-					log.Info().Msg("reconfiguring server...")
-					err = server.Reconfigure(cfg)
-					if err != nil {
-						log.Panic().Err(err).Msg("failed to reconfiguring server")
-						return
-					}
-				}))
+				// reload the config on changes, invalid ones are skipped
+				config, err = configfx.WatchAtomic(configProvider, slogger, opts...)
+			} else {
+				cfg, err = configProvider.Config(opts...)
+				config.Store(cfg)
 			}
-			// re-create config with opts (overlays, config change)
-			cfg, err = configProvider.Config(opts...)
 			if err != nil {
 				return err
 			}
+			updates := config.Subscribe(cmd.Context())
 
 			// create server instance
-			server, err = webserver.NewServer(cfg, logger)
+			server, err := webserver.NewServer(config.Load(), logger)
 			if err != nil {
 				return err
 			}
 
+			// reconfigure the server on config changes
+			go func() {
+				current := config.Load()
+				for newcfg := range updates {
+					changelog := diff.ObjectReflectDiff(current, newcfg)
+					current = newcfg
+					log.Info().
+						Msgf("updated config, changelog: %s", changelog)
+
+					log.Info().Msg("reconfiguring server...")
+					if err := server.Reconfigure(newcfg); err != nil {
+						log.Error().Err(err).Msg("failed to reconfigure server")
+					}
+				}
+			}()
+
 			// start server using context
 			return server.Start(cmd.Context())
 		},
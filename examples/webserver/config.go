@@ -18,6 +18,7 @@ package webserver
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/choopm/stdfx/configfx"
 	"github.com/choopm/stdfx/loggingfx"
@@ -64,6 +65,10 @@ type WebserverConfig struct {
 
 	// Port is the listening port to use when starting a server
 	Port int `mapstructure:"port" default:"8080"`
+
+	// ShutdownTimeout is the grace period for active connections
+	// to finish when stopping the server before closing them
+	ShutdownTimeout time.Duration `mapstructure:"shutdownTimeout" default:"10s"`
 }
 
 // Validate validates the HTTPConfig
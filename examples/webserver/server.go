@@ -23,16 +23,18 @@ import (
 	"net"
 	"net/http"
 	"strconv"
+	"sync/atomic"
 
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/configfx"
 	"github.com/rs/zerolog"
-	"golang.org/x/sync/errgroup"
 )
 
 // Server state struct
 type Server struct {
-	config *Config
+	config *configfx.Atomic[Config]
 	log    *zerolog.Logger
-	mux    *http.ServeMux
+	mux    atomic.Pointer[http.ServeMux]
 }
 
 // NewServer creates a new *Server instance using a provided config
@@ -52,51 +54,39 @@ func NewServer(config *Config, logger *zerolog.Logger) (*Server, error) {
 	}
 
 	s := &Server{
-		config: config,
+		config: configfx.NewAtomic(config),
 		log:    logger,
-		mux:    http.NewServeMux(),
 	}
+	s.mux.Store(http.NewServeMux())
 
 	return s, nil
 }
 
 // Start starts the server using ctx
 func (s *Server) Start(ctx context.Context) error {
+	config := s.config.Load()
 	s.log.Trace().
-		Interface("config", s.config).
+		Interface("config", config).
 		Msg("initializing server")
 
-	if err := s.Reconfigure(s.config); err != nil {
+	if err := s.Reconfigure(config); err != nil {
 		return err
 	}
 
 	s.log.Trace().
 		Msg("starting server")
-	g, ctx := errgroup.WithContext(ctx)
 
 	// build and start webserver
-	addr := net.JoinHostPort(s.config.Webserver.Host,
-		strconv.Itoa(s.config.Webserver.Port),
+	addr := net.JoinHostPort(config.Webserver.Host,
+		strconv.Itoa(config.Webserver.Port),
 	)
 	server := &http.Server{Addr: addr, Handler: s}
-	// shutdown hook, registered before starting
-	context.AfterFunc(ctx, func() {
-		_ = server.Close()
-	})
-	g.Go(func() error {
-		err := server.ListenAndServe()
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			return err
-		}
-
-		return nil
-	})
-
-	// wait for started tasks
+
+	// serve until ctx is cancelled, draining active connections
 	s.log.Info().
 		Str("addr", addr).
 		Msg("server is running")
-	if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+	if err := stdfx.ListenAndServeContext(ctx, server, config.Webserver.ShutdownTimeout); err != nil {
 		return err
 	}
 
@@ -105,24 +95,33 @@ func (s *Server) Start(ctx context.Context) error {
 	return nil
 }
 
-// Reconfigure restarts the server using a new config or error
+// Reconfigure replaces the routes of the server using a new config or error.
+// Webserver address changes require a restart.
 func (s *Server) Reconfigure(cfg *Config) error {
+	if cfg == nil {
+		return errors.New("missing config")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config: %s", err)
+	}
+
 	mux := http.NewServeMux()
 
 	// register routes
-	for _, route := range s.config.Routes {
+	for _, route := range cfg.Routes {
 		mux.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprint(w, route.Content)
 		})
 	}
 
-	// replace server mux
-	s.mux = mux
+	// replace config and server mux
+	s.config.Store(cfg)
+	s.mux.Store(mux)
 
 	return nil
 }
 
 // ServeHTTP implements http.Handler
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	s.mux.ServeHTTP(w, r)
+	s.mux.Load().ServeHTTP(w, r)
 }
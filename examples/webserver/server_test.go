@@ -0,0 +1,69 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webserver_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/choopm/stdfx/examples/webserver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerReconfigure(t *testing.T) {
+	// config returns a valid config serving routes
+	config := func(routes ...*webserver.Route) *webserver.Config {
+		return &webserver.Config{
+			Webserver: webserver.WebserverConfig{Host: "localhost", Port: 8080},
+			Routes:    routes,
+		}
+	}
+
+	server, err := webserver.NewServer(config(
+		&webserver.Route{Path: "/kept", Content: "kept"},
+		&webserver.Route{Path: "/removed", Content: "removed"},
+	), nil)
+	require.NoError(t, err)
+
+	// get requests path and returns the status code and body
+	get := func(path string) (int, string) {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	require.NoError(t, server.Reconfigure(config(
+		&webserver.Route{Path: "/kept", Content: "changed"},
+		&webserver.Route{Path: "/added", Content: "added"},
+	)))
+
+	for path, want := range map[string]string{"/kept": "changed", "/added": "added"} {
+		code, body := get(path)
+		assert.Equal(t, http.StatusOK, code, path)
+		assert.Equal(t, want, body, path)
+	}
+	code, _ := get("/removed")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// invalid configs keep the current routes
+	assert.Error(t, server.Reconfigure(&webserver.Config{}))
+	code, body := get("/added")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "added", body)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package globals
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	// rootFlagsMutex guards RootFlags, RootPreRuns and rootFlagsTaken
+	// against concurrent definitions
+	rootFlagsMutex sync.Mutex
+
+	// rootFlagsTaken is set once a root command took RootFlags and
+	// RootPreRuns, the next definition starts over using empty ones
+	rootFlagsTaken bool
+
+	// rootFlagValues stores the values of flags defined by RootString
+	// and RootBool on RootFlags
+	rootFlagValues = map[*pflag.Flag]any{}
+)
+
+// RootString defines a string flag on RootFlags and returns its value.
+// Repeated calls using the same name return the same value, which allows
+// several config sources to share a flag. The first definition is kept,
+// later shorthands, default values and usages are ignored.
+// It panics if the flag was defined using a different type before.
+func RootString(name, shorthand, value, usage string) *string {
+	return rootFlag(name, func() *string {
+		return RootFlags.StringP(name, shorthand, value, usage)
+	})
+}
+
+// RootBool defines a bool flag on RootFlags and returns its value.
+// Repeated calls using the same name return the same value, which allows
+// several config sources to share a flag. The first definition is kept,
+// later shorthands, default values and usages are ignored.
+// It panics if the flag was defined using a different type before.
+func RootBool(name, shorthand string, value bool, usage string) *bool {
+	return rootFlag(name, func() *bool {
+		return RootFlags.BoolP(name, shorthand, value, usage)
+	})
+}
+
+// TakeRootFlags returns RootFlags and RootPreRuns to be added to a new
+// root command. Flags and pre-runs defined afterwards start over using
+// an empty RootFlags and RootPreRuns for the next root command, which
+// allows building an app multiple times per process.
+// It is called by stdfx when building the root command.
+func TakeRootFlags() (*pflag.FlagSet, []func(cmd *cobra.Command, args []string)) {
+	rootFlagsMutex.Lock()
+	defer rootFlagsMutex.Unlock()
+
+	rootFlagsTaken = true
+
+	return RootFlags, RootPreRuns
+}
+
+// ResetRootFlags starts over using an empty RootFlags and RootPreRuns
+// on the next flag definition, as if a root command took them.
+// Use it in tests constructing config sources of different apps.
+func ResetRootFlags() {
+	TakeRootFlags()
+}
+
+// rootFlag returns the value of flag name defined on RootFlags or
+// defines it using define. A flag defined already is kept as is,
+// it panics if the flag is defined already using a different type.
+func rootFlag[V any](name string, define func() *V) *V {
+	rootFlagsMutex.Lock()
+	defer rootFlagsMutex.Unlock()
+
+	if rootFlagsTaken {
+		RootFlags = pflag.NewFlagSet("root", pflag.ContinueOnError)
+		RootPreRuns = nil
+		rootFlagValues = map[*pflag.Flag]any{}
+		rootFlagsTaken = false
+	}
+
+	flag := RootFlags.Lookup(name)
+	if flag == nil {
+		v := define()
+		rootFlagValues[RootFlags.Lookup(name)] = v
+		return v
+	}
+
+	v, ok := rootFlagValues[flag].(*V)
+	if !ok {
+		panic(fmt.Sprintf("root flag %q redefined using a different type", name))
+	}
+
+	return v
+}
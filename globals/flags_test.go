@@ -0,0 +1,72 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package globals_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx/globals"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRootString(t *testing.T) {
+	globals.ResetRootFlags()
+	t.Cleanup(globals.ResetRootFlags)
+
+	// identical definitions share the value
+	first := globals.RootString("test-path", "", "/etc", "test path")
+	second := globals.RootString("test-path", "", "/etc", "test path")
+	assert.Same(t, first, second)
+
+	// the first definition is kept
+	third := globals.RootString("test-path", "p", "/opt", "other path")
+	assert.Same(t, first, third)
+	flag := globals.RootFlags.Lookup("test-path")
+	assert.Equal(t, "", flag.Shorthand)
+	assert.Equal(t, "/etc", flag.DefValue)
+	assert.Equal(t, "test path", flag.Usage)
+
+	// definitions using a different type fail
+	assert.Panics(t, func() {
+		globals.RootBool("test-path", "", false, "test path")
+	})
+
+	// parsed values don't leak into the flags of the next root command
+	flags, _ := globals.TakeRootFlags()
+	require.NoError(t, flags.Parse([]string{"--test-path", "/var"}))
+	assert.Equal(t, "/var", *first)
+
+	next := globals.RootString("test-path", "", "/opt", "test path")
+	assert.Equal(t, "/opt", *next)
+	assert.Equal(t, "/var", *first)
+	assert.NotSame(t, flags, globals.RootFlags)
+}
+
+func TestTakeRootFlagsPreRuns(t *testing.T) {
+	globals.ResetRootFlags()
+	t.Cleanup(globals.ResetRootFlags)
+
+	globals.RootBool("test-version", "", false, "test version")
+	globals.RootPreRuns = append(globals.RootPreRuns, nil)
+	_, preRuns := globals.TakeRootFlags()
+	assert.Len(t, preRuns, 1)
+
+	// pre-runs start over along with the flags
+	globals.RootBool("test-version", "", false, "test version")
+	assert.Empty(t, globals.RootPreRuns)
+}
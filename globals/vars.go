@@ -24,10 +24,12 @@ import (
 var (
 	// RootFlags stores the global flags to be used in newRootCommand.
 	// These flags might be filled by configfx.Source[T] implementations.
+	// Define them using RootString and RootBool, see TakeRootFlags.
 	RootFlags = pflag.NewFlagSet("root", pflag.ContinueOnError)
 
 	// RootPreRuns will be added to root commands PreRun.
 	// Use this to inject any code precommand start.
+	// They are taken by the root command along with RootFlags.
 	RootPreRuns []func(cmd *cobra.Command, args []string)
 
 	// RootFlagConfigPathDefault is the default value for config-path.
@@ -0,0 +1,160 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthfx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"runtime"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/choopm/stdfx/internal/httpserver"
+	"github.com/creasty/defaults"
+	"github.com/earthboundkid/versioninfo/v2"
+	"go.uber.org/fx"
+)
+
+// Module provides a *HealthRegistry and serves its probes
+// on the address found in [Config].
+// Usage example:
+//
+//	healthfx.Module,
+//	fx.Invoke(func(registry *healthfx.HealthRegistry, db *sql.DB) {
+//		registry.Register("database", db.PingContext)
+//	}),
+//
+// Replace the default [Config] to change the address:
+//
+//	fx.Replace(healthfx.Config{Host: "127.0.0.1", Port: 9091, Timeout: 5 * time.Second}),
+var Module = fx.Module(
+	"health",
+	fx.Provide(
+		NewHealthRegistry,
+		DefaultConfig,
+	),
+	fx.Invoke(Serve),
+)
+
+// Config defines the configuration of the health endpoints
+type Config struct {
+	// Host is the listening host of the health server
+	Host string `mapstructure:"host" default:"0.0.0.0"`
+
+	// Port is the listening port of the health server
+	Port int `mapstructure:"port" default:"8081"`
+
+	// Timeout limits the time for running all readiness probes
+	Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+}
+
+// Address returns the listening address of c
+func (c Config) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// DefaultConfig returns the default health configuration
+func DefaultConfig() (Config, error) {
+	config := Config{}
+	if err := defaults.Set(&config); err != nil {
+		return config, fmt.Errorf("setting defaults: %s", err)
+	}
+
+	return config, nil
+}
+
+// Serve starts a http server on config.Address() serving
+// /healthz and /readyz using registry during the fx lifecycle.
+// Serving errors after the start are logged and shut down the app.
+func Serve(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	config Config,
+	registry *HealthRegistry,
+	log *slog.Logger,
+) {
+	server := &http.Server{
+		Addr:    config.Address(),
+		Handler: Handler(registry, config.Timeout),
+	}
+
+	lc.Append(httpserver.Hook("health server", server, 0, shutdowner, log))
+}
+
+// Handler returns a http.Handler serving /healthz and /readyz.
+// /healthz always responds 200 including build information.
+// /readyz responds 200 when all probes of registry pass within timeout
+// and 503 with the failing probe names otherwise.
+// A timeout of 0 does not limit the probes.
+func Handler(registry *HealthRegistry, timeout time.Duration) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status":      "ok",
+			"version":     versioninfo.Short(),
+			"revision":    versioninfo.Revision,
+			"last-commit": versioninfo.LastCommit,
+			"dirty-build": versioninfo.DirtyBuild,
+			"go-version":  runtime.Version(),
+			"go-os":       runtime.GOOS,
+			"go-arch":     runtime.GOARCH,
+		})
+	})
+
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		failed := registry.Check(ctx)
+		if len(failed) == 0 {
+			writeJSON(w, http.StatusOK, map[string]any{
+				"status": "ok",
+			})
+			return
+		}
+
+		errs := make(map[string]string, len(failed))
+		for name, err := range failed {
+			errs[name] = err.Error()
+		}
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{
+			"status": "unavailable",
+			"failed": slices.Sorted(maps.Keys(failed)),
+			"errors": errs,
+		})
+	})
+
+	return mux
+}
+
+// writeJSON responds using status and v encoded as json
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
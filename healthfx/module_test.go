@@ -0,0 +1,86 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthfx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/healthfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// get requests path from handler and decodes the json response
+func get(t *testing.T, handler http.Handler, path string) (int, map[string]any) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	body := map[string]any{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+
+	return rec.Code, body
+}
+
+func TestHandlerHealthy(t *testing.T) {
+	registry := healthfx.NewHealthRegistry()
+	registry.Register("first", func(context.Context) error { return nil })
+	registry.Register("second", func(context.Context) error { return nil })
+	handler := healthfx.Handler(registry, time.Second)
+
+	code, body := get(t, handler, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+
+	code, body = get(t, handler, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, runtime.Version(), body["go-version"])
+	assert.Contains(t, body, "version")
+}
+
+func TestHandlerFailing(t *testing.T) {
+	registry := healthfx.NewHealthRegistry()
+	registry.Register("first", func(context.Context) error { return nil })
+	registry.Register("database", func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	handler := healthfx.Handler(registry, time.Second)
+
+	code, body := get(t, handler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []any{"database"}, body["failed"])
+	assert.Equal(t, map[string]any{"database": "connection refused"}, body["errors"])
+
+	// liveness is not affected by readiness
+	code, _ = get(t, handler, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestDefaultConfig(t *testing.T) {
+	config, err := healthfx.DefaultConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:8081", config.Address())
+	assert.Equal(t, 5*time.Second, config.Timeout)
+}
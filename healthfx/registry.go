@@ -0,0 +1,65 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package healthfx
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+// Probe is a readiness check returning nil when ready
+type Probe func(ctx context.Context) error
+
+// HealthRegistry stores readiness probes of components
+type HealthRegistry struct {
+	probes map[string]Probe
+	mutex  sync.RWMutex
+}
+
+// NewHealthRegistry returns an empty *HealthRegistry
+func NewHealthRegistry() *HealthRegistry {
+	return &HealthRegistry{
+		probes: map[string]Probe{},
+	}
+}
+
+// Register adds a readiness probe by name.
+// Registering the same name again replaces the previous probe.
+func (r *HealthRegistry) Register(name string, probe func(context.Context) error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.probes[name] = probe
+}
+
+// Check runs all probes using ctx and returns the names
+// of all failing probes mapped to their error.
+func (r *HealthRegistry) Check(ctx context.Context) map[string]error {
+	r.mutex.RLock()
+	probes := maps.Clone(r.probes)
+	r.mutex.RUnlock()
+
+	failed := map[string]error{}
+	for name, probe := range probes {
+		if err := probe(ctx); err != nil {
+			failed[name] = err
+		}
+	}
+
+	return failed
+}
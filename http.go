@@ -0,0 +1,115 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/choopm/stdfx/internal/httpserver"
+	"go.uber.org/fx"
+)
+
+const (
+	// DefaultHTTPServerShutdownTimeout limits the time to wait for
+	// active connections during graceful shutdown of [HTTPServer]
+	DefaultHTTPServerShutdownTimeout = 10 * time.Second
+)
+
+// HTTPServer returns a *http.Server constructor serving handler on addr
+// during the fx lifecycle.
+// The server listens when the fx.App starts, address errors like a port
+// in use fail the start. Serving errors afterwards are logged and shut
+// down the app using exit code 1.
+// It is shutdown gracefully when the fx.App stops, waiting up to
+// [DefaultHTTPServerShutdownTimeout] for active connections before
+// closing them.
+// Usage example:
+//
+//	fx.Provide(stdfx.HTTPServer(":8080", mux)),
+//	fx.Invoke(func(*http.Server) {}),
+func HTTPServer(addr string, handler http.Handler) func(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	log *slog.Logger,
+) *http.Server {
+	return func(
+		lc fx.Lifecycle,
+		shutdowner fx.Shutdowner,
+		log *slog.Logger,
+	) *http.Server {
+		server := &http.Server{Addr: addr, Handler: handler}
+		lc.Append(httpserver.Hook("http server", server,
+			DefaultHTTPServerShutdownTimeout, shutdowner, log))
+
+		return server
+	}
+}
+
+// ListenAndServeContext listens on server.Addr and serves until ctx is
+// cancelled, see [ServeContext].
+// Usage example:
+//
+//	server := &http.Server{Addr: ":8080", Handler: mux}
+//	return stdfx.ListenAndServeContext(cmd.Context(), server, 10*time.Second)
+func ListenAndServeContext(ctx context.Context, server *http.Server, grace time.Duration) error {
+	ln, err := httpserver.Listen(server)
+	if err != nil {
+		return fmt.Errorf("http server: %w", err)
+	}
+
+	return ServeContext(ctx, server, ln, grace)
+}
+
+// ServeContext serves connections of ln using server until ctx is cancelled.
+// On cancellation it stops accepting new connections and waits up to grace
+// for active connections to finish, closing the remaining ones afterwards.
+// It returns once all connections are drained or closed, the error wraps
+// context.DeadlineExceeded if connections had to be closed.
+// Serving errors are returned immediately.
+func ServeContext(ctx context.Context, server *http.Server, ln net.Listener, grace time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return fmt.Errorf("http server %s: %w", ln.Addr(), err)
+
+	case <-ctx.Done():
+	}
+
+	// drain active connections
+	drainCtx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	err := httpserver.Shutdown(drainCtx, server)
+	<-errCh
+	if err != nil {
+		return fmt.Errorf("http server %s: %w", ln.Addr(), err)
+	}
+
+	return nil
+}
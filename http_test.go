@@ -0,0 +1,178 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+// newHTTPServerApp returns a fx.App serving "hello" on addr
+func newHTTPServerApp(addr string) *fx.App {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	})
+
+	return fx.New(
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(stdfx.HTTPServer(addr, handler)),
+		fx.Invoke(func(*http.Server) {}),
+	)
+}
+
+func TestHTTPServer(t *testing.T) {
+	// find a free port
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	app := newHTTPServerApp(addr)
+	require.NoError(t, app.Start(context.Background()))
+
+	// server shall listen after start
+	res, err := http.Get("http://" + addr)
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, "hello", string(body))
+
+	// server shall stop cleanly
+	require.NoError(t, app.Stop(context.Background()))
+	_, err = http.Get("http://" + addr)
+	assert.Error(t, err)
+}
+
+func TestHTTPServerPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	// start shall fail since the port is taken
+	app := newHTTPServerApp(ln.Addr().String())
+	err = app.Start(context.Background())
+	assert.ErrorContains(t, err, "address already in use")
+}
+
+// slowHandler is a http.Handler responding "done" once release is closed.
+// started receives a value for every request being handled.
+type slowHandler struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+// ServeHTTP implements http.Handler
+func (h *slowHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.started <- struct{}{}
+	<-h.release
+	fmt.Fprint(w, "done")
+}
+
+// serveSlow serves a *slowHandler using stdfx.ServeContext and grace.
+// It returns the handler, the address and a channel receiving the result of
+// ServeContext and the result of a slow request once ctx is cancelled.
+func serveSlow(
+	t *testing.T,
+	ctx context.Context,
+	grace time.Duration,
+) (*slowHandler, string, <-chan error, <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+
+	handler := &slowHandler{
+		started: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	served := make(chan error, 1)
+	go func() {
+		served <- stdfx.ServeContext(ctx, &http.Server{Handler: handler}, ln, grace)
+	}()
+
+	// issue a slow request and wait for it to be handled
+	requested := make(chan error, 1)
+	go func() {
+		res, err := http.Get("http://" + addr)
+		if err != nil {
+			requested <- err
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err == nil && string(body) != "done" {
+			err = fmt.Errorf("unexpected body %q", body)
+		}
+		requested <- err
+	}()
+	<-handler.started
+
+	return handler, addr, served, requested
+}
+
+func TestServeContextDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler, addr, served, requested := serveSlow(t, ctx, 5*time.Second)
+
+	// start draining
+	cancel()
+
+	// new connections shall be refused while draining
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			return true
+		}
+		_ = conn.Close()
+		return false
+	}, 2*time.Second, 10*time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("ServeContext returned before draining: %v", err)
+	default:
+	}
+
+	// the active request shall complete
+	close(handler.release)
+	assert.NoError(t, <-requested)
+	assert.NoError(t, <-served)
+}
+
+func TestServeContextDrainTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler, _, served, requested := serveSlow(t, ctx, 50*time.Millisecond)
+	defer close(handler.release)
+
+	// the active request shall be closed after the grace period
+	cancel()
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+	assert.Error(t, <-requested)
+}
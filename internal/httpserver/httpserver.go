@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpserver serves a *http.Server during the fx lifecycle.
+// It is shared by stdfx, healthfx and metricsfx.
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// Listen listens on the address of server, ":http" if empty
+func Listen(server *http.Server) (net.Listener, error) {
+	addr := server.Addr
+	if len(addr) == 0 {
+		addr = ":http"
+	}
+
+	return net.Listen("tcp", addr)
+}
+
+// Shutdown shuts down server gracefully using ctx.
+// Connections still active when ctx is done are closed.
+func Shutdown(ctx context.Context, server *http.Server) error {
+	err := server.Shutdown(ctx)
+	if err != nil {
+		return errors.Join(err, server.Close())
+	}
+
+	return nil
+}
+
+// Hook returns a fx.Hook serving server named name during the fx lifecycle.
+// OnStart listens synchronously, address errors fail the start.
+// Serving errors afterwards are logged and shut down the app using
+// exit code 1. OnStop shuts down server gracefully, waiting up to
+// timeout for active connections before closing them.
+// A timeout of 0 waits as long as the fx stop timeout allows.
+func Hook(
+	name string,
+	server *http.Server,
+	timeout time.Duration,
+	shutdowner fx.Shutdowner,
+	log *slog.Logger,
+) fx.Hook {
+	return fx.Hook{
+		OnStart: func(_ context.Context) error {
+			ln, err := Listen(server)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+
+			go func() {
+				err := server.Serve(ln)
+				if err == nil || errors.Is(err, http.ErrServerClosed) {
+					return
+				}
+				log.Error(name+" failed", slog.Any("error", err))
+				shutdowner.Shutdown(fx.ExitCode(1)) // nolint:errcheck
+			}()
+
+			log.Debug(name+" is running",
+				slog.String("addr", ln.Addr().String()))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			return Shutdown(ctx, server)
+		},
+	}
+}
@@ -0,0 +1,250 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package lifecyclefx manages connections which are opened during
+// fx start, closed during fx stop and reported as health probes.
+package lifecyclefx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/choopm/stdfx/healthfx"
+	"go.uber.org/fx"
+)
+
+const (
+	// DefaultRetries is the number of retries after a failed open
+	DefaultRetries = 5
+
+	// DefaultBackoff is the initial wait time between retries
+	DefaultBackoff = 500 * time.Millisecond
+
+	// DefaultMaxBackoff limits the doubling wait time between retries
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// ErrNotConnected is returned when using a *Connection not being opened
+var ErrNotConnected = errors.New("not connected")
+
+// Pinger is implemented by connections which can check their health,
+// e.g. *sql.DB. It is used as health probe unless [WithProbe] is given.
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// options stores options for [Option] funcs
+type options[C io.Closer] struct {
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	probe      func(ctx context.Context, conn C) error
+}
+
+// Option is a func to adjust options of a *Connection[C]
+type Option[C io.Closer] func(*options[C])
+
+// WithRetries sets the number of retries after a failed open.
+// Defaults to [DefaultRetries].
+func WithRetries[C io.Closer](retries int) Option[C] {
+	return func(o *options[C]) {
+		o.retries = retries
+	}
+}
+
+// WithBackoff sets the initial and maximum wait time between retries.
+// The wait time doubles after each failed attempt.
+// Defaults to [DefaultBackoff] and [DefaultMaxBackoff].
+func WithBackoff[C io.Closer](initial, maximum time.Duration) Option[C] {
+	return func(o *options[C]) {
+		o.backoff = initial
+		o.maxBackoff = maximum
+	}
+}
+
+// WithProbe sets the health probe of the connection.
+// Defaults to [Pinger] if implemented by C.
+func WithProbe[C io.Closer](probe func(ctx context.Context, conn C) error) Option[C] {
+	return func(o *options[C]) {
+		o.probe = probe
+	}
+}
+
+// Connection holds a connection of type C opened by an opener func
+type Connection[C io.Closer] struct {
+	name string
+	open func(ctx context.Context) (C, error)
+	opts *options[C]
+	log  *slog.Logger
+
+	conn      C
+	connected bool
+	mutex     sync.RWMutex
+}
+
+// New returns a *Connection[C] named name which uses open to connect.
+// Use [Connection.Start] and [Connection.Stop] or [Provide] to manage it.
+func New[C io.Closer](
+	name string,
+	open func(ctx context.Context) (C, error),
+	log *slog.Logger,
+	opts ...Option[C],
+) *Connection[C] {
+	o := &options[C]{
+		retries:    DefaultRetries,
+		backoff:    DefaultBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &Connection[C]{
+		name: name,
+		open: open,
+		opts: o,
+		log:  log.With(slog.String("connection", name)),
+	}
+}
+
+// Params are the dependencies of [Provide]
+type Params struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Log       *slog.Logger
+
+	// Registry receives the health probe if provided
+	Registry *healthfx.HealthRegistry `optional:"true"`
+}
+
+// Provide returns a constructor of *Connection[C] which is opened
+// during fx start and closed during fx stop. If a *healthfx.HealthRegistry
+// is provided, the connection registers its health probe by name.
+// Usage example:
+//
+//	fx.Provide(lifecyclefx.Provide("database",
+//		func(ctx context.Context) (*sql.DB, error) {
+//			db, err := sql.Open("postgres", dsn)
+//			if err != nil {
+//				return nil, err
+//			}
+//			return db, db.PingContext(ctx)
+//		},
+//	)),
+//	fx.Invoke(func(db *lifecyclefx.Connection[*sql.DB]) {}),
+//
+// Like all fx constructors, it only runs when *Connection[C] is requested.
+func Provide[C io.Closer](
+	name string,
+	open func(ctx context.Context) (C, error),
+	opts ...Option[C],
+) func(Params) *Connection[C] {
+	return func(p Params) *Connection[C] {
+		conn := New(name, open, p.Log, opts...)
+		p.Lifecycle.Append(fx.StartStopHook(conn.Start, conn.Stop))
+		if p.Registry != nil {
+			p.Registry.Register(name, conn.Probe)
+		}
+
+		return conn
+	}
+}
+
+// Get returns the opened connection or [ErrNotConnected]
+func (c *Connection[C]) Get() (C, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if !c.connected {
+		var zero C
+		return zero, fmt.Errorf("%s: %w", c.name, ErrNotConnected)
+	}
+
+	return c.conn, nil
+}
+
+// Start opens the connection, retrying with backoff until
+// it succeeds, the retries are used up or ctx is done.
+func (c *Connection[C]) Start(ctx context.Context) error {
+	backoff := c.opts.backoff
+	for attempt := 0; ; attempt++ {
+		conn, err := c.open(ctx)
+		if err == nil {
+			c.mutex.Lock()
+			c.conn, c.connected = conn, true
+			c.mutex.Unlock()
+
+			c.log.Debug("connection opened", slog.Int("attempt", attempt+1))
+			return nil
+		}
+		if attempt >= c.opts.retries {
+			return fmt.Errorf("open %s: %s", c.name, err)
+		}
+
+		c.log.Warn("connection failed, retrying",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("backoff", backoff),
+			slog.Any("error", err))
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("open %s: %s: %s", c.name, ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+
+		backoff = min(2*backoff, c.opts.maxBackoff)
+	}
+}
+
+// Stop closes the connection if opened
+func (c *Connection[C]) Stop(_ context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.connected {
+		return nil
+	}
+	c.connected = false
+
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("close %s: %s", c.name, err)
+	}
+	c.log.Debug("connection closed")
+
+	return nil
+}
+
+// Probe is a [healthfx.Probe] reporting the health of the connection
+func (c *Connection[C]) Probe(ctx context.Context) error {
+	conn, err := c.Get()
+	if err != nil {
+		return err
+	}
+
+	if c.opts.probe != nil {
+		return c.opts.probe(ctx, conn)
+	}
+	if pinger, ok := any(conn).(Pinger); ok {
+		return pinger.PingContext(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lifecyclefx_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/healthfx"
+	"github.com/choopm/stdfx/lifecyclefx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+// fakeConn is an io.Closer implementing lifecyclefx.Pinger
+type fakeConn struct {
+	closed  bool
+	pingErr error
+}
+
+// Close implements io.Closer
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+// PingContext implements lifecyclefx.Pinger
+func (c *fakeConn) PingContext(context.Context) error {
+	return c.pingErr
+}
+
+func TestProvide(t *testing.T) {
+	conn := &fakeConn{}
+	attempts := 0
+	open := func(context.Context) (*fakeConn, error) {
+		attempts++
+		if attempts < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return conn, nil
+	}
+
+	var (
+		connection *lifecyclefx.Connection[*fakeConn]
+		registry   *healthfx.HealthRegistry
+	)
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(
+			healthfx.NewHealthRegistry,
+			lifecyclefx.Provide("database", open,
+				lifecyclefx.WithBackoff[*fakeConn](time.Millisecond, 5*time.Millisecond),
+			),
+		),
+		fx.Populate(&connection, &registry),
+	)
+
+	_, err := connection.Get()
+	assert.ErrorIs(t, err, lifecyclefx.ErrNotConnected)
+	assert.Contains(t, registry.Check(context.Background()), "database")
+
+	require.NoError(t, app.Start(context.Background()))
+	assert.Equal(t, 3, attempts)
+	got, err := connection.Get()
+	require.NoError(t, err)
+	assert.Same(t, conn, got)
+	assert.Empty(t, registry.Check(context.Background()))
+
+	conn.pingErr = errors.New("broken pipe")
+	assert.Contains(t, registry.Check(context.Background()), "database")
+
+	require.NoError(t, app.Stop(context.Background()))
+	assert.True(t, conn.closed)
+}
+
+func TestStartRetriesExhausted(t *testing.T) {
+	attempts := 0
+	connection := lifecyclefx.New("cache",
+		func(context.Context) (*fakeConn, error) {
+			attempts++
+			return nil, errors.New("connection refused")
+		},
+		slog.New(slog.DiscardHandler),
+		lifecyclefx.WithRetries[*fakeConn](2),
+		lifecyclefx.WithBackoff[*fakeConn](time.Millisecond, time.Millisecond),
+	)
+
+	err := connection.Start(context.Background())
+	assert.ErrorContains(t, err, "open cache: connection refused")
+	assert.Equal(t, 3, attempts)
+	assert.NoError(t, connection.Stop(context.Background()))
+}
+
+func TestStartContextDone(t *testing.T) {
+	connection := lifecyclefx.New("cache",
+		func(context.Context) (*fakeConn, error) {
+			return nil, errors.New("connection refused")
+		},
+		slog.New(slog.DiscardHandler),
+		lifecyclefx.WithBackoff[*fakeConn](time.Hour, time.Hour),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := connection.Start(ctx)
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
@@ -19,6 +19,9 @@ package loggingfx
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/creasty/defaults"
@@ -38,10 +41,20 @@ type Config struct {
 	// "trace", "fatal"
 	Level string `mapstructure:"level" default:"info"`
 
+	// Levels maps logger names to levels overriding Level for named loggers,
+	// e.g. {"http": "debug"}. Dotted names inherit the level of their
+	// closest parent, "http.client" uses the level of "http" if unset.
+	// Named loggers are supported by zerologfx.Named.
+	Levels map[string]string `mapstructure:"levels"`
+
 	// Output is the logging sink to use, currently supported:
 	// "stdout", "stderr", "<filename>"
 	Output string `mapstructure:"output" default:"stdout"`
 
+	// Reload reopens Output once log.output changes in the config file.
+	// It is disabled by default as it watches the config file.
+	Reload bool `mapstructure:"reload" default:"false"`
+
 	// Format is the logging encoding, currently supported:
 	// "text", "json"
 	Format string `mapstructure:"format" default:"text"`
@@ -49,12 +62,176 @@ type Config struct {
 	// FormatTime is the time encoding, all golang time formats are supported.
 	// Defaults to [time.RFC3339]
 	TimeFormat string `mapstructure:"timeFormat" default:""`
+
+	// TimeZone is the location of timestamps, e.g. "UTC" or "America/New_York".
+	// Defaults to the local time zone which can be set using env TZ.
+	TimeZone string `mapstructure:"timeZone" default:""`
+
+	// SampleBurst is the number of messages logged per SamplePeriod
+	// before sampling kicks in. Sampling is disabled if unset.
+	SampleBurst int `mapstructure:"sampleBurst" default:"0"`
+
+	// SamplePeriod is the period in which SampleBurst messages are logged.
+	// zapfx samples per message and level, zerologfx samples all messages.
+	SamplePeriod time.Duration `mapstructure:"samplePeriod" default:"0s"`
+
+	// SampleTick logs every n-th message exceeding SampleBurst,
+	// all of them are dropped if unset.
+	// Used without SampleBurst it logs every n-th message.
+	SampleTick int `mapstructure:"sampleTick" default:"0"`
+
+	// Stacktrace attaches stack traces to error level logs.
+	// zerologfx requires errors carrying a stack, e.g. of github.com/pkg/errors.
+	// zapfx attaches them regardless, to warn level logs of development formats.
+	Stacktrace bool `mapstructure:"stacktrace" default:"false"`
+
+	// FieldNames remaps the keys of standard fields to match a log schema
+	FieldNames FieldNames `mapstructure:"fieldNames"`
+
+	// Console tunes human readable console output
+	Console Console `mapstructure:"console"`
+}
+
+// LoggerLevel returns the level of the logger name. It is the level of
+// name or its closest dotted parent found in Levels, Level otherwise.
+func (c Config) LoggerLevel(name string) string {
+	for len(name) > 0 {
+		if level, ok := c.Levels[name]; ok {
+			return level
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+
+	return c.Level
+}
+
+// Location returns the location of TimeZone or time.Local if unset
+func (c Config) Location() (*time.Location, error) {
+	if len(c.TimeZone) == 0 {
+		return time.Local, nil
+	}
+
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log.timeZone %q: %s", c.TimeZone, err)
+	}
+
+	return loc, nil
 }
 
+// knownLevels lists the levels supported by all adapters
+var knownLevels = []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
+
+// knownFormats lists the formats supported by all adapters
+var knownFormats = []string{"text", "json", "color", "human", "nice"}
+
+// Validate returns an error if c contains values rejected by the adapters.
+// A file Output is checked for its directory to be writable.
+func (c Config) Validate() error {
+	if !slices.Contains(knownLevels, c.Level) {
+		return fmt.Errorf("unknown log.level: %s", c.Level)
+	}
+	for name, level := range c.Levels {
+		if !slices.Contains(knownLevels, level) {
+			return fmt.Errorf("unknown log.levels.%s: %s", name, level)
+		}
+	}
+	if !slices.Contains(knownFormats, c.Format) {
+		return fmt.Errorf("unknown log.format: %s", c.Format)
+	}
+	if err := validateOutput(c.Output); err != nil {
+		return err
+	}
+	if err := ValidateTimeFormat(c.TimeFormat); err != nil {
+		return err
+	}
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	if c.SampleBurst < 0 || c.SampleTick < 0 || c.SamplePeriod < 0 {
+		return fmt.Errorf("invalid log sampling: negative values are not allowed")
+	}
+
+	return nil
+}
+
+// validateOutput returns an error if the sink name cannot be opened.
+// Files are checked by creating a temporary file next to them.
+func validateOutput(name string) error {
+	switch name {
+	case "stdout", "stderr":
+		return nil
+	case "":
+		return fmt.Errorf("missing log.output")
+	}
+
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		return fmt.Errorf("invalid log.output %q: is a directory", name)
+	}
+	f, err := os.CreateTemp(filepath.Dir(name), ".log-validate-*")
+	if err != nil {
+		return fmt.Errorf("invalid log.output %q: directory is not writable: %s", name, err)
+	}
+	_ = f.Close()
+	_ = os.Remove(f.Name())
+
+	return nil
+}
+
+// Console defines options for human readable console output.
+// Adapters without a console writer ignore these options.
+type Console struct {
+	// Width is the width of the terminal in columns.
+	// 0 detects the width if the output is a terminal.
+	Width int `mapstructure:"width" default:"0"`
+
+	// Compact shortens timestamps, it is enabled automatically
+	// for terminals narrower than [CompactWidth].
+	Compact bool `mapstructure:"compact" default:"false"`
+
+	// Truncate shortens field values to a third of Width, or of
+	// [CompactWidth] if the width is unknown. Errors are never shortened.
+	Truncate bool `mapstructure:"truncate" default:"false"`
+}
+
+// CompactWidth is the terminal width below which [Console.Compact]
+// is enabled automatically.
+const CompactWidth = 100
+
+// FieldNames defines the keys of standard log fields.
+// Empty values keep the default key of the log adapter.
+type FieldNames struct {
+	// Timestamp is the key of the time field, e.g. "@timestamp"
+	Timestamp string `mapstructure:"timestamp" default:""`
+
+	// Level is the key of the level field, e.g. "level"
+	Level string `mapstructure:"level" default:""`
+
+	// Message is the key of the message field, e.g. "message"
+	Message string `mapstructure:"message" default:""`
+}
+
+// EnvironmentKey is the environment variable naming the deployment
+// environment, e.g. "production" or "development".
+// It is used by [DefaultConfig] to select a default format.
+// Set it to an empty string to disable environment specific defaults.
+var EnvironmentKey = "APP_ENV"
+
+// ProductionEnvironments lists the values of [EnvironmentKey]
+// which are treated as production environments (case-insensitive).
+var ProductionEnvironments = []string{"production", "prod"}
+
 // DefaultConfig returns the default logging configuration to be used until a
 // config file has been parsed to configure the real logger.
 // It reads environment variables LOG_* to adjust logging as early as possible
 // before even config parsing takes place.
+// The logging flags take precedence over them once applied, see [Flags].
+// If LOG_FORMAT is missing and [EnvironmentKey] is set, the format defaults
+// to "json" for [ProductionEnvironments] and to "color" otherwise.
 func DefaultConfig() (Config, error) {
 	config := Config{
 		Level:      os.Getenv("LOG_LEVEL"),
@@ -63,6 +240,19 @@ func DefaultConfig() (Config, error) {
 		TimeFormat: os.Getenv("LOG_TIMEFORMAT"),
 	}
 
+	// environment specific defaults
+	if len(config.Format) == 0 && len(EnvironmentKey) > 0 {
+		if env, ok := os.LookupEnv(EnvironmentKey); ok {
+			config.Format = "color"
+			for _, prod := range ProductionEnvironments {
+				if strings.EqualFold(env, prod) {
+					config.Format = "json"
+					break
+				}
+			}
+		}
+	}
+
 	if err := defaults.Set(&config); err != nil {
 		return config, fmt.Errorf("settings defaults: %s", err)
 	}
@@ -71,6 +261,33 @@ func DefaultConfig() (Config, error) {
 	if len(config.TimeFormat) == 0 {
 		config.TimeFormat = time.RFC3339
 	}
+	if err := ValidateTimeFormat(config.TimeFormat); err != nil {
+		return config, err
+	}
 
 	return config, nil
 }
+
+// unixTimeFormats are the names of the unix timestamp formats of zerolog,
+// e.g. zerolog.TimeFormatUnixMs, which are no go time layouts
+var unixTimeFormats = []string{"UNIXMS", "UNIXMICRO", "UNIXNANO"}
+
+// ValidateTimeFormat returns an error if layout is obviously no go time layout.
+// This is the case when formatting a time returns layout unchanged, which
+// catches layouts of other languages like "YYYY-MM-DD".
+// An empty layout is valid and selects the default of the adapter, as well
+// as the unix timestamp formats "UNIXMS", "UNIXMICRO" and "UNIXNANO" of zerolog.
+func ValidateTimeFormat(layout string) error {
+	if len(layout) == 0 || slices.Contains(unixTimeFormats, layout) {
+		return nil
+	}
+
+	// any time other than the reference time changes a valid layout
+	t := time.Date(1999, time.December, 31, 23, 58, 57, 0, time.UTC)
+	if t.Format(layout) == layout {
+		return fmt.Errorf("invalid log.timeFormat %q: not a go time layout like %q",
+			layout, time.RFC3339)
+	}
+
+	return nil
+}
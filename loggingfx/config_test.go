@@ -0,0 +1,206 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultConfigEnvironment(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		format string
+	}{
+		{name: "unset", format: "text"},
+		{name: "production", env: map[string]string{"APP_ENV": "Production"}, format: "json"},
+		{name: "development", env: map[string]string{"APP_ENV": "dev"}, format: "color"},
+		{name: "explicit", env: map[string]string{"APP_ENV": "prod", "LOG_FORMAT": "text"}, format: "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LOG_FORMAT", "")
+			t.Setenv("APP_ENV", "")
+			require.NoError(t, os.Unsetenv("APP_ENV"))
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			config, err := loggingfx.DefaultConfig()
+			require.NoError(t, err)
+			assert.Equal(t, tt.format, config.Format)
+		})
+	}
+}
+
+func TestDefaultConfigEnvironmentKey(t *testing.T) {
+	key := loggingfx.EnvironmentKey
+	defer func() { loggingfx.EnvironmentKey = key }()
+
+	loggingfx.EnvironmentKey = "STAGE"
+	t.Setenv("LOG_FORMAT", "")
+	t.Setenv("STAGE", "production")
+
+	config, err := loggingfx.DefaultConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "json", config.Format)
+}
+
+func TestValidateTimeFormat(t *testing.T) {
+	for _, layout := range []string{
+		"", time.RFC3339, time.Kitchen, "2006-01-02 15:04",
+		zerolog.TimeFormatUnixMs, zerolog.TimeFormatUnixMicro, zerolog.TimeFormatUnixNano,
+	} {
+		assert.NoError(t, loggingfx.ValidateTimeFormat(layout), layout)
+	}
+	for _, layout := range []string{"YYYY-MM-DD", "yyyy-MM-dd HH:mm:ss"} {
+		assert.Error(t, loggingfx.ValidateTimeFormat(layout), layout)
+	}
+
+	t.Setenv("LOG_TIMEFORMAT", "YYYY-MM-DD")
+	_, err := loggingfx.DefaultConfig()
+	assert.ErrorContains(t, err, "YYYY-MM-DD")
+}
+
+func TestLoggerLevel(t *testing.T) {
+	config := loggingfx.Config{
+		Level: "info",
+		Levels: map[string]string{
+			"http":        "debug",
+			"http.client": "warn",
+		},
+	}
+
+	assert.Equal(t, "debug", config.LoggerLevel("http"))
+	assert.Equal(t, "debug", config.LoggerLevel("http.server"))
+	assert.Equal(t, "warn", config.LoggerLevel("http.client.retry"))
+	assert.Equal(t, "info", config.LoggerLevel("database"))
+	assert.Equal(t, "info", config.LoggerLevel(""))
+}
+
+func TestFlags(t *testing.T) {
+	flags := loggingfx.NewFlags()
+	require.NoError(t, flags.FlagSet().Parse([]string{"--log-level", "debug", "--log-output=stderr"}))
+
+	// flags override configs once applied
+	config := loggingfx.Config{Level: "info", Format: "json", Output: "stdout"}
+	assert.Equal(t, config, flags.Override(config))
+
+	applied := 0
+	require.NoError(t, flags.OnApply(func() error {
+		applied++
+		return nil
+	}))
+	require.NoError(t, flags.Apply())
+	assert.Equal(t, 1, applied)
+	assert.Equal(t, loggingfx.Config{Level: "debug", Format: "json", Output: "stderr"},
+		flags.Override(config))
+
+	// callbacks added later are called right away
+	require.EqualError(t, flags.OnApply(func() error {
+		return errors.New("invalid")
+	}), "invalid")
+	require.EqualError(t, flags.Apply(), "invalid")
+	assert.Equal(t, 2, applied)
+}
+
+func TestConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		modify func(*loggingfx.Config)
+		err    string
+	}{
+		{name: "valid", modify: func(*loggingfx.Config) {}},
+		{name: "valid file", modify: func(c *loggingfx.Config) { c.Output = filepath.Join(dir, "app.log") }},
+		{
+			name:   "level",
+			modify: func(c *loggingfx.Config) { c.Level = "infoo" },
+			err:    "unknown log.level: infoo",
+		},
+		{
+			name:   "levels",
+			modify: func(c *loggingfx.Config) { c.Levels = map[string]string{"http": "verbose"} },
+			err:    "unknown log.levels.http: verbose",
+		},
+		{
+			name:   "format",
+			modify: func(c *loggingfx.Config) { c.Format = "xml" },
+			err:    "unknown log.format: xml",
+		},
+		{
+			name:   "output missing",
+			modify: func(c *loggingfx.Config) { c.Output = "" },
+			err:    "missing log.output",
+		},
+		{
+			name:   "output directory",
+			modify: func(c *loggingfx.Config) { c.Output = dir },
+			err:    "is a directory",
+		},
+		{
+			name:   "output not writable",
+			modify: func(c *loggingfx.Config) { c.Output = filepath.Join(dir, "missing", "app.log") },
+			err:    "directory is not writable",
+		},
+		{
+			name:   "time format",
+			modify: func(c *loggingfx.Config) { c.TimeFormat = "YYYY-MM-DD" },
+			err:    "invalid log.timeFormat",
+		},
+		{
+			name:   "time zone",
+			modify: func(c *loggingfx.Config) { c.TimeZone = "Mars/Olympus_Mons" },
+			err:    "invalid log.timeZone",
+		},
+		{
+			name:   "sampling",
+			modify: func(c *loggingfx.Config) { c.SampleBurst = -1 },
+			err:    "invalid log sampling",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := loggingfx.Config{Level: "info", Format: "text", Output: "stdout"}
+			tt.modify(&config)
+
+			err := config.Validate()
+			if len(tt.err) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	// validation must not leave files behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// Names of the logging flags overriding the logging config
+const (
+	FlagLevel  = "log-level"
+	FlagFormat = "log-format"
+	FlagOutput = "log-output"
+)
+
+// Flags are the logging flags --log-level, --log-format and --log-output
+// overriding the logging config. Flags take precedence over environment
+// variables and config files.
+//
+// The root command of stdfx binds them and applies them once cobra parsed
+// them. Loggers are built before, the NewWithFlags funcs of the adapters
+// follow the flags using [Flags.OnApply]. The adapter modules provide them.
+type Flags struct {
+	flagSet *pflag.FlagSet
+	level   *string
+	format  *string
+	output  *string
+
+	mutex   sync.Mutex
+	applied bool
+	// overrides are the values of flags set by the user once applied
+	overrides map[string]string
+	onApply   []func() error
+}
+
+// NewFlags returns *Flags to be bound to a root command
+func NewFlags() *Flags {
+	f := &Flags{
+		flagSet: pflag.NewFlagSet("logging", pflag.ContinueOnError),
+	}
+	f.level = f.flagSet.String(FlagLevel, "",
+		"Log level overriding the config, example: debug")
+	f.format = f.flagSet.String(FlagFormat, "",
+		"Log format overriding the config, example: json")
+	f.output = f.flagSet.String(FlagOutput, "",
+		"Log output overriding the config, example: stderr")
+
+	return f
+}
+
+// FlagSet returns the flags to be added to the persistent flags
+// of a root command
+func (f *Flags) FlagSet() *pflag.FlagSet {
+	return f.flagSet
+}
+
+// Apply applies the parsed flags by calling the callbacks of OnApply.
+// The root command calls it once cobra parsed the flags.
+func (f *Flags) Apply() error {
+	f.mutex.Lock()
+	f.applied = true
+	f.overrides = map[string]string{}
+	for name, value := range map[string]*string{
+		FlagLevel:  f.level,
+		FlagFormat: f.format,
+		FlagOutput: f.output,
+	} {
+		if f.flagSet.Changed(name) {
+			f.overrides[name] = *value
+		}
+	}
+	callbacks := f.onApply
+	f.mutex.Unlock()
+
+	errs := []error{}
+	for _, callback := range callbacks {
+		errs = append(errs, callback())
+	}
+
+	return errors.Join(errs...)
+}
+
+// OnApply adds callback to be called once the flags are applied.
+// It is called right away if they have been applied already.
+func (f *Flags) OnApply(callback func() error) error {
+	f.mutex.Lock()
+	applied := f.applied
+	f.onApply = append(f.onApply, callback)
+	f.mutex.Unlock()
+
+	if applied {
+		return callback()
+	}
+	return nil
+}
+
+// Override returns config overridden by the flags set by the user
+// once they have been applied.
+func (f *Flags) Override(config Config) Config {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if level, ok := f.overrides[FlagLevel]; ok {
+		config.Level = level
+	}
+	if format, ok := f.overrides[FlagFormat]; ok {
+		config.Format = format
+	}
+	if output, ok := f.overrides[FlagOutput]; ok {
+		config.Output = output
+	}
+
+	return config
+}
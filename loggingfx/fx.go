@@ -0,0 +1,63 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx
+
+import "log/slog"
+
+// FxLevelPolicy maps the importance of fx events to the level they are
+// logged at by the ToFx adapters. fx logs regular events like provides
+// and invokes as [slog.LevelInfo] and failures as [slog.LevelError].
+// Usage example:
+//
+//	fx.WithLogger(zerologfx.ToFxWithPolicy(loggingfx.FxVerbosePolicy)),
+type FxLevelPolicy map[slog.Level]slog.Level
+
+var (
+	// FxVerbosePolicy logs fx events at their own level, used by zapfx.ToFx
+	FxVerbosePolicy = FxLevelPolicy{
+		slog.LevelDebug: slog.LevelDebug,
+		slog.LevelInfo:  slog.LevelInfo,
+		slog.LevelWarn:  slog.LevelWarn,
+		slog.LevelError: slog.LevelError,
+	}
+
+	// FxQuietPolicy logs fx events at debug level unless they are errors,
+	// used by zerologfx.ToFx
+	FxQuietPolicy = FxLevelPolicy{
+		slog.LevelDebug: slog.LevelDebug,
+		slog.LevelInfo:  slog.LevelDebug,
+		slog.LevelWarn:  slog.LevelDebug,
+		slog.LevelError: slog.LevelError,
+	}
+
+	// FxDebugPolicy logs all fx events at debug level, used by slogfx.ToFx
+	FxDebugPolicy = FxLevelPolicy{
+		slog.LevelDebug: slog.LevelDebug,
+		slog.LevelInfo:  slog.LevelDebug,
+		slog.LevelWarn:  slog.LevelDebug,
+		slog.LevelError: slog.LevelDebug,
+	}
+)
+
+// Level returns the level of events of importance.
+// Unmapped importances are logged at their own level.
+func (p FxLevelPolicy) Level(importance slog.Level) slog.Level {
+	if level, ok := p[importance]; ok {
+		return level
+	}
+	return importance
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx_test
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx/slogfx"
+	"github.com/choopm/stdfx/loggingfx/zapfx"
+	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTestModule(t *testing.T) {
+	// default loggers write to out, nop loggers must not fall back to them
+	out := &bytes.Buffer{}
+	stdlog, slogger, zlogger := log.Writer(), slog.Default(), zlog.Logger
+	log.SetOutput(out)
+	slog.SetDefault(slog.New(slog.NewTextHandler(out, nil)))
+	zlog.Logger = zerolog.New(out)
+	restoreZap := zap.ReplaceGlobals(zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		zapcore.AddSync(out), zapcore.DebugLevel)))
+	t.Cleanup(func() {
+		log.SetOutput(stdlog)
+		slog.SetDefault(slogger)
+		zlog.Logger = zlogger
+		restoreZap()
+	})
+
+	tests := []struct {
+		name   string
+		module fx.Option
+		invoke any
+	}{
+		{
+			name:   "slog",
+			module: slogfx.TestModule,
+			invoke: func(log *log.Logger, slog *slog.Logger) {
+				log.Print("error message")
+				slog.Error("error message")
+			},
+		},
+		{
+			name:   "zap",
+			module: zapfx.TestModule,
+			invoke: func(log *zap.Logger, slog *slog.Logger) {
+				log.Error("error message")
+				slog.Error("error message")
+			},
+		},
+		{
+			name:   "zerolog",
+			module: zerologfx.TestModule,
+			invoke: func(log *zerolog.Logger, slog *slog.Logger) {
+				log.Error().Msg("error message")
+				slog.Error("error message")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			fxtest.New(t,
+				fx.NopLogger,
+				tt.module,
+				fx.Invoke(tt.invoke),
+			).RequireStart().RequireStop()
+
+			assert.Empty(t, out.String())
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Output is an io.Writer writing to the sink named by [Config.Output]
+// which is "stdout", "stderr" or a filename.
+// The sink can be swapped using [Output.Reopen] while logging, e.g. on
+// config reloads or after log rotation. Files opened by Output are closed
+// when being swapped. Pass it to the NewWithOutput func of an adapter:
+//
+//	output, err := loggingfx.OpenOutput(cfg.Logging.Output)
+//	// ...
+//	logger, err := zerologfx.NewWithOutput(cfg.Logging, output)
+//	// ...
+//	// on config changes
+//	err = output.Reopen(newCfg.Logging.Output)
+//
+// Note that adapters choose formatting like colors by the sink
+// given at construction time.
+type Output struct {
+	name string
+	file *os.File
+	// writer replaces file if set by WriterOutput
+	writer io.Writer
+	// owned denotes file was opened by Output and needs to be closed
+	owned bool
+	mutex sync.RWMutex
+}
+
+// ensure Output implements io.WriteCloser
+var _ io.WriteCloser = &Output{}
+
+// OpenOutput returns an *Output writing to the sink name
+func OpenOutput(name string) (*Output, error) {
+	o := &Output{}
+	if err := o.Reopen(name); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// WriterOutput returns an *Output writing to w instead of a named sink.
+// Use it to capture logs programmatically, e.g. into a *bytes.Buffer.
+// Reopening it switches to a named sink.
+func WriterOutput(w io.Writer) *Output {
+	return &Output{name: "writer", writer: w}
+}
+
+// Reopen swaps the sink to name and closes the previous file if any.
+// Reopening the same filename reopens the file which is required
+// after it has been rotated.
+func (o *Output) Reopen(name string) error {
+	file, owned := os.Stdout, false
+	switch name {
+	case "stdout":
+	case "stderr":
+		file = os.Stderr
+	default:
+		// name is a filename
+		var err error
+		file, err = os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("unable to open log.output: %s", err)
+		}
+		owned = true
+	}
+
+	// swap, waiting for running writes to finish
+	o.mutex.Lock()
+	previous, previousOwned := o.file, o.owned
+	o.name, o.file, o.owned, o.writer = name, file, owned, nil
+	o.mutex.Unlock()
+
+	if previousOwned {
+		return previous.Close()
+	}
+
+	return nil
+}
+
+// ReopenOnChange returns a callback for configfx.WithOnConfigChange which
+// reopens o using the Output of the config returned by config.
+// The previous sink is kept if config fails or the new sink cannot be opened.
+func (o *Output) ReopenOnChange(config func() (Config, error)) func(in fsnotify.Event) {
+	return func(fsnotify.Event) {
+		cfg, err := config()
+		if err != nil {
+			return
+		}
+		_ = o.Reopen(cfg.Output)
+	}
+}
+
+// Name returns the name of the current sink
+func (o *Output) Name() string {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	return o.name
+}
+
+// File returns the *os.File of the current sink
+// or nil if writing to the writer of [WriterOutput]
+func (o *Output) File() *os.File {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	return o.file
+}
+
+// IsFile returns true if the current sink is a file
+// instead of stdout or stderr.
+func (o *Output) IsFile() bool {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	return o.owned
+}
+
+// Write implements io.Writer
+func (o *Output) Write(p []byte) (int, error) {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	if o.writer != nil {
+		return o.writer.Write(p)
+	}
+	return o.file.Write(p)
+}
+
+// Sync commits the written data of files to stable storage
+func (o *Output) Sync() error {
+	o.mutex.RLock()
+	defer o.mutex.RUnlock()
+
+	if !o.owned {
+		return nil
+	}
+	return o.file.Sync()
+}
+
+// Close closes the current sink if it is a file.
+// Subsequent writes go to stdout.
+func (o *Output) Close() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if !o.owned {
+		return nil
+	}
+	file := o.file
+	o.name, o.file, o.owned = "stdout", os.Stdout, false
+
+	return file.Close()
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputReopen(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+
+	output, err := loggingfx.OpenOutput("stdout")
+	require.NoError(t, err)
+	assert.False(t, output.IsFile())
+	assert.Same(t, os.Stdout, output.File())
+
+	// stdout to file
+	require.NoError(t, output.Reopen(first))
+	assert.True(t, output.IsFile())
+	_, err = output.Write([]byte("first\n"))
+	require.NoError(t, err)
+	firstFile := output.File()
+
+	// file to file closes the previous file
+	require.NoError(t, output.Reopen(second))
+	_, err = output.Write([]byte("second\n"))
+	require.NoError(t, err)
+	_, err = firstFile.Write([]byte("leaked\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+
+	// reopening the same file, e.g. after rotation
+	for range 100 {
+		require.NoError(t, output.Reopen(second))
+	}
+	require.NoError(t, output.Close())
+
+	b, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", string(b))
+	b, err = os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", string(b))
+
+	assert.ErrorContains(t, output.Reopen(filepath.Join(dir, "missing", "x.log")), "unable to open log.output")
+}
+
+func TestWriterOutput(t *testing.T) {
+	out := &bytes.Buffer{}
+	output := loggingfx.WriterOutput(out)
+	assert.False(t, output.IsFile())
+	assert.Nil(t, output.File())
+
+	_, err := output.Write([]byte("captured\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "captured\n", out.String())
+
+	// reopening switches to the named sink
+	filename := filepath.Join(t.TempDir(), "out.log")
+	require.NoError(t, output.Reopen(filename))
+	_, err = output.Write([]byte("file\n"))
+	require.NoError(t, err)
+	require.NoError(t, output.Close())
+	assert.Equal(t, "captured\n", out.String())
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// RedactedValue replaces the value of redacted string fields
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a deep copy of v having secret values masked.
+// Struct fields tagged using `secret:"true"` and struct fields or map
+// entries named like any of keys are masked. Keys are matched
+// case-insensitive against field names and `mapstructure:""` tags.
+// Strings are replaced by [RedactedValue], other types are zeroed.
+// Use it whenever logging whole config structs:
+//
+//	log.Debug("config", slog.Any("config", loggingfx.Redacted(cfg)))
+func Redacted(v any, keys ...string) any {
+	src := reflect.ValueOf(v)
+	if !src.IsValid() {
+		return v
+	}
+
+	dst := reflect.New(src.Type()).Elem()
+	redactCopy(dst, src, keys)
+
+	return dst.Interface()
+}
+
+// redactCopy deep copies src to dst masking secrets
+func redactCopy(dst, src reflect.Value, keys []string) {
+	switch src.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		redactCopy(dst.Elem(), src.Elem(), keys)
+
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		elem := reflect.New(src.Elem().Type()).Elem()
+		redactCopy(elem, src.Elem(), keys)
+		dst.Set(elem)
+
+	case reflect.Struct:
+		// copy everything including unexported fields first
+		dst.Set(src)
+		for i := range src.NumField() {
+			field := src.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if isSecretField(field, keys) {
+				redact(dst.Field(i))
+				continue
+			}
+			redactCopy(dst.Field(i), src.Field(i), keys)
+		}
+
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(src.Type().Elem()).Elem()
+			if isSecretKey(fmt.Sprint(iter.Key().Interface()), keys) {
+				redact(elem)
+			} else {
+				redactCopy(elem, iter.Value(), keys)
+			}
+			dst.SetMapIndex(iter.Key(), elem)
+		}
+
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := range src.Len() {
+			redactCopy(dst.Index(i), src.Index(i), keys)
+		}
+
+	case reflect.Array:
+		for i := range src.Len() {
+			redactCopy(dst.Index(i), src.Index(i), keys)
+		}
+
+	default:
+		dst.Set(src)
+	}
+}
+
+// redact masks v: strings are replaced by [RedactedValue],
+// everything else is zeroed.
+func redact(v reflect.Value) {
+	if v.Kind() == reflect.String {
+		v.SetString(RedactedValue)
+		return
+	}
+	v.Set(reflect.Zero(v.Type()))
+}
+
+// isSecretField returns true if field is tagged as secret
+// or named like any of keys
+func isSecretField(field reflect.StructField, keys []string) bool {
+	if field.Tag.Get("secret") == "true" {
+		return true
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	return isSecretKey(field.Name, keys) ||
+		(len(name) > 0 && isSecretKey(name, keys))
+}
+
+// isSecretKey returns true if name equals any of keys
+func isSecretKey(name string, keys []string) bool {
+	for _, key := range keys {
+		if strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loggingfx_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/stretchr/testify/assert"
+)
+
+type redactDatabase struct {
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password" secret:"true"`
+	Port     int    `mapstructure:"port" secret:"true"`
+}
+
+type redactConfig struct {
+	Database *redactDatabase   `mapstructure:"database"`
+	APIToken string            `mapstructure:"api-token"`
+	Headers  map[string]string `mapstructure:"headers"`
+	Replicas []redactDatabase  `mapstructure:"replicas"`
+}
+
+func TestRedacted(t *testing.T) {
+	cfg := &redactConfig{
+		Database: &redactDatabase{User: "admin", Password: "secret", Port: 5432},
+		APIToken: "token",
+		Headers:  map[string]string{"Authorization": "Bearer token", "Accept": "*/*"},
+		Replicas: []redactDatabase{{User: "replica", Password: "secret"}},
+	}
+
+	redacted := loggingfx.Redacted(cfg, "api-token", "authorization").(*redactConfig)
+
+	// secrets are masked
+	assert.Equal(t, loggingfx.RedactedValue, redacted.Database.Password)
+	assert.Equal(t, 0, redacted.Database.Port)
+	assert.Equal(t, loggingfx.RedactedValue, redacted.APIToken)
+	assert.Equal(t, loggingfx.RedactedValue, redacted.Headers["Authorization"])
+	assert.Equal(t, loggingfx.RedactedValue, redacted.Replicas[0].Password)
+
+	// everything else is kept
+	assert.Equal(t, "admin", redacted.Database.User)
+	assert.Equal(t, "*/*", redacted.Headers["Accept"])
+	assert.Equal(t, "replica", redacted.Replicas[0].User)
+
+	// the original is untouched
+	assert.Equal(t, "secret", cfg.Database.Password)
+	assert.Equal(t, "token", cfg.APIToken)
+	assert.Equal(t, "Bearer token", cfg.Headers["Authorization"])
+	assert.Equal(t, "secret", cfg.Replicas[0].Password)
+}
@@ -21,7 +21,7 @@ import (
 	"io"
 	"log"
 	"log/slog"
-	"os"
+	"time"
 
 	"github.com/choopm/stdfx/loggingfx"
 	"go.uber.org/fx"
@@ -31,14 +31,52 @@ import (
 // Module returns a slog constructor and adapters to common loggers
 var Module = fx.Module(
 	"slog", fx.Provide(
-		New,
+		NewWithFlags,
 		ToStdlog,
 		loggingfx.DefaultConfig,
+		loggingfx.NewFlags,
 	),
 )
 
 // New returns a new configured *slog.Logger
 func New(config loggingfx.Config) (*slog.Logger, error) {
+	output, err := loggingfx.OpenOutput(config.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := NewWithOutput(config, output)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// NewWithWriter returns a new configured *slog.Logger writing to w
+// instead of config.Output, e.g. to capture logs into a *bytes.Buffer.
+func NewWithWriter(config loggingfx.Config, w io.Writer) (*slog.Logger, error) {
+	return NewWithOutput(config, loggingfx.WriterOutput(w))
+}
+
+// NewWithOutput returns a new configured *slog.Logger writing to output
+// instead of config.Output. Use it to swap the output on config reloads.
+func NewWithOutput(config loggingfx.Config, output *loggingfx.Output) (*slog.Logger, error) {
+	handler, err := newHandler(config, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return slog.New(handler), nil
+}
+
+// newHandler returns the slog.Handler of config writing to output
+func newHandler(config loggingfx.Config, output *loggingfx.Output) (slog.Handler, error) {
+	if err := loggingfx.ValidateTimeFormat(config.TimeFormat); err != nil {
+		return nil, err
+	}
+
 	// parse level
 	slevel := slog.LevelInfo // nolint:ineffassign
 	switch config.Level {
@@ -54,26 +92,17 @@ func New(config loggingfx.Config) (*slog.Logger, error) {
 		return nil, fmt.Errorf("unknown log.level: %s", config.Level)
 	}
 
-	// build output sink
-	var output io.Writer = os.Stdout // nolint:ineffassign
-	switch config.Output {
-	case "stdout":
-		output = os.Stdout
-	case "stderr":
-		output = os.Stderr
-	default:
-		// config.Output is a filename
-		var err error
-		output, err = os.OpenFile(config.Output, 0644, os.ModeAppend)
-		if err != nil {
-			return nil, fmt.Errorf("unable to open log.output: %s", err)
-		}
-		// this file is closed automatically by go runtime through finalizers
-	}
-
 	// build options
 	opts := &slog.HandlerOptions{
-		Level: slevel,
+		Level:       slevel,
+		ReplaceAttr: replaceFieldNames(config.FieldNames),
+	}
+	if len(config.TimeZone) > 0 {
+		loc, err := config.Location()
+		if err != nil {
+			return nil, err
+		}
+		opts.ReplaceAttr = replaceTimeZone(loc, opts.ReplaceAttr)
 	}
 
 	// choose a handler to use
@@ -87,10 +116,57 @@ func New(config loggingfx.Config) (*slog.Logger, error) {
 		return nil, fmt.Errorf("unknown log.format: %s", config.Format)
 	}
 
-	// build logger
-	logger := slog.New(handler)
+	if config.Stacktrace {
+		handler = &slogStacktrace{Handler: handler}
+	}
 
-	return logger, nil
+	return handler, nil
+}
+
+// replaceFieldNames returns a slog.HandlerOptions.ReplaceAttr func
+// renaming the standard top-level keys as defined by names.
+// It returns nil if no key needs to be renamed.
+func replaceFieldNames(names loggingfx.FieldNames) func([]string, slog.Attr) slog.Attr {
+	keys := map[string]string{}
+	for key, name := range map[string]string{
+		slog.TimeKey:    names.Timestamp,
+		slog.LevelKey:   names.Level,
+		slog.MessageKey: names.Message,
+	} {
+		if len(name) > 0 && name != key {
+			keys[key] = name
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) > 0 {
+			return a
+		}
+		if name, ok := keys[a.Key]; ok {
+			a.Key = name
+		}
+		return a
+	}
+}
+
+// replaceTimeZone returns a slog.HandlerOptions.ReplaceAttr func
+// converting the top-level time to loc before calling next if not nil.
+func replaceTimeZone(
+	loc *time.Location,
+	next func([]string, slog.Attr) slog.Attr,
+) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+			a.Value = slog.TimeValue(a.Value.Time().In(loc))
+		}
+		if next != nil {
+			return next(groups, a)
+		}
+		return a
+	}
 }
 
 // ToStdlog provides a logging adapter for logging from stdlog to slog.
@@ -101,8 +177,17 @@ func ToStdlog(log *slog.Logger) *log.Logger {
 
 // ToFx provides a logging adapter for logging from fxevent.Logger to slog.
 // Designed to be used as a parameter for with fx.WithLogger().
+// It logs all fx events at debug level, see [loggingfx.FxDebugPolicy].
 func ToFx(log *slog.Logger) fxevent.Logger {
-	return &fxevent.SlogLogger{
-		Logger: AtLevel(log, slog.LevelDebug),
+	return ToFxWithPolicy(loggingfx.FxDebugPolicy)(log)
+}
+
+// ToFxWithPolicy returns a [ToFx] adapter logging fx events at the levels
+// of policy. Designed to be used as a parameter for with fx.WithLogger().
+func ToFxWithPolicy(policy loggingfx.FxLevelPolicy) func(log *slog.Logger) fxevent.Logger {
+	return func(log *slog.Logger) fxevent.Logger {
+		return &fxevent.SlogLogger{
+			Logger: AtLevelMap(log, policy),
+		}
 	}
 }
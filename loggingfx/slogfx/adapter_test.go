@@ -0,0 +1,99 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/slogfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestNewTimeZone(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := slogfx.New(loggingfx.Config{
+		Level:    "info",
+		Format:   "json",
+		Output:   filename,
+		TimeZone: "Asia/Kolkata",
+		FieldNames: loggingfx.FieldNames{
+			Timestamp: "@timestamp",
+		},
+	})
+	require.NoError(t, err)
+	log.Info("message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(b, &line))
+	assert.Regexp(t, `\+05:30$`, line["@timestamp"])
+
+	_, err = slogfx.New(loggingfx.Config{
+		Level:    "info",
+		Format:   "json",
+		Output:   filename,
+		TimeZone: "Mars/Olympus_Mons",
+	})
+	assert.ErrorContains(t, err, "invalid log.timeZone")
+}
+
+func TestNewWithWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log, err := slogfx.NewWithWriter(loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Output: "stdout", // ignored
+	}, out)
+	require.NoError(t, err)
+	log.Info("message", "key", "value")
+
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "INFO", line["level"])
+	assert.Equal(t, "message", line["msg"])
+	assert.Equal(t, "value", line["key"])
+}
+
+func TestToFxWithPolicy(t *testing.T) {
+	// run starts and stops an app logging fx events using policy
+	// and returns the log output
+	run := func(policy loggingfx.FxLevelPolicy) string {
+		out := &bytes.Buffer{}
+		log, err := slogfx.NewWithWriter(loggingfx.Config{Level: "info", Format: "json"}, out)
+		require.NoError(t, err)
+
+		fxtest.New(t,
+			fx.Supply(log),
+			fx.WithLogger(slogfx.ToFxWithPolicy(policy)),
+		).RequireStart().RequireStop()
+
+		return out.String()
+	}
+
+	assert.Contains(t, run(loggingfx.FxVerbosePolicy), "started")
+	assert.NotContains(t, run(loggingfx.FxQuietPolicy), "started")
+	assert.NotContains(t, run(loggingfx.FxDebugPolicy), "started")
+}
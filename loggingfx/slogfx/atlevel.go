@@ -37,6 +37,7 @@ func AtLevel(log *slog.Logger, level slog.Level) *slog.Logger {
 
 // AtLevelMap takes a *slog.Logger and returns a new *slog.Logger
 // which logs everything to the level mapped by level instead.
+// Unmapped levels are logged at their own level.
 func AtLevelMap(log *slog.Logger, levels map[slog.Level]slog.Level) *slog.Logger {
 	return slog.New(&slogLevelRedirect{
 		Logger: log,
@@ -51,15 +52,24 @@ type slogLevelRedirect struct {
 }
 
 func (s *slogLevelRedirect) Enabled(ctx context.Context, level slog.Level) bool {
-	return s.Logger.Handler().Enabled(ctx, level)
+	// check the rewritten level, handlers rely on Enabled for filtering
+	return s.Logger.Handler().Enabled(ctx, s.level(level))
 }
 
 func (s *slogLevelRedirect) Handle(ctx context.Context, record slog.Record) error {
 	// rewrite level
-	record.Level = s.m[record.Level]
+	record.Level = s.level(record.Level)
 	return s.Logger.Handler().Handle(ctx, record)
 }
 
+// level returns the level mapped for level or level itself if unmapped
+func (s *slogLevelRedirect) level(level slog.Level) slog.Level {
+	if mapped, ok := s.m[level]; ok {
+		return mapped
+	}
+	return level
+}
+
 func (s *slogLevelRedirect) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return s.Logger.Handler().WithAttrs(attrs)
 }
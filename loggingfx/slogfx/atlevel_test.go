@@ -0,0 +1,41 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx/slogfx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtLevelMapPartial(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	// only info is remapped, errors keep their level
+	redirected := slogfx.AtLevelMap(log, map[slog.Level]slog.Level{
+		slog.LevelInfo: slog.LevelDebug,
+	})
+	redirected.Info("info message")
+	redirected.Error("error message")
+
+	assert.NotContains(t, out.String(), "info message")
+	assert.Contains(t, out.String(), "level=ERROR msg=\"error message\"")
+}
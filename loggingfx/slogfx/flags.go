@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+
+	"github.com/choopm/stdfx/loggingfx"
+)
+
+// NewWithFlags returns a new configured *slog.Logger following the
+// level, format and output of flags once they are applied.
+func NewWithFlags(config loggingfx.Config, flags *loggingfx.Flags) (*slog.Logger, error) {
+	output, err := loggingfx.OpenOutput(flags.Override(config).Output)
+	if err != nil {
+		return nil, err
+	}
+
+	handler, err := newHandler(flags.Override(config), output)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+	swap := &swapHandler{root: &atomic.Pointer[rootHandler]{}}
+	swap.root.Store(&rootHandler{handler: handler})
+
+	err = flags.OnApply(func() error {
+		overridden := flags.Override(config)
+		if overridden.Output != config.Output {
+			if err := output.Reopen(overridden.Output); err != nil {
+				return err
+			}
+		}
+		handler, err := newHandler(overridden, output)
+		if err != nil {
+			return err
+		}
+		swap.root.Store(&rootHandler{handler: handler})
+
+		return nil
+	})
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+
+	return slog.New(swap), nil
+}
+
+// rootHandler is a handler swapped by swapHandler
+type rootHandler struct {
+	handler slog.Handler
+}
+
+// derivedHandler is the handler of a rootHandler with attrs and groups added
+type derivedHandler struct {
+	root    *rootHandler
+	handler slog.Handler
+}
+
+// swapHandler is a slog.Handler delegating to the current root handler
+// which is swapped while logging. Attrs and groups added using WithAttrs
+// and WithGroup are added to the current root handler once it is used.
+type swapHandler struct {
+	root *atomic.Pointer[rootHandler]
+	// derive adds the attrs and groups of the handler in order
+	derive []func(slog.Handler) slog.Handler
+
+	// current is the current root handler with attrs and groups added
+	current atomic.Pointer[derivedHandler]
+}
+
+// ensure swapHandler implements slog.Handler
+var _ slog.Handler = &swapHandler{}
+
+// handler returns the current root handler with the attrs
+// and groups of h added
+func (h *swapHandler) handler() slog.Handler {
+	root := h.root.Load()
+	if current := h.current.Load(); current != nil && current.root == root {
+		return current.handler
+	}
+
+	handler := root.handler
+	for _, derive := range h.derive {
+		handler = derive(handler)
+	}
+	h.current.Store(&derivedHandler{root: root, handler: handler})
+
+	return handler
+}
+
+// Enabled implements slog.Handler
+func (h *swapHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler().Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler
+func (h *swapHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler().Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler
+func (h *swapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler {
+		return handler.WithAttrs(attrs)
+	})
+}
+
+// WithGroup implements slog.Handler
+func (h *swapHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler {
+		return handler.WithGroup(name)
+	})
+}
+
+// with returns a *swapHandler adding derive after the ones of h
+func (h *swapHandler) with(derive func(slog.Handler) slog.Handler) *swapHandler {
+	return &swapHandler{
+		root:   h.root,
+		derive: append(slices.Clip(h.derive), derive),
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package slogfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/slogfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithFlags(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	flags := loggingfx.NewFlags()
+	log, err := slogfx.NewWithFlags(loggingfx.Config{
+		Level:  "info",
+		Format: "text",
+		Output: filename,
+	}, flags)
+	require.NoError(t, err)
+	child := log.WithGroup("request").With("key", "value")
+	child.Debug("hidden")
+
+	// applied flags swap level and format of existing loggers
+	require.NoError(t, flags.FlagSet().Parse([]string{
+		"--log-level", "debug", "--log-format", "json",
+	}))
+	require.NoError(t, flags.Apply())
+	child.Debug("after")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "hidden")
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(b), &line))
+	assert.Equal(t, "DEBUG", line["level"])
+	assert.Equal(t, "after", line["msg"])
+	assert.Equal(t, map[string]any{"key": "value"}, line["request"])
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx
+
+import (
+	"log/slog"
+
+	"go.uber.org/fx"
+)
+
+// TestModule provides discarding loggers like [Module] does
+// without requiring a config. Use it in tests of fx applications:
+//
+//	fxtest.New(t, slogfx.TestModule, fx.Invoke(...))
+var TestModule = fx.Module(
+	"slog", fx.Provide(
+		Nop,
+		ToStdlog,
+	),
+)
+
+// Nop returns a new *slog.Logger discarding all messages
+// using [slog.DiscardHandler]
+func Nop() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
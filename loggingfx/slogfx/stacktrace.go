@@ -0,0 +1,83 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+)
+
+// StackKey is the key of the attribute holding the stack trace
+// of error level logs if [loggingfx.Config] Stacktrace is enabled
+const StackKey = "stack"
+
+// slogStacktrace wraps a slog.Handler adding the stack trace of
+// the caller to records of error level or above
+type slogStacktrace struct {
+	slog.Handler
+}
+
+func (s *slogStacktrace) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelError {
+		record = record.Clone()
+		record.AddAttrs(slog.String(StackKey, stacktrace(record.PC)))
+	}
+
+	return s.Handler.Handle(ctx, record)
+}
+
+func (s *slogStacktrace) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &slogStacktrace{Handler: s.Handler.WithAttrs(attrs)}
+}
+
+func (s *slogStacktrace) WithGroup(name string) slog.Handler {
+	return &slogStacktrace{Handler: s.Handler.WithGroup(name)}
+}
+
+// stacktrace formats the current stack starting at the function of pc
+// like debug.Stack does, omitting the frames of slog and this handler.
+// The full stack is returned if pc is not found.
+func stacktrace(pc uintptr) string {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	caller, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	full, trimmed := strings.Builder{}, strings.Builder{}
+	found := false
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		line := fmt.Sprintf("%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		full.WriteString(line)
+		found = found || frame.Function == caller.Function
+		if found {
+			trimmed.WriteString(line)
+		}
+		if !more {
+			break
+		}
+	}
+	if !found {
+		return full.String()
+	}
+
+	return trimmed.String()
+}
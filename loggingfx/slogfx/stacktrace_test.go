@@ -0,0 +1,56 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/slogfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStacktrace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := slogfx.New(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     filename,
+		Stacktrace: true,
+	})
+	require.NoError(t, err)
+
+	log.Info("info message")
+	log.Error("error message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	info, failed := map[string]any{}, map[string]any{}
+	require.NoError(t, json.Unmarshal(lines[0], &info))
+	require.NoError(t, json.Unmarshal(lines[1], &failed))
+	assert.NotContains(t, info, slogfx.StackKey)
+	require.Contains(t, failed, slogfx.StackKey)
+	assert.Regexp(t, "^[^\n]+TestNewStacktrace\n", failed[slogfx.StackKey])
+}
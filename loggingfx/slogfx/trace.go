@@ -0,0 +1,67 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Keys of the attributes added by [WithTraceContext]
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// WithTraceContext takes a *slog.Logger and returns a new *slog.Logger
+// which adds the OpenTelemetry trace and span id of the context of
+// each message as [TraceIDKey] and [SpanIDKey] attributes if it carries
+// a valid span. Log using the *Context methods to pass the context:
+//
+//	log.InfoContext(r.Context(), "handling request")
+func WithTraceContext(log *slog.Logger) *slog.Logger {
+	return slog.New(&slogTraceContext{
+		Handler: log.Handler(),
+	})
+}
+
+// slogTraceContext wraps a slog.Handler adding trace context attributes
+type slogTraceContext struct {
+	slog.Handler
+}
+
+func (s *slogTraceContext) Handle(ctx context.Context, record slog.Record) error {
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		record = record.Clone()
+		record.AddAttrs(
+			slog.String(TraceIDKey, spanCtx.TraceID().String()),
+			slog.String(SpanIDKey, spanCtx.SpanID().String()),
+		)
+	}
+
+	return s.Handler.Handle(ctx, record)
+}
+
+func (s *slogTraceContext) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &slogTraceContext{Handler: s.Handler.WithAttrs(attrs)}
+}
+
+func (s *slogTraceContext) WithGroup(name string) slog.Handler {
+	return &slogTraceContext{Handler: s.Handler.WithGroup(name)}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package slogfx_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx/slogfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestWithTraceContext(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := slogfx.WithTraceContext(slog.New(slog.NewJSONHandler(out, nil))).
+		With(slog.String("component", "http"))
+
+	// decode returns the last logged message
+	decode := func() map[string]any {
+		entry := map[string]any{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &entry))
+		out.Reset()
+		return entry
+	}
+
+	traceID, err := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("0102030405060708")
+	require.NoError(t, err)
+	ctx := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		}))
+
+	log.InfoContext(ctx, "with span")
+	entry := decode()
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", entry[slogfx.TraceIDKey])
+	assert.Equal(t, "0102030405060708", entry[slogfx.SpanIDKey])
+	assert.Equal(t, "http", entry["component"])
+
+	log.InfoContext(context.Background(), "without span")
+	entry = decode()
+	assert.NotContains(t, entry, slogfx.TraceIDKey)
+	assert.NotContains(t, entry, slogfx.SpanIDKey)
+}
@@ -18,7 +18,10 @@ package zapfx
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"time"
 
 	"github.com/choopm/stdfx/loggingfx"
 	slogzap "github.com/samber/slog-zap/v2"
@@ -31,14 +34,53 @@ import (
 // Module returns a zap constructor and adapters to common loggers
 var Module = fx.Module(
 	"zap", fx.Provide(
-		New,
+		NewWithFlags,
 		ToSlog,
 		loggingfx.DefaultConfig,
+		loggingfx.NewFlags,
 	),
 )
 
 // New returns a new configured *zap.Logger
 func New(config loggingfx.Config) (*zap.Logger, error) {
+	output, err := loggingfx.OpenOutput(config.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := NewWithOutput(config, output)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// NewWithWriter returns a new configured *zap.Logger writing to w
+// instead of config.Output, e.g. to capture logs into a *bytes.Buffer.
+func NewWithWriter(config loggingfx.Config, w io.Writer) (*zap.Logger, error) {
+	return NewWithOutput(config, loggingfx.WriterOutput(w))
+}
+
+// NewWithOutput returns a new configured *zap.Logger writing to output
+// instead of config.Output. Use it to swap the output on config reloads.
+func NewWithOutput(config loggingfx.Config, output *loggingfx.Output) (*zap.Logger, error) {
+	core, zconfig, err := newCore(config, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return zap.New(core, options(config, zconfig)...), nil
+}
+
+// newCore returns the zapcore.Core of config writing to output
+// and the zap.Config it is based on
+func newCore(config loggingfx.Config, output *loggingfx.Output) (zapcore.Core, zap.Config, error) {
+	if err := loggingfx.ValidateTimeFormat(config.TimeFormat); err != nil {
+		return nil, zap.Config{}, err
+	}
+
 	var zconfig zap.Config
 
 	// choose production development
@@ -48,7 +90,7 @@ func New(config loggingfx.Config) (*zap.Logger, error) {
 	case "color", "human", "nice":
 		zconfig = zap.NewDevelopmentConfig()
 	default:
-		return nil, fmt.Errorf("unknown log.format: %s", config.Format)
+		return nil, zconfig, fmt.Errorf("unknown log.format: %s", config.Format)
 	}
 
 	// parse and set level
@@ -66,27 +108,93 @@ func New(config loggingfx.Config) (*zap.Logger, error) {
 	case "panic":
 		zconfig.Level.SetLevel(zapcore.PanicLevel)
 	default:
-		return nil, fmt.Errorf("unknown log.level: %s", config.Level)
+		return nil, zconfig, fmt.Errorf("unknown log.level: %s", config.Level)
 	}
 
-	// set output sink
-	zconfig.OutputPaths = []string{config.Output}
+	// remap standard field names
+	if len(config.FieldNames.Timestamp) > 0 {
+		zconfig.EncoderConfig.TimeKey = config.FieldNames.Timestamp
+	}
+	if len(config.FieldNames.Level) > 0 {
+		zconfig.EncoderConfig.LevelKey = config.FieldNames.Level
+	}
+	if len(config.FieldNames.Message) > 0 {
+		zconfig.EncoderConfig.MessageKey = config.FieldNames.Message
+	}
+
+	// encode timestamps in the configured location
+	if len(config.TimeZone) > 0 {
+		loc, err := config.Location()
+		if err != nil {
+			return nil, zconfig, err
+		}
+		encodeTime := zconfig.EncoderConfig.EncodeTime
+		zconfig.EncoderConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			encodeTime(t.In(loc), enc)
+		}
+	}
 
 	// if we are text based stdout/stderr, enable coloring
-	if config.Output == "stdout" || config.Output == "stderr" {
+	if !output.IsFile() {
 		switch config.Format {
 		case "color", "human", "nice":
 			zconfig.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 		}
 	}
 
-	// build logger
-	logger, err := zconfig.Build()
-	if err != nil {
-		return nil, err
+	// build logger writing to output like zconfig.Build() would do
+	encoder := zapcore.NewJSONEncoder(zconfig.EncoderConfig)
+	if zconfig.Encoding == "console" {
+		encoder = zapcore.NewConsoleEncoder(zconfig.EncoderConfig)
 	}
+	core := zapcore.NewCore(encoder, zapcore.Lock(output), zconfig.Level)
 
-	return logger, nil
+	return core, zconfig, nil
+}
+
+// options returns the zap.Options of a logger of config like
+// zconfig.Build() would do
+func options(config loggingfx.Config, zconfig zap.Config) []zap.Option {
+	// keep the stack trace defaults of zconfig.Build()
+	stackLevel := zapcore.ErrorLevel
+	opts := []zap.Option{
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.AddCaller(),
+	}
+	if sampled := sampler(config); sampled != nil {
+		opts = append(opts, zap.WrapCore(sampled))
+	}
+	if zconfig.Development {
+		stackLevel = zapcore.WarnLevel
+		opts = append(opts, zap.Development())
+	}
+	if config.Stacktrace {
+		stackLevel = min(stackLevel, zapcore.ErrorLevel)
+	}
+	opts = append(opts, zap.AddStacktrace(stackLevel))
+
+	return opts
+}
+
+// sampler returns a core wrapper sampling as configured by config or nil
+func sampler(config loggingfx.Config) func(zapcore.Core) zapcore.Core {
+	burst := max(config.SampleBurst, 0)
+	if config.SamplePeriod <= 0 {
+		burst = 0
+	}
+	if burst == 0 && config.SampleTick <= 0 {
+		return nil
+	}
+
+	period := config.SamplePeriod
+	if period <= 0 {
+		period = time.Second
+	}
+	tick := max(config.SampleTick, 0)
+
+	return func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, period, burst, tick)
+	}
 }
 
 // ToSlog provides a logging adapter for logging from slog to zap.
@@ -109,11 +217,30 @@ func ToSlog(log *zap.Logger) *slog.Logger {
 	}.NewZapHandler())
 }
 
-// ToFx provides a logging adapter for logging from fxevent.Logger to slog.
+// ToFx provides a logging adapter for logging from fxevent.Logger to zap.
 // Designed to be used as a parameter for with fx.WithLogger().
-// Unlike the other ToFx methods this one does not enforce DebugLevel.
+// Unlike the other ToFx methods this one does not enforce DebugLevel,
+// see [loggingfx.FxVerbosePolicy].
 func ToFx(log *zap.Logger) fxevent.Logger {
-	return &fxevent.ZapLogger{
-		Logger: log,
+	return ToFxWithPolicy(loggingfx.FxVerbosePolicy)(log)
+}
+
+// ToFxWithPolicy returns a [ToFx] adapter logging fx events at the levels
+// of policy. Designed to be used as a parameter for with fx.WithLogger().
+func ToFxWithPolicy(policy loggingfx.FxLevelPolicy) func(log *zap.Logger) fxevent.Logger {
+	return func(log *zap.Logger) fxevent.Logger {
+		logger := &fxevent.ZapLogger{
+			Logger: log,
+		}
+		logger.UseLogLevel(zapLevel(policy.Level(slog.LevelInfo)))
+		logger.UseErrorLevel(zapLevel(policy.Level(slog.LevelError)))
+
+		return logger
 	}
 }
+
+// zapLevel converts the slog.Level level to a zapcore.Level
+func zapLevel(level slog.Level) zapcore.Level {
+	// slog levels are spaced by 4 with info being 0 in both
+	return zapcore.Level(level / 4)
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zapfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zapfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+func TestNewFieldNames(t *testing.T) {
+	out := &bytes.Buffer{}
+	log, err := zapfx.NewWithWriter(loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Output: "stdout", // ignored
+		FieldNames: loggingfx.FieldNames{
+			Timestamp: "@timestamp",
+			Level:     "severity",
+			Message:   "msg",
+		},
+	}, out)
+	require.NoError(t, err)
+	log.Info("message")
+
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.NotEmpty(t, line["@timestamp"])
+	assert.Equal(t, "info", line["severity"])
+	assert.Equal(t, "message", line["msg"])
+	assert.NotContains(t, line, "ts")
+	assert.NotContains(t, line, "level")
+}
+
+func TestNewSampling(t *testing.T) {
+	// emit logs n identical messages using config and returns the lines written
+	emit := func(config loggingfx.Config, n int) int {
+		filename := filepath.Join(t.TempDir(), "out.log")
+		config.Level, config.Format, config.Output = "info", "json", filename
+
+		log, err := zapfx.New(config)
+		require.NoError(t, err)
+		for range n {
+			log.Info("same message")
+		}
+
+		b, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		return bytes.Count(b, []byte("\n"))
+	}
+
+	assert.Equal(t, 100, emit(loggingfx.Config{}, 100))
+	assert.Equal(t, 5, emit(loggingfx.Config{
+		SampleBurst:  5,
+		SamplePeriod: time.Hour,
+	}, 100))
+	// every 10th of the remaining 95
+	assert.Equal(t, 5+9, emit(loggingfx.Config{
+		SampleBurst:  5,
+		SamplePeriod: time.Hour,
+		SampleTick:   10,
+	}, 100))
+}
+
+func TestNewStacktrace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := zapfx.New(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     filename,
+		Stacktrace: true,
+	})
+	require.NoError(t, err)
+
+	log.Info("info message")
+	log.Error("error message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	info, failed := map[string]any{}, map[string]any{}
+	require.NoError(t, json.Unmarshal(lines[0], &info))
+	require.NoError(t, json.Unmarshal(lines[1], &failed))
+	assert.NotContains(t, info, "stacktrace")
+	assert.NotEmpty(t, failed["stacktrace"])
+}
+
+func TestNewStacktraceDefault(t *testing.T) {
+	// stacktraces returns whether info, warn and error logs of format
+	// carry a stack trace without enabling Stacktrace
+	stacktraces := func(format string) []bool {
+		out := &bytes.Buffer{}
+		log, err := zapfx.NewWithWriter(loggingfx.Config{
+			Level:  "info",
+			Format: format,
+		}, out)
+		require.NoError(t, err)
+
+		log.Info("info message")
+		log.Warn("warn message")
+		log.Error("error message")
+
+		// a stack trace follows its message up to the next message
+		messages := []string{"info message", "warn message", "error message"}
+		traced := []bool{}
+		for i, msg := range messages {
+			segment := out.String()[strings.Index(out.String(), msg):]
+			if i+1 < len(messages) {
+				segment = segment[:strings.Index(segment, messages[i+1])]
+			}
+			traced = append(traced, strings.Contains(segment, "testing.tRunner"))
+		}
+		return traced
+	}
+
+	assert.Equal(t, []bool{false, false, true}, stacktraces("json"))
+	assert.Equal(t, []bool{false, true, true}, stacktraces("color"))
+}
+
+func TestNewTimeZone(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := zapfx.New(loggingfx.Config{
+		Level:      "info",
+		Format:     "color",
+		Output:     filename,
+		TimeFormat: time.RFC3339,
+		TimeZone:   "Asia/Kolkata",
+	})
+	require.NoError(t, err)
+	log.Info("message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "+0530")
+
+	_, err = zapfx.New(loggingfx.Config{
+		Level:    "info",
+		Format:   "color",
+		Output:   filename,
+		TimeZone: "Mars/Olympus_Mons",
+	})
+	assert.ErrorContains(t, err, "invalid log.timeZone")
+}
+
+func TestNewWithWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log, err := zapfx.NewWithWriter(loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Output: "stdout", // ignored
+	}, out)
+	require.NoError(t, err)
+	log.Info("message", zap.String("key", "value"))
+
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "message", line["msg"])
+	assert.Equal(t, "value", line["key"])
+}
+
+func TestToFxWithPolicy(t *testing.T) {
+	// run starts and stops an app logging fx events using policy
+	// and returns the log output
+	run := func(policy loggingfx.FxLevelPolicy) string {
+		out := &bytes.Buffer{}
+		log, err := zapfx.NewWithWriter(loggingfx.Config{Level: "info", Format: "json"}, out)
+		require.NoError(t, err)
+
+		fxtest.New(t,
+			fx.Supply(log),
+			fx.WithLogger(zapfx.ToFxWithPolicy(policy)),
+		).RequireStart().RequireStop()
+
+		return out.String()
+	}
+
+	assert.Contains(t, run(loggingfx.FxVerbosePolicy), "started")
+	assert.NotContains(t, run(loggingfx.FxQuietPolicy), "started")
+	assert.NotContains(t, run(loggingfx.FxDebugPolicy), "started")
+}
@@ -17,8 +17,11 @@ limitations under the License.
 package zapfx
 
 import (
+	"errors"
+
 	"github.com/choopm/stdfx/configfx"
 	"github.com/choopm/stdfx/loggingfx"
+	"go.uber.org/fx"
 	"go.uber.org/zap"
 )
 
@@ -28,27 +31,71 @@ import (
 // The decorator will silently discard any errors since it is only decorating:
 // A user could run version command without providing a valid config path.
 // In such a case config file parsing would fail hence why errors are ignored.
+//
+// The decorated logger follows flags once they are applied, see
+// [NewWithFlags]. If log.reload is enabled, the output is reopened once
+// log.output changes in the config file, which is watched once the flags
+// are applied. The output is closed once lc stops, use
+// [configfx.CloseOnStop] to stop watching the config.
 func Decorator[T any](
+	lc fx.Lifecycle,
 	configProvider configfx.Provider[T],
+	flags *loggingfx.Flags,
 	logger *zap.Logger,
 ) (*zap.Logger, error) {
-	cfg, err := configProvider.Config()
+	// loggingConfig returns the logging config of cfg
+	loggingConfig := func() (loggingfx.Config, error) {
+		cfg, err := configProvider.Config()
+		if err != nil {
+			return loggingfx.Config{}, err
+		}
+
+		// check if cfg implements ConfigWithLogging
+		ctype, ok := any(cfg).(loggingfx.ConfigWithLogging)
+		if !ok {
+			return loggingfx.Config{}, errNoLoggingConfig
+		}
+
+		// cfg implements ConfigWithLogging and therefore
+		// has a custom func LoggingConfig(), use it to decorate
+		return ctype.LoggingConfig(), nil
+	}
+
+	config, err := loggingConfig()
 	if err != nil {
+		// not implementing or invalid, so return as it is
 		return logger, nil
 	}
 
-	// check if cfg implements ConfigWithLogging
-	if ctype, ok := any(cfg).(loggingfx.ConfigWithLogging); ok {
-		// cfg implements ConfigWithLogging and therefore
-		// has a custom func LoggingConfig(), use it to decorate:
-		log, err := New(ctype.LoggingConfig())
-		if err != nil {
-			return logger, nil
-		}
-
-		return log, nil
+	output, err := loggingfx.OpenOutput(flags.Override(config).Output)
+	if err != nil {
+		return logger, nil
+	}
+	log, err := newWithFlags(config, output, flags)
+	if err != nil {
+		_ = output.Close()
+		return logger, nil
 	}
 
-	// not implementing, so return as it is
-	return logger, nil
+	// follow changes of log.output once flags like --config-file are parsed
+	_ = flags.OnApply(func() error {
+		config, err := loggingConfig()
+		if err != nil || !config.Reload {
+			return nil
+		}
+		_, _ = configProvider.Config(
+			configfx.WithOnConfigChange(output.ReopenOnChange(func() (loggingfx.Config, error) {
+				config, err := loggingConfig()
+				return flags.Override(config), err
+			})),
+		)
+		return nil
+	})
+	lc.Append(fx.StopHook(output.Close))
+
+	return log, nil
 }
+
+// errNoLoggingConfig is returned if a config does not implement
+// [loggingfx.ConfigWithLogging]
+var errNoLoggingConfig = errors.New("config does not implement loggingfx.ConfigWithLogging")
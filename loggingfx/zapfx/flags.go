@@ -0,0 +1,155 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zapfx
+
+import (
+	"slices"
+	"sync/atomic"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewWithFlags returns a new configured *zap.Logger following the
+// level, format and output of flags once they are applied.
+// Options like the stack trace level keep using the format of config.
+func NewWithFlags(config loggingfx.Config, flags *loggingfx.Flags) (*zap.Logger, error) {
+	output, err := loggingfx.OpenOutput(flags.Override(config).Output)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := newWithFlags(config, output, flags)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// newWithFlags returns a *zap.Logger writing to output using the
+// core of config which is swapped once flags are applied
+func newWithFlags(
+	config loggingfx.Config,
+	output *loggingfx.Output,
+	flags *loggingfx.Flags,
+) (*zap.Logger, error) {
+	overridden := flags.Override(config)
+	core, zconfig, err := newCore(overridden, output)
+	if err != nil {
+		return nil, err
+	}
+	swap := &swapCore{root: &atomic.Pointer[rootCore]{}}
+	swap.root.Store(&rootCore{core: core})
+
+	err = flags.OnApply(func() error {
+		overridden := flags.Override(config)
+		if overridden.Output != config.Output {
+			if err := output.Reopen(overridden.Output); err != nil {
+				return err
+			}
+		}
+		core, _, err := newCore(overridden, output)
+		if err != nil {
+			return err
+		}
+		swap.root.Store(&rootCore{core: core})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return zap.New(swap, options(overridden, zconfig)...), nil
+}
+
+// rootCore is a core swapped by swapCore
+type rootCore struct {
+	core zapcore.Core
+}
+
+// withCore is the core of a rootCore with fields added
+type withCore struct {
+	root *rootCore
+	core zapcore.Core
+}
+
+// swapCore is a zapcore.Core delegating to the current root core which
+// is swapped while logging. Fields added using With are added to the
+// current root core once it is used.
+type swapCore struct {
+	root   *atomic.Pointer[rootCore]
+	fields []zapcore.Field
+
+	// current is the current root core with fields added
+	current atomic.Pointer[withCore]
+}
+
+// ensure swapCore implements zapcore.Core
+var _ zapcore.Core = &swapCore{}
+
+// core returns the current root core with the fields of c added
+func (c *swapCore) core() zapcore.Core {
+	root := c.root.Load()
+	if current := c.current.Load(); current != nil && current.root == root {
+		return current.core
+	}
+
+	core := root.core
+	if len(c.fields) > 0 {
+		core = core.With(c.fields)
+	}
+	c.current.Store(&withCore{root: root, core: core})
+
+	return core
+}
+
+// Enabled implements zapcore.LevelEnabler
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	return c.core().Enabled(level)
+}
+
+// Level returns the minimum enabled level of the current root core
+func (c *swapCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.core())
+}
+
+// With implements zapcore.Core
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &swapCore{
+		root:   c.root,
+		fields: append(slices.Clip(c.fields), fields...),
+	}
+}
+
+// Check implements zapcore.Core
+func (c *swapCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return c.core().Check(entry, checked)
+}
+
+// Write implements zapcore.Core
+func (c *swapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.core().Write(entry, fields)
+}
+
+// Sync implements zapcore.Core
+func (c *swapCore) Sync() error {
+	return c.core().Sync()
+}
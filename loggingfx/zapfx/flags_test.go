@@ -0,0 +1,62 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package zapfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zapfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewWithFlags(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	flags := loggingfx.NewFlags()
+	log, err := zapfx.NewWithFlags(loggingfx.Config{
+		Level:  "info",
+		Format: "text",
+		Output: filename,
+	}, flags)
+	require.NoError(t, err)
+	child := log.With(zap.String("key", "value"))
+	child.Debug("hidden")
+	assert.Equal(t, zapcore.InfoLevel, log.Level())
+
+	// applied flags swap level and format of existing loggers
+	require.NoError(t, flags.FlagSet().Parse([]string{
+		"--log-level", "debug", "--log-format", "json",
+	}))
+	require.NoError(t, flags.Apply())
+	assert.Equal(t, zapcore.DebugLevel, log.Level())
+	child.Debug("after")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "hidden")
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(b), &line))
+	assert.Equal(t, "debug", line["level"])
+	assert.Equal(t, "after", line["msg"])
+	assert.Equal(t, "value", line["key"])
+}
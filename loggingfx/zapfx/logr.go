@@ -0,0 +1,98 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zapfx
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ToLogr provides a logging adapter for logging from logr to zap.
+// Use this whenever something requires logr like controller-runtime.
+// Verbosity V(n) logs at zap level -n, V(0) is info and V(1) is debug.
+func ToLogr(log *zap.Logger) logr.Logger {
+	return logr.New(&zapSink{log: log})
+}
+
+// zapSink implements logr.LogSink writing to a *zap.Logger
+type zapSink struct {
+	log *zap.Logger
+}
+
+// ensure zapSink implements logr.LogSink and logr.CallDepthLogSink
+var (
+	_ logr.LogSink          = &zapSink{}
+	_ logr.CallDepthLogSink = &zapSink{}
+)
+
+// Init implements logr.LogSink
+func (s *zapSink) Init(info logr.RuntimeInfo) {
+	// skip the frames of logr and zapSink
+	s.log = s.log.WithOptions(zap.AddCallerSkip(info.CallDepth + 1))
+}
+
+// Enabled implements logr.LogSink
+func (s *zapSink) Enabled(level int) bool {
+	return s.log.Core().Enabled(zapcore.Level(-level))
+}
+
+// Info implements logr.LogSink
+func (s *zapSink) Info(level int, msg string, keysAndValues ...any) {
+	if ce := s.log.Check(zapcore.Level(-level), msg); ce != nil {
+		ce.Write(zapFields(keysAndValues)...)
+	}
+}
+
+// Error implements logr.LogSink
+func (s *zapSink) Error(err error, msg string, keysAndValues ...any) {
+	if ce := s.log.Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(append(zapFields(keysAndValues), zap.Error(err))...)
+	}
+}
+
+// WithValues implements logr.LogSink
+func (s *zapSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &zapSink{log: s.log.With(zapFields(keysAndValues)...)}
+}
+
+// WithName implements logr.LogSink
+func (s *zapSink) WithName(name string) logr.LogSink {
+	return &zapSink{log: s.log.Named(name)}
+}
+
+// WithCallDepth implements logr.CallDepthLogSink
+func (s *zapSink) WithCallDepth(depth int) logr.LogSink {
+	return &zapSink{log: s.log.WithOptions(zap.AddCallerSkip(depth))}
+}
+
+// zapFields converts logr key value pairs to zap fields.
+// A missing value of the last key is logged as "!BADKEY" like slog does.
+func zapFields(keysAndValues []any) []zap.Field {
+	fields := make([]zap.Field, 0, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fields = append(fields, zap.Any("!BADKEY", keysAndValues[i]))
+			break
+		}
+		fields = append(fields, zap.Any(fmt.Sprint(keysAndValues[i]), keysAndValues[i+1]))
+	}
+
+	return fields
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zapfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zapfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToLogr(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := zapfx.New(loggingfx.Config{
+		Level:  "debug",
+		Format: "json",
+		Output: filename,
+	})
+	require.NoError(t, err)
+
+	logger := zapfx.ToLogr(log).WithName("controller").WithValues("key", "value")
+	assert.True(t, logger.V(1).Enabled())
+	assert.False(t, logger.V(5).Enabled())
+
+	logger.Info("info message", "count", 1)
+	logger.V(1).Info("debug message")
+	logger.V(5).Info("dropped message")
+	logger.Error(errors.New("failure"), "error message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 3)
+
+	entries := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal(line, &entries[i]))
+		assert.Equal(t, "value", entries[i]["key"])
+		assert.Equal(t, "controller", entries[i]["logger"])
+	}
+
+	assert.Equal(t, "info", entries[0]["level"])
+	assert.Equal(t, "info message", entries[0]["msg"])
+	assert.EqualValues(t, 1, entries[0]["count"])
+	assert.Equal(t, "debug", entries[1]["level"])
+	assert.Equal(t, "debug message", entries[1]["msg"])
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.Equal(t, "error message", entries[2]["msg"])
+	assert.Equal(t, "failure", entries[2]["error"])
+}
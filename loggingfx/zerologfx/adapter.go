@@ -30,21 +30,70 @@ import (
 	"go.uber.org/fx/fxevent"
 
 	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/pkgerrors"
 )
 
 // Module returns a zerolog constructor and adapters to common loggers
 var Module = fx.Module(
 	"zerolog", fx.Provide(
-		New,
+		NewWithFlags,
 		ToSlog,
 		loggingfx.DefaultConfig,
+		loggingfx.NewFlags,
 	),
 )
 
 // New returns a new configured *zerolog.Logger
 func New(config loggingfx.Config) (*zerolog.Logger, error) {
-	// global options
-	zerolog.TimeFieldFormat = config.TimeFormat
+	output, err := loggingfx.OpenOutput(config.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := NewWithOutput(config, output)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// NewWithWriter returns a new configured *zerolog.Logger writing to w
+// instead of config.Output, e.g. to capture logs into a *bytes.Buffer.
+func NewWithWriter(config loggingfx.Config, w io.Writer) (*zerolog.Logger, error) {
+	return NewWithOutput(config, loggingfx.WriterOutput(w))
+}
+
+// NewWithOutput returns a new configured *zerolog.Logger writing to output
+// instead of config.Output. Use it to swap the output on config reloads.
+func NewWithOutput(config loggingfx.Config, sink *loggingfx.Output) (*zerolog.Logger, error) {
+	s, err := newSettings(config, sink)
+	if err != nil {
+		return nil, err
+	}
+
+	return newLogger(config, s.writer, s.level, s.timestamp, sampler(config)), nil
+}
+
+// settings are the settings of a logger depending on the level,
+// format and output of its config
+type settings struct {
+	level     zerolog.Level
+	writer    io.Writer
+	timestamp timestampHook
+}
+
+// newSettings returns the *settings of config writing to sink
+func newSettings(config loggingfx.Config, sink *loggingfx.Output) (*settings, error) {
+	if err := loggingfx.ValidateTimeFormat(config.TimeFormat); err != nil {
+		return nil, err
+	}
+
+	loc, err := config.Location()
+	if err != nil {
+		return nil, err
+	}
 
 	// enable/disable coloring for known formats
 	noColor := false
@@ -62,56 +111,213 @@ func New(config loggingfx.Config) (*zerolog.Logger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unknown log.level: %s", config.Level)
 	}
-
-	// build output sink
-	fileOutput := false
-	var output io.Writer = os.Stdout // nolint:ineffassign
-	switch config.Output {
-	case "stdout":
-		output = os.Stdout
-	case "stderr":
-		output = os.Stderr
-	default:
-		// config.Output is a filename
-		fileOutput = true
-
-		var err error
-		output, err = os.OpenFile(config.Output, 0644, os.ModeAppend)
-		if err != nil {
-			return nil, fmt.Errorf("unable to open log.output: %s", err)
+	for name, level := range config.Levels {
+		if _, err := zerolog.ParseLevel(level); err != nil {
+			return nil, fmt.Errorf("unknown log.levels.%s: %s", name, level)
 		}
-		// this file is closed automatically by go runtime through finalizers
 	}
 
+	// build output sink
+	var output io.Writer = sink
+	fileOutput := sink.IsFile()
+
 	// wrap output into a synchronnized writer (files are already synced)
 	if !fileOutput {
 		output = zerolog.SyncWriter(output)
 	}
 
+	// timestamps are written by timestampHook, field names and time
+	// format of zerolog are globals which are kept for console output
+	timestamp := timestampHook{
+		loc:    loc,
+		key:    zerolog.TimestampFieldName,
+		layout: zerolog.TimeFieldFormat,
+	}
+
 	// if we are text based stdout/stderr, wrap it into a ConsoleWriter
 	if !fileOutput && config.Format != "json" {
-		output = zerolog.ConsoleWriter{
-			Out:          output,
-			NoColor:      noColor,
-			TimeFormat:   config.TimeFormat,
-			TimeLocation: time.Local, // you may overwrite location using env TZ
+		output = consoleWriter(output, sink.File(), config, loc, noColor)
+	} else {
+		// json output uses the field names and time format of this logger
+		timestamp.key = fieldName(config.FieldNames.Timestamp, timestamp.key)
+		if config.TimeFormat != "" {
+			timestamp.layout = config.TimeFormat
+		}
+		output = newFieldNamesWriter(output, config.FieldNames)
+	}
+
+	return &settings{
+		level:     zlevel,
+		writer:    output,
+		timestamp: timestamp,
+	}, nil
+}
+
+// newLogger returns a *zerolog.Logger of config writing to w
+// at level using the timestamp hook and the sampler if not nil
+func newLogger(
+	config loggingfx.Config,
+	w io.Writer,
+	level zerolog.Level,
+	timestamp zerolog.Hook,
+	sampler zerolog.Sampler,
+) *zerolog.Logger {
+	zcontext := zerolog.New(w).
+		Level(level).
+		With()
+	if config.Stacktrace {
+		// the marshaler is a global of zerolog, keep any set by the app
+		if zerolog.ErrorStackMarshaler == nil {
+			zerolog.ErrorStackMarshaler = pkgerrors.MarshalStack
+		}
+		zcontext = zcontext.Stack()
+	}
+	// timestamps of this logger only use loc, unlike zerolog.TimestampFunc
+	logger := zcontext.Logger().Hook(timestamp)
+	if sampler != nil {
+		logger = logger.Sample(sampler)
+	}
+
+	return &logger
+}
+
+// timestampHook is a zerolog.Hook adding the time in loc to events
+// using key and layout, which is a go time layout or a unix time format
+// of zerolog like zerolog.TimeFormatUnixMs
+type timestampHook struct {
+	loc    *time.Location
+	key    string
+	layout string
+}
+
+// ensure timestampHook implements zerolog.Hook
+var _ zerolog.Hook = timestampHook{}
+
+// Run implements zerolog.Hook
+func (h timestampHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	t := time.Now().In(h.loc)
+	switch h.layout {
+	case zerolog.TimeFormatUnix:
+		e.Int64(h.key, t.Unix())
+	case zerolog.TimeFormatUnixMs:
+		e.Int64(h.key, t.UnixMilli())
+	case zerolog.TimeFormatUnixMicro:
+		e.Int64(h.key, t.UnixMicro())
+	case zerolog.TimeFormatUnixNano:
+		e.Int64(h.key, t.UnixNano())
+	default:
+		e.Str(h.key, t.Format(h.layout))
+	}
+}
+
+// sampler returns the zerolog.Sampler configured by config or nil
+func sampler(config loggingfx.Config) zerolog.Sampler {
+	var tick zerolog.Sampler
+	if config.SampleTick > 0 {
+		tick = &zerolog.BasicSampler{N: uint32(config.SampleTick)}
+	}
+	if config.SampleBurst <= 0 || config.SamplePeriod <= 0 {
+		return tick
+	}
+
+	return &zerolog.BurstSampler{
+		Burst:       uint32(config.SampleBurst),
+		Period:      config.SamplePeriod,
+		NextSampler: tick,
+	}
+}
+
+// LoggerFieldName is the key of the field holding the name of named loggers
+var LoggerFieldName = "logger"
+
+// Named returns a constructor of a child logger of log named name.
+// The child logs using the level of name found in [loggingfx.Config] Levels
+// of config, defaulting to the level of log.
+// Levels can be lower than the one of log to debug single components.
+// Usage example:
+//
+//	httpLog := zerologfx.Named(cfg.Logging, "http")(logger)
+func Named(config loggingfx.Config, name string) func(log *zerolog.Logger) *zerolog.Logger {
+	return func(log *zerolog.Logger) *zerolog.Logger {
+		child := namedLevel(config, log.With().Str(LoggerFieldName, name).Logger(), name)
+		return &child
+	}
+}
+
+// namedLevel returns log using the level of the logger name if configured.
+// The sampler is replaced as loggers of [NewWithFlags] enforce their
+// level using it.
+func namedLevel(config loggingfx.Config, log zerolog.Logger, name string) zerolog.Logger {
+	if level := config.LoggerLevel(name); len(config.Levels) > 0 && level != config.Level {
+		if zlevel, err := zerolog.ParseLevel(level); err == nil {
+			return log.Level(zlevel).Sample(sampler(config))
 		}
 	}
 
-	// build logger
-	logger := zerolog.New(output).
-		Level(zlevel).
-		With().
-		Timestamp().
-		// Caller().
-		Logger()
-	// // throttle to 10 messages per second
-	// Sample(&zerolog.BurstSampler{
-	// 	Burst:  10,
-	// 	Period: 1 * time.Second,
-	// })
+	return log
+}
 
-	return &logger, nil
+// consoleWriter returns a zerolog.ConsoleWriter writing to output
+// printing timestamps in loc.
+// Colors are disabled if console is not a terminal and the console
+// layout is adjusted to config.Console and the terminal width.
+func consoleWriter(
+	output io.Writer,
+	console *os.File,
+	config loggingfx.Config,
+	loc *time.Location,
+	noColor bool,
+) zerolog.ConsoleWriter {
+	width, terminal := terminalWidth(console)
+	if !terminal {
+		// never emit ANSI sequences into pipes
+		noColor = true
+	}
+	if config.Console.Width != 0 {
+		width = config.Console.Width
+	}
+	compact := config.Console.Compact ||
+		(width > 0 && width < loggingfx.CompactWidth)
+
+	writer := zerolog.ConsoleWriter{
+		Out:          output,
+		NoColor:      noColor,
+		TimeFormat:   config.TimeFormat,
+		TimeLocation: loc,
+	}
+	if compact {
+		writer.TimeFormat = time.TimeOnly
+	}
+
+	// shorten long field values to keep lines readable, errors are kept
+	if config.Console.Truncate {
+		maxValue := loggingfx.CompactWidth / 3
+		if width > 0 {
+			maxValue = max(width/3, 16)
+		}
+		writer.FormatFieldValue = func(i any) string {
+			return shorten(fmt.Sprint(i), maxValue)
+		}
+	}
+
+	return writer
+}
+
+// shorten truncates s to n runes marking it with an ellipsis
+func shorten(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// fieldName returns name or def if name is empty
+func fieldName(name, def string) string {
+	if len(name) == 0 {
+		return def
+	}
+	return name
 }
 
 // ToSlog provides a logging adapter for logging from slog to zerolog.
@@ -136,17 +342,18 @@ func ToSlog(log *zerolog.Logger) *slog.Logger {
 
 // ToFx provides a logging adapter for logging from fxevent.Logger to zerolog.
 // Designed to be used as a parameter for with fx.WithLogger().
-// It will rewrite all log levels to debug if other than error.
+// It will rewrite all log levels to debug if other than error,
+// see [loggingfx.FxQuietPolicy].
 func ToFx(log *zerolog.Logger) fxevent.Logger {
-	return &fxevent.SlogLogger{
-		Logger: slogfx.AtLevelMap(
-			ToSlog(log),
-			map[slog.Level]slog.Level{
-				slog.LevelDebug: slog.LevelDebug,
-				slog.LevelInfo:  slog.LevelDebug,
-				slog.LevelWarn:  slog.LevelDebug,
-				slog.LevelError: slog.LevelError,
-			},
-		),
+	return ToFxWithPolicy(loggingfx.FxQuietPolicy)(log)
+}
+
+// ToFxWithPolicy returns a [ToFx] adapter logging fx events at the levels
+// of policy. Designed to be used as a parameter for with fx.WithLogger().
+func ToFxWithPolicy(policy loggingfx.FxLevelPolicy) func(log *zerolog.Logger) fxevent.Logger {
+	return func(log *zerolog.Logger) fxevent.Logger {
+		return &fxevent.SlogLogger{
+			Logger: slogfx.AtLevelMap(ToSlog(log), policy),
+		}
 	}
 }
@@ -0,0 +1,312 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestNewSampling(t *testing.T) {
+	// emit logs n identical messages using config and returns the lines written
+	emit := func(config loggingfx.Config, n int) int {
+		filename := filepath.Join(t.TempDir(), "out.log")
+		config.Level, config.Format, config.Output = "info", "json", filename
+
+		log, err := zerologfx.New(config)
+		require.NoError(t, err)
+		for range n {
+			log.Info().Msg("same message")
+		}
+
+		b, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		return bytes.Count(b, []byte("\n"))
+	}
+
+	assert.Equal(t, 100, emit(loggingfx.Config{}, 100))
+	assert.Equal(t, 5, emit(loggingfx.Config{
+		SampleBurst:  5,
+		SamplePeriod: time.Hour,
+	}, 100))
+	// every 10th of the remaining 95 starting with the first
+	assert.Equal(t, 5+10, emit(loggingfx.Config{
+		SampleBurst:  5,
+		SamplePeriod: time.Hour,
+		SampleTick:   10,
+	}, 100))
+}
+
+func TestNewStacktrace(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := zerologfx.New(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     filename,
+		Stacktrace: true,
+	})
+	require.NoError(t, err)
+
+	log.Info().Msg("info message")
+	log.Error().Err(errors.New("failure")).Msg("error message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	info, failed := map[string]any{}, map[string]any{}
+	require.NoError(t, json.Unmarshal(lines[0], &info))
+	require.NoError(t, json.Unmarshal(lines[1], &failed))
+	assert.NotContains(t, info, "stack")
+	assert.NotEmpty(t, failed["stack"])
+}
+
+func TestNewTimeZone(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := zerologfx.New(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     filename,
+		TimeFormat: time.RFC3339,
+		TimeZone:   "Asia/Kolkata",
+	})
+	require.NoError(t, err)
+	log.Info().Msg("message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "+05:30")
+
+	_, err = zerologfx.New(loggingfx.Config{
+		Level:    "info",
+		Format:   "json",
+		Output:   filename,
+		TimeZone: "Mars/Olympus_Mons",
+	})
+	assert.ErrorContains(t, err, "invalid log.timeZone")
+
+	// the zone is kept per logger
+	out := &bytes.Buffer{}
+	utc, err := zerologfx.NewWithWriter(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		TimeFormat: time.RFC3339,
+		TimeZone:   "UTC",
+	}, out)
+	require.NoError(t, err)
+	utc.Info().Msg("message")
+	log.Info().Msg("message")
+	assert.Contains(t, out.String(), `Z"`)
+	b, err = os.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(b), "+05:30"))
+	assert.Equal(t, time.Local, zerolog.TimestampFunc().Location(), "global left untouched")
+}
+
+func TestNewFieldNames(t *testing.T) {
+	out := &bytes.Buffer{}
+	log, err := zerologfx.NewWithWriter(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     "stdout", // ignored
+		TimeFormat: time.RFC3339,
+		FieldNames: loggingfx.FieldNames{
+			Timestamp: "@timestamp",
+			Level:     "severity",
+			Message:   "msg",
+		},
+	}, out)
+	require.NoError(t, err)
+	log.Info().Str("quoted", `"message":"\"`).Msg(`say "message":`)
+
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.NotEmpty(t, line["@timestamp"])
+	assert.Equal(t, "info", line["severity"])
+	assert.Equal(t, `say "message":`, line["msg"])
+	assert.Equal(t, `"message":"\"`, line["quoted"])
+	assert.NotContains(t, line, "time")
+	assert.NotContains(t, line, "level")
+	assert.NotContains(t, line, "message")
+
+	// other loggers keep the default names
+	out.Reset()
+	def, err := zerologfx.NewWithWriter(loggingfx.Config{Level: "info", Format: "json"}, out)
+	require.NoError(t, err)
+	def.Info().Msg("message")
+	line = map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "message", line["message"])
+	assert.NotEmpty(t, line["time"])
+
+	assert.Equal(t, "time", zerolog.TimestampFieldName, "global left untouched")
+	assert.Equal(t, "level", zerolog.LevelFieldName, "global left untouched")
+	assert.Equal(t, "message", zerolog.MessageFieldName, "global left untouched")
+}
+
+func TestNewUnixTimeFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	log, err := zerologfx.NewWithWriter(loggingfx.Config{
+		Level:      "info",
+		Format:     "json",
+		Output:     "stdout", // ignored
+		TimeFormat: zerolog.TimeFormatUnixMs,
+	}, out)
+	require.NoError(t, err)
+	log.Info().Msg("message")
+
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.IsType(t, float64(0), line["time"])
+	assert.Greater(t, line["time"], float64(time.Now().Add(-time.Hour).UnixMilli()))
+	assert.Equal(t, time.RFC3339, zerolog.TimeFieldFormat, "global left untouched")
+}
+
+func TestNewWithWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log, err := zerologfx.NewWithWriter(loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Output: "stdout", // ignored
+	}, out)
+	require.NoError(t, err)
+	log.Info().Str("key", "value").Msg("message")
+
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "message", line["message"])
+	assert.Equal(t, "value", line["key"])
+}
+
+func TestToFxWithPolicy(t *testing.T) {
+	// run starts and stops an app logging fx events using policy
+	// and returns the log output
+	run := func(policy loggingfx.FxLevelPolicy) string {
+		out := &bytes.Buffer{}
+		log, err := zerologfx.NewWithWriter(loggingfx.Config{Level: "info", Format: "json"}, out)
+		require.NoError(t, err)
+
+		fxtest.New(t,
+			fx.Supply(log),
+			fx.WithLogger(zerologfx.ToFxWithPolicy(policy)),
+		).RequireStart().RequireStop()
+
+		return out.String()
+	}
+
+	assert.Contains(t, run(loggingfx.FxVerbosePolicy), "started")
+	assert.NotContains(t, run(loggingfx.FxQuietPolicy), "started")
+	assert.NotContains(t, run(loggingfx.FxDebugPolicy), "started")
+}
+
+func TestConsoleWriter(t *testing.T) {
+	long := strings.Repeat("x", 60)
+
+	// emit returns the console output of logging a long field and error
+	emit := func(console loggingfx.Console) string {
+		out := &bytes.Buffer{}
+		log, err := zerologfx.NewWithWriter(loggingfx.Config{
+			Level:      "info",
+			Format:     "color",
+			TimeFormat: time.RFC3339,
+			Console:    console,
+		}, out)
+		require.NoError(t, err)
+		log.Info().
+			Str("field", long).
+			Err(errors.New(long)).
+			Msg("message")
+
+		return out.String()
+	}
+
+	// writers are no terminal, so colors are disabled
+	plain := emit(loggingfx.Console{})
+	assert.NotContains(t, plain, "\x1b[")
+	assert.Regexp(t, `^\d{4}-\d\d-\d\dT`, plain)
+	assert.Contains(t, plain, "field="+long)
+
+	// compact shortens timestamps only
+	compact := emit(loggingfx.Console{Compact: true})
+	assert.Regexp(t, `^\d\d:\d\d:\d\d `, compact)
+	assert.Contains(t, compact, "field="+long)
+
+	// truncation shortens field values but keeps errors
+	truncated := emit(loggingfx.Console{Width: 60, Truncate: true})
+	assert.Contains(t, truncated, "field="+strings.Repeat("x", 19)+"…")
+	assert.Contains(t, truncated, "error="+long)
+	assert.Contains(t, emit(loggingfx.Console{Truncate: true}),
+		"field="+strings.Repeat("x", loggingfx.CompactWidth/3-1)+"…")
+}
+
+func TestNamed(t *testing.T) {
+	out := &bytes.Buffer{}
+	config := loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Levels: map[string]string{"http": "debug"},
+	}
+	log, err := zerologfx.NewWithWriter(config, out)
+	require.NoError(t, err)
+
+	// building another logger must not change the levels of named loggers
+	_, err = zerologfx.NewWithWriter(loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Levels: map[string]string{"http": "error"},
+	}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	httpLog := zerologfx.Named(config, "http")(log)
+	clientLog := zerologfx.Named(config, "http.client")(log)
+	dbLog := zerologfx.Named(config, "db")(log)
+
+	httpLog.Debug().Msg("http debug")
+	clientLog.Debug().Msg("client debug")
+	dbLog.Debug().Msg("db debug")
+	dbLog.Info().Msg("db info")
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	entries := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal(line, &entries[i]))
+	}
+	assert.Equal(t, "http", entries[0]["logger"])
+	assert.Equal(t, "http debug", entries[0]["message"])
+	assert.Equal(t, "http.client", entries[1]["logger"])
+	assert.Equal(t, "client debug", entries[1]["message"])
+	assert.Equal(t, "db", entries[2]["logger"])
+	assert.Equal(t, "db info", entries[2]["message"])
+}
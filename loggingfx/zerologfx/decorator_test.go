@@ -0,0 +1,163 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologfx_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx/configfx"
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+type decoratedConfig struct {
+	Logging loggingfx.Config `mapstructure:"log"`
+}
+
+// LoggingConfig implements loggingfx.ConfigWithLogging
+func (c *decoratedConfig) LoggingConfig() loggingfx.Config {
+	return c.Logging
+}
+
+// fileSource is a configfx.Source[T] reading an explicit config file
+type fileSource[T any] struct {
+	filename string
+}
+
+// Viper implements configfx.Source[T]
+func (s *fileSource[T]) Viper(opts ...viper.Option) *viper.Viper {
+	v := viper.NewWithOptions(opts...)
+	v.SetConfigFile(s.filename)
+	return v
+}
+
+func TestDecoratorReopensOutput(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+
+	// writeConfig writes the config logging to output
+	writeConfig := func(output string) {
+		require.NoError(t, os.WriteFile(filename,
+			[]byte("log:\n  format: json\n  reload: true\n  output: "+output+"\n"), 0644))
+	}
+	writeConfig(first)
+
+	provider := configfx.NewProvider[decoratedConfig](
+		&fileSource[decoratedConfig]{filename: filename},
+		slog.New(slog.DiscardHandler),
+	)
+	nop := zerolog.Nop()
+	lc := fxtest.NewLifecycle(t)
+	flags := loggingfx.NewFlags()
+	log, err := zerologfx.Decorator(lc, provider, flags, &nop)
+	require.NoError(t, err)
+	configfx.CloseOnStop(lc, provider)
+	lc.RequireStart()
+	require.NoError(t, flags.Apply())
+	log.Info().Msg("before")
+
+	// changing log.output redirects subsequent logs
+	writeConfig(second)
+	require.Eventually(t, func() bool {
+		log.Info().Msg("waiting")
+		b, _ := os.ReadFile(second)
+		return len(b) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	log.Info().Msg("after")
+
+	b, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "before")
+	assert.NotContains(t, string(b), "after")
+	b, err = os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "after")
+
+	// openFiles returns the files opened by this process
+	openFiles := func() []string {
+		fds, err := os.ReadDir("/proc/self/fd")
+		require.NoError(t, err)
+		files := []string{}
+		for _, fd := range fds {
+			target, _ := os.Readlink(filepath.Join("/proc/self/fd", fd.Name()))
+			files = append(files, target)
+		}
+		return files
+	}
+
+	if runtime.GOOS == "linux" {
+		// the previous file has been closed
+		assert.NotContains(t, openFiles(), first, "previous log.output is still open")
+	}
+
+	// stopping closes the output and CloseOnStop stops watching the config
+	lc.RequireStop()
+	if runtime.GOOS == "linux" {
+		assert.NotContains(t, openFiles(), second, "log.output is still open")
+	}
+	writeConfig(first)
+	time.Sleep(200 * time.Millisecond)
+	log.Info().Msg("stopped")
+	for _, filename := range []string{first, second} {
+		b, err = os.ReadFile(filename)
+		require.NoError(t, err)
+		assert.NotContains(t, string(b), "stopped", "log.output reopened after stop")
+	}
+}
+
+func TestDecoratorWithoutReload(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.yaml")
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	require.NoError(t, os.WriteFile(filename,
+		[]byte("log:\n  format: json\n  output: "+first+"\n"), 0644))
+
+	provider := configfx.NewProvider[decoratedConfig](
+		&fileSource[decoratedConfig]{filename: filename},
+		slog.New(slog.DiscardHandler),
+	)
+	nop := zerolog.Nop()
+	lc := fxtest.NewLifecycle(t)
+	flags := loggingfx.NewFlags()
+	log, err := zerologfx.Decorator(lc, provider, flags, &nop)
+	require.NoError(t, err)
+	lc.RequireStart()
+	defer lc.RequireStop()
+	require.NoError(t, flags.Apply())
+
+	// the config is not watched unless log.reload is enabled
+	require.NoError(t, os.WriteFile(filename,
+		[]byte("log:\n  format: json\n  output: "+second+"\n"), 0644))
+	time.Sleep(200 * time.Millisecond)
+	log.Info().Msg("after")
+
+	b, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "after")
+	assert.NoFileExists(t, second)
+}
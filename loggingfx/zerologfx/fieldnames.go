@@ -0,0 +1,130 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologfx
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/rs/zerolog"
+)
+
+// fieldNamesWriter renames the level and message keys of json events.
+// zerolog only knows global field names, but always writes the level as
+// the first and the message as the last field of an event, which allows
+// renaming them per logger without parsing the whole event.
+type fieldNamesWriter struct {
+	w io.Writer
+
+	levelFrom, levelTo     []byte
+	messageFrom, messageTo []byte
+}
+
+// ensure fieldNamesWriter implements zerolog.LevelWriter
+var _ zerolog.LevelWriter = fieldNamesWriter{}
+
+// newFieldNamesWriter wraps w renaming the level and message keys
+// from the zerolog globals to names. It returns w if nothing is renamed.
+func newFieldNamesWriter(w io.Writer, names loggingfx.FieldNames) io.Writer {
+	level := fieldName(names.Level, zerolog.LevelFieldName)
+	message := fieldName(names.Message, zerolog.MessageFieldName)
+	if level == zerolog.LevelFieldName && message == zerolog.MessageFieldName {
+		return w
+	}
+
+	return fieldNamesWriter{
+		w:           w,
+		levelFrom:   jsonKey("{", zerolog.LevelFieldName),
+		levelTo:     jsonKey("{", level),
+		messageFrom: jsonKey("", zerolog.MessageFieldName),
+		messageTo:   jsonKey("", message),
+	}
+}
+
+// jsonKey returns the quoted key followed by a colon and prefixed by prefix
+func jsonKey(prefix, key string) []byte {
+	return []byte(prefix + strconv.Quote(key) + ":")
+}
+
+// Write implements io.Writer
+func (w fieldNamesWriter) Write(p []byte) (int, error) {
+	if _, err := w.w.Write(w.rename(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (w fieldNamesWriter) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	lw, ok := w.w.(zerolog.LevelWriter)
+	if !ok {
+		return w.Write(p)
+	}
+	if _, err := lw.WriteLevel(l, w.rename(p)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// rename returns a copy of the event p with renamed keys
+func (w fieldNamesWriter) rename(p []byte) []byte {
+	out := make([]byte, 0, len(p)+len(w.levelTo)+len(w.messageTo))
+
+	// the level is the first field of an event
+	if bytes.HasPrefix(p, w.levelFrom) {
+		out = append(out, w.levelTo...)
+		p = p[len(w.levelFrom):]
+	}
+
+	// the message is the last field, find the start of its string value
+	i := messageStart(p)
+	if i < 0 || !bytes.HasSuffix(p[:i], w.messageFrom) {
+		return append(out, p...)
+	}
+	key := i - len(w.messageFrom)
+	if key == 0 || (p[key-1] != ',' && p[key-1] != '{') {
+		return append(out, p...)
+	}
+	out = append(out, p[:key]...)
+	out = append(out, w.messageTo...)
+	return append(out, p[i:]...)
+}
+
+// messageStart returns the index of the opening quote of the last string
+// value of the json event p or -1 if the last value is not a string.
+func messageStart(p []byte) int {
+	end := bytes.LastIndexByte(p, '}')
+	if end < 1 || p[end-1] != '"' {
+		return -1
+	}
+	for i := end - 2; i >= 0; i-- {
+		if p[i] != '"' {
+			continue
+		}
+		// an odd number of backslashes escapes the quote
+		n := 0
+		for j := i - 1; j >= 0 && p[j] == '\\'; j-- {
+			n++
+		}
+		if n%2 == 0 {
+			return i
+		}
+	}
+	return -1
+}
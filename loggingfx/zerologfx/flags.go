@@ -0,0 +1,134 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologfx
+
+import (
+	"sync/atomic"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/rs/zerolog"
+)
+
+// NewWithFlags returns a new configured *zerolog.Logger following the
+// level, format and output of flags once they are applied.
+// The logger logs at zerolog.TraceLevel, its level is enforced by a sampler.
+func NewWithFlags(config loggingfx.Config, flags *loggingfx.Flags) (*zerolog.Logger, error) {
+	output, err := loggingfx.OpenOutput(flags.Override(config).Output)
+	if err != nil {
+		return nil, err
+	}
+
+	logger, err := newWithFlags(config, output, flags)
+	if err != nil {
+		_ = output.Close()
+		return nil, err
+	}
+
+	return logger, nil
+}
+
+// newWithFlags returns a *zerolog.Logger writing to sink using the
+// settings of config which are swapped once flags are applied
+func newWithFlags(
+	config loggingfx.Config,
+	sink *loggingfx.Output,
+	flags *loggingfx.Flags,
+) (*zerolog.Logger, error) {
+	s, err := newSettings(flags.Override(config), sink)
+	if err != nil {
+		return nil, err
+	}
+	swap := &swapSettings{}
+	swap.settings.Store(s)
+
+	err = flags.OnApply(func() error {
+		overridden := flags.Override(config)
+		if overridden.Output != config.Output {
+			if err := sink.Reopen(overridden.Output); err != nil {
+				return err
+			}
+		}
+		s, err := newSettings(overridden, sink)
+		if err != nil {
+			return err
+		}
+		swap.settings.Store(s)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newLogger(config, swap, zerolog.TraceLevel, swap, levelSampler{
+		swap: swap,
+		next: sampler(config),
+	}), nil
+}
+
+// swapSettings holds the *settings of a logger which are swapped
+// while logging. It writes to the writer and adds the timestamp
+// of the current settings.
+type swapSettings struct {
+	settings atomic.Pointer[settings]
+}
+
+// ensure swapSettings implements zerolog.LevelWriter and zerolog.Hook
+var (
+	_ zerolog.LevelWriter = &swapSettings{}
+	_ zerolog.Hook        = &swapSettings{}
+)
+
+// Write implements io.Writer
+func (s *swapSettings) Write(p []byte) (int, error) {
+	return s.settings.Load().writer.Write(p)
+}
+
+// WriteLevel implements zerolog.LevelWriter
+func (s *swapSettings) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	if lw, ok := s.settings.Load().writer.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return s.Write(p)
+}
+
+// Run implements zerolog.Hook
+func (s *swapSettings) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+	s.settings.Load().timestamp.Run(e, level, msg)
+}
+
+// levelSampler is a zerolog.Sampler dropping events below the level of
+// the current settings of swap before asking next if not nil.
+// Unlike the level of a zerolog.Logger it can be swapped while logging.
+type levelSampler struct {
+	swap *swapSettings
+	next zerolog.Sampler
+}
+
+// ensure levelSampler implements zerolog.Sampler
+var _ zerolog.Sampler = levelSampler{}
+
+// Sample implements zerolog.Sampler
+func (s levelSampler) Sample(lvl zerolog.Level) bool {
+	if lvl < s.swap.settings.Load().level {
+		return false
+	}
+	if s.next != nil {
+		return s.next.Sample(lvl)
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package zerologfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithFlags(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.log"), filepath.Join(dir, "second.log")
+	config := loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Output: first,
+		Levels: map[string]string{"db": "warn"},
+	}
+	flags := loggingfx.NewFlags()
+	log, err := zerologfx.NewWithFlags(config, flags)
+	require.NoError(t, err)
+	dbLog := zerologfx.Named(config, "db")(log)
+	log.Debug().Msg("hidden")
+	log.Info().Msg("before")
+
+	// applied flags swap level and output of existing loggers
+	require.NoError(t, flags.FlagSet().Parse([]string{
+		"--log-level", "debug", "--log-output", second,
+	}))
+	require.NoError(t, flags.Apply())
+	log.Debug().Msg("after")
+	dbLog.Info().Msg("db hidden")
+	dbLog.Warn().Msg("db warn")
+
+	b, err := os.ReadFile(first)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "hidden")
+	assert.Contains(t, string(b), "before")
+	assert.NotContains(t, string(b), "after")
+
+	b, err = os.ReadFile(second)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "after")
+	assert.Contains(t, string(b), "db warn")
+	assert.NotContains(t, string(b), "db hidden")
+	line := map[string]any{}
+	require.NoError(t, json.Unmarshal(bytes.SplitN(b, []byte("\n"), 2)[0], &line))
+	assert.Equal(t, "debug", line["level"])
+	assert.Equal(t, "after", line["message"])
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologfx
+
+import (
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/go-logr/logr"
+	"github.com/rs/zerolog"
+)
+
+// ToLogr provides a logging adapter for logging from logr to zerolog.
+// Use this whenever something requires logr like controller-runtime.
+// Verbosity V(n) logs at zerolog level 1-n, V(0) is info and V(1) is debug.
+// Names of logr.Logger.WithName are joined by dots.
+func ToLogr(log *zerolog.Logger) logr.Logger {
+	return ToLogrWithConfig(loggingfx.Config{})(log)
+}
+
+// ToLogrWithConfig returns a [ToLogr] adapter using the levels of
+// config for names of logr.Logger.WithName like [Named] loggers do.
+func ToLogrWithConfig(config loggingfx.Config) func(log *zerolog.Logger) logr.Logger {
+	return func(log *zerolog.Logger) logr.Logger {
+		return logr.New(&zerologSink{log: *log, config: config})
+	}
+}
+
+// zerologSink implements logr.LogSink writing to a zerolog.Logger
+type zerologSink struct {
+	log    zerolog.Logger
+	name   string
+	config loggingfx.Config
+}
+
+// ensure zerologSink implements logr.LogSink
+var _ logr.LogSink = &zerologSink{}
+
+// Init implements logr.LogSink
+func (s *zerologSink) Init(logr.RuntimeInfo) {}
+
+// Enabled implements logr.LogSink
+func (s *zerologSink) Enabled(level int) bool {
+	zlevel := zerolog.Level(1 - level)
+	return zlevel >= s.log.GetLevel() && zlevel >= zerolog.GlobalLevel()
+}
+
+// Info implements logr.LogSink
+func (s *zerologSink) Info(level int, msg string, keysAndValues ...any) {
+	s.named(s.log.WithLevel(zerolog.Level(1 - level))).
+		Fields(keysAndValues).
+		Msg(msg)
+}
+
+// Error implements logr.LogSink
+func (s *zerologSink) Error(err error, msg string, keysAndValues ...any) {
+	s.named(s.log.Error()).
+		Err(err).
+		Fields(keysAndValues).
+		Msg(msg)
+}
+
+// WithValues implements logr.LogSink
+func (s *zerologSink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &zerologSink{
+		log:    s.log.With().Fields(keysAndValues).Logger(),
+		name:   s.name,
+		config: s.config,
+	}
+}
+
+// WithName implements logr.LogSink
+func (s *zerologSink) WithName(name string) logr.LogSink {
+	if len(s.name) > 0 {
+		name = s.name + "." + name
+	}
+
+	return &zerologSink{
+		log:    namedLevel(s.config, s.log, name),
+		name:   name,
+		config: s.config,
+	}
+}
+
+// named adds the name of s to event
+func (s *zerologSink) named(event *zerolog.Event) *zerolog.Event {
+	if len(s.name) == 0 {
+		return event
+	}
+	return event.Str(LoggerFieldName, s.name)
+}
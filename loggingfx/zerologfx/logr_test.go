@@ -0,0 +1,90 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package zerologfx_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/choopm/stdfx/loggingfx"
+	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToLogr(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "out.log")
+	log, err := zerologfx.New(loggingfx.Config{
+		Level:  "debug",
+		Format: "json",
+		Output: filename,
+	})
+	require.NoError(t, err)
+
+	logger := zerologfx.ToLogr(log).WithName("controller").WithValues("key", "value")
+	assert.True(t, logger.V(1).Enabled())
+	assert.False(t, logger.V(5).Enabled())
+
+	logger.Info("info message", "count", 1)
+	logger.V(1).Info("debug message")
+	logger.V(5).Info("dropped message")
+	logger.Error(errors.New("failure"), "error message")
+
+	b, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 3)
+
+	entries := make([]map[string]any, len(lines))
+	for i, line := range lines {
+		require.NoError(t, json.Unmarshal(line, &entries[i]))
+		assert.Equal(t, "value", entries[i]["key"])
+		assert.Equal(t, "controller", entries[i]["logger"])
+	}
+
+	assert.Equal(t, "info", entries[0]["level"])
+	assert.Equal(t, "info message", entries[0]["message"])
+	assert.EqualValues(t, 1, entries[0]["count"])
+	assert.Equal(t, "debug", entries[1]["level"])
+	assert.Equal(t, "debug message", entries[1]["message"])
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.Equal(t, "error message", entries[2]["message"])
+	assert.Equal(t, "failure", entries[2]["error"])
+}
+
+func TestToLogrWithConfig(t *testing.T) {
+	out := &bytes.Buffer{}
+	config := loggingfx.Config{
+		Level:  "info",
+		Format: "json",
+		Levels: map[string]string{"controller": "debug"},
+	}
+	log, err := zerologfx.NewWithWriter(config, out)
+	require.NoError(t, err)
+
+	logger := zerologfx.ToLogrWithConfig(config)(log)
+	assert.False(t, logger.V(1).Enabled())
+	assert.True(t, logger.WithName("controller").V(1).Enabled())
+	assert.True(t, logger.WithName("controller").WithName("reconciler").V(1).Enabled())
+
+	// ToLogr ignores levels of names
+	assert.False(t, zerologfx.ToLogr(log).WithName("controller").V(1).Enabled())
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsfx
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/choopm/stdfx/internal/httpserver"
+	"github.com/creasty/defaults"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/fx"
+)
+
+// Module provides a *prometheus.Registry including Go runtime and
+// process collectors and serves it on the address found in [Config].
+// Usage example:
+//
+//	metricsfx.Module,
+//	fx.Invoke(func(registry *prometheus.Registry) {
+//		registry.MustRegister(myCounter)
+//	}),
+//
+// Replace the default [Config] to change the address:
+//
+//	fx.Replace(metricsfx.Config{Host: "127.0.0.1", Port: 9090, Path: "/metrics"}),
+var Module = fx.Module(
+	"metrics",
+	fx.Provide(
+		NewRegistry,
+		DefaultConfig,
+	),
+	fx.Invoke(Serve),
+)
+
+// Config defines the configuration of the metrics endpoint
+type Config struct {
+	// Host is the listening host of the metrics server
+	Host string `mapstructure:"host" default:"0.0.0.0"`
+
+	// Port is the listening port of the metrics server
+	Port int `mapstructure:"port" default:"9090"`
+
+	// Path is the http path serving the metrics
+	Path string `mapstructure:"path" default:"/metrics"`
+}
+
+// Address returns the listening address of c
+func (c Config) Address() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// DefaultConfig returns the default metrics configuration
+func DefaultConfig() (Config, error) {
+	config := Config{}
+	if err := defaults.Set(&config); err != nil {
+		return config, fmt.Errorf("setting defaults: %s", err)
+	}
+
+	return config, nil
+}
+
+// NewRegistry returns a *prometheus.Registry including
+// Go runtime and process collectors.
+func NewRegistry() (*prometheus.Registry, error) {
+	registry := prometheus.NewRegistry()
+
+	err := registry.Register(collectors.NewGoCollector())
+	if err != nil {
+		return nil, fmt.Errorf("register go collector: %s", err)
+	}
+	err = registry.Register(collectors.NewProcessCollector(
+		collectors.ProcessCollectorOpts{},
+	))
+	if err != nil {
+		return nil, fmt.Errorf("register process collector: %s", err)
+	}
+
+	return registry, nil
+}
+
+// Serve starts a http server on config.Address() serving
+// registry on config.Path during the fx lifecycle.
+// Serving errors after the start are logged and shut down the app.
+func Serve(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	config Config,
+	registry *prometheus.Registry,
+	log *slog.Logger,
+) {
+	mux := http.NewServeMux()
+	mux.Handle(config.Path, promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(log.Handler(), slog.LevelError),
+	}))
+	server := &http.Server{
+		Addr:    config.Address(),
+		Handler: mux,
+	}
+
+	lc.Append(httpserver.Hook("metrics server", server, 0, shutdowner,
+		log.With(slog.String("path", config.Path))))
+}
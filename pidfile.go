@@ -0,0 +1,143 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// ErrPIDFileInUse is returned by [PIDFile] if the pid file
+// belongs to another running process
+var ErrPIDFileInUse = errors.New("pid file is in use")
+
+// PIDFile might be used with [fx.Invoke] to write the pid of the process
+// to path on start and remove it on stop. Starting fails if path names
+// another running process, stale pid files of dead processes are reclaimed.
+// Pid files not holding a pid are considered in use by a starting process
+// and need to be removed manually if left behind.
+// Usage example:
+//
+//	fx.Invoke(stdfx.PIDFile("/run/myproject.pid")),
+func PIDFile(path string) func(lc fx.Lifecycle) error {
+	return pidFile(path, processAlive)
+}
+
+// pidFile implements [PIDFile] using alive to check for running processes
+func pidFile(path string, alive func(pid int) bool) func(lc fx.Lifecycle) error {
+	return func(lc fx.Lifecycle) error {
+		pid := os.Getpid()
+
+		lc.Append(fx.Hook{
+			OnStart: func(_ context.Context) error {
+				return writePIDFile(path, pid, alive)
+			},
+			OnStop: func(_ context.Context) error {
+				// only remove the file if it still belongs to us
+				if owner, err := readPIDFile(path); err != nil || owner != pid {
+					return nil
+				}
+				if err := os.Remove(path); err != nil {
+					return fmt.Errorf("pid file: %s", err)
+				}
+				return nil
+			},
+		})
+
+		return nil
+	}
+}
+
+// writePIDFile writes pid to path unless it belongs to another process
+// considered alive
+func writePIDFile(path string, pid int, alive func(pid int) bool) error {
+	owner, err := readPIDFileRetry(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// fresh start
+
+	case err != nil:
+		// another process might have created it without writing its pid yet
+		return fmt.Errorf("%w: %s", ErrPIDFileInUse, err)
+
+	case owner != pid && alive(owner):
+		return fmt.Errorf("%w: %q belongs to running process %d", ErrPIDFileInUse, path, owner)
+
+	default:
+		// reclaim stale pid files
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("pid file: %s", err)
+		}
+	}
+
+	// fail if another process created the file in the meantime
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if errors.Is(err, os.ErrExist) {
+		return fmt.Errorf("%w: %q was created by another process", ErrPIDFileInUse, path)
+	}
+	if err != nil {
+		return fmt.Errorf("pid file: %s", err)
+	}
+	_, err = f.WriteString(strconv.Itoa(pid) + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("pid file: %s", err)
+	}
+
+	return nil
+}
+
+// pidFileRetry is the duration of reading unreadable pid files again
+// as another process might be writing its pid to it
+const pidFileRetry = 100 * time.Millisecond
+
+// readPIDFileRetry returns the pid stored at path or error
+// retrying to read it for pidFileRetry unless it does not exist
+func readPIDFileRetry(path string) (int, error) {
+	deadline := time.Now().Add(pidFileRetry)
+	for {
+		pid, err := readPIDFile(path)
+		if err == nil || errors.Is(err, os.ErrNotExist) || time.Now().After(deadline) {
+			return pid, err
+		}
+		time.Sleep(pidFileRetry / 10)
+	}
+}
+
+// readPIDFile returns the pid stored at path or error
+func readPIDFile(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0, fmt.Errorf("invalid pid file %q: %s", path, err)
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestPIDFile(t *testing.T) {
+	const otherPID = 4242
+	self := strconv.Itoa(os.Getpid()) + "\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		empty    bool
+		alive    bool
+		err      error
+	}{
+		{name: "fresh start"},
+		{name: "stale file", existing: strconv.Itoa(otherPID), alive: false},
+		{name: "garbage file", existing: "garbage", err: ErrPIDFileInUse},
+		{name: "empty file", empty: true, err: ErrPIDFileInUse},
+		{name: "live process", existing: strconv.Itoa(otherPID), alive: true, err: ErrPIDFileInUse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.pid")
+			if len(tt.existing) > 0 || tt.empty {
+				require.NoError(t, os.WriteFile(path, []byte(tt.existing), 0644))
+			}
+			alive := func(pid int) bool {
+				assert.Equal(t, otherPID, pid)
+				return tt.alive
+			}
+
+			lc := fxtest.NewLifecycle(t)
+			require.NoError(t, pidFile(path, alive)(lc))
+
+			err := lc.Start(context.Background())
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				b, _ := os.ReadFile(path)
+				assert.Equal(t, tt.existing, string(b), "foreign pid file must be kept")
+				return
+			}
+			require.NoError(t, err)
+
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, self, string(b))
+
+			require.NoError(t, lc.Stop(context.Background()))
+			assert.NoFileExists(t, path)
+		})
+	}
+}
+
+func TestPIDFileConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.pid")
+	alive := func(int) bool { return true }
+
+	// a starting process created the pid file without writing its pid yet
+	const starting = 999
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	time.AfterFunc(pidFileRetry/4, func() {
+		_, _ = f.WriteString(strconv.Itoa(starting) + "\n")
+		_ = f.Close()
+	})
+
+	// every other process must find the pid file in use
+	const processes = 16
+	errs := make([]error, processes)
+	var wg sync.WaitGroup
+	for i := range processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = writePIDFile(path, 1000+i, alive)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrPIDFileInUse)
+	}
+	owner, err := readPIDFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, starting, owner)
+}
+
+func TestProcessAlive(t *testing.T) {
+	assert.True(t, processAlive(os.Getpid()))
+}
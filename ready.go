@@ -0,0 +1,117 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrContextMissingReadyProbe can be returned by [Ready]
+// and [ReadyProbeFromContext]
+var ErrContextMissingReadyProbe = errors.New("context is missing ready probe")
+
+// ReadyProbe signals the readiness of a running command, e.g. once a
+// server is listening. It is injected into the context of commands
+// started by [Commander] and [RunInvoker], see [Ready].
+// Pass it using [WithReadyProbe] to wait for readiness in tests.
+// Usage example:
+//
+//	probe := stdfx.NewReadyProbe()
+//	fx.Invoke(stdfx.CommanderWithOptions(stdfx.WithReadyProbe(probe))),
+//	...
+//	err := probe.WaitReady(ctx)
+type ReadyProbe struct {
+	once  sync.Once
+	ready chan struct{}
+}
+
+// NewReadyProbe returns a new *ReadyProbe which is not ready
+func NewReadyProbe() *ReadyProbe {
+	return &ReadyProbe{
+		ready: make(chan struct{}),
+	}
+}
+
+// Ready marks p as ready, it is safe to be called multiple times
+func (p *ReadyProbe) Ready() {
+	p.once.Do(func() {
+		close(p.ready)
+	})
+}
+
+// Done returns a channel which is closed once p is ready
+func (p *ReadyProbe) Done() <-chan struct{} {
+	return p.ready
+}
+
+// WaitReady blocks until p is ready or returns the error of ctx
+func (p *ReadyProbe) WaitReady(ctx context.Context) error {
+	select {
+	case <-p.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type readyProbeContextKeyType struct{}
+
+// readyProbeContextKey is used to inject *ReadyProbe into Context
+var readyProbeContextKey = &readyProbeContextKeyType{}
+
+// withReadyProbe injects probe into ctx for use with [Ready]
+func withReadyProbe(ctx context.Context, probe *ReadyProbe) context.Context {
+	return context.WithValue(ctx, readyProbeContextKey, probe)
+}
+
+// ReadyProbeFromContext returns the *ReadyProbe injected into ctx
+// by [Commander] or [ErrContextMissingReadyProbe].
+func ReadyProbeFromContext(ctx context.Context) (*ReadyProbe, error) {
+	probe, ok := ctx.Value(readyProbeContextKey).(*ReadyProbe)
+	if !ok || probe == nil {
+		return nil, ErrContextMissingReadyProbe
+	}
+	return probe, nil
+}
+
+// Ready marks the command of ctx as ready using the *ReadyProbe of ctx.
+// Call it once the command is able to serve, e.g. after listening.
+// It might return [ErrContextMissingReadyProbe] if ctx does not stem
+// from [Commander] which is safe to be ignored.
+func Ready(ctx context.Context) error {
+	probe, err := ReadyProbeFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	probe.Ready()
+	return nil
+}
+
+// WaitReady blocks until the command of ctx is ready using the *ReadyProbe
+// of ctx or returns the error of ctx. Use it in goroutines of a command
+// depending on it being able to serve, e.g. to register with a service
+// discovery. It returns [ErrContextMissingReadyProbe] if ctx does not stem
+// from [Commander].
+func WaitReady(ctx context.Context) error {
+	probe, err := ReadyProbeFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	return probe.WaitReady(ctx)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/stdfxtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+func TestReadyProbe(t *testing.T) {
+	// addr is set by the runnable before signaling readiness
+	var addr string
+	probe := stdfx.NewReadyProbe()
+
+	stdfxtest.StartTestApp(t,
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(func() stdfx.Runnable {
+			return runnableFunc(func(ctx context.Context) error {
+				ln, err := net.Listen("tcp", "127.0.0.1:0")
+				if err != nil {
+					return err
+				}
+				addr = ln.Addr().String()
+				if err := stdfx.Ready(ctx); err != nil {
+					return err
+				}
+
+				server := &http.Server{Handler: http.HandlerFunc(
+					func(w http.ResponseWriter, r *http.Request) {
+						fmt.Fprint(w, "ready")
+					},
+				)}
+				context.AfterFunc(ctx, func() {
+					_ = server.Close()
+				})
+				if err := server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			})
+		}),
+		fx.Invoke(stdfx.RunInvokerWithOptions(
+			stdfx.WithStartBackoff(time.Millisecond),
+			stdfx.WithReadyProbe(probe),
+		)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, probe.WaitReady(ctx))
+
+	// no sleep required, the server is listening
+	res, err := http.Get("http://" + addr)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ready", string(body))
+}
+
+func TestReadyProbeWait(t *testing.T) {
+	probe := stdfx.NewReadyProbe()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, probe.WaitReady(ctx), context.DeadlineExceeded)
+
+	probe.Ready()
+	probe.Ready() // safe to be called again
+	assert.NoError(t, probe.WaitReady(context.Background()))
+	select {
+	case <-probe.Done():
+	default:
+		t.Fatal("Done is not closed after Ready")
+	}
+}
+
+func TestWaitReady(t *testing.T) {
+	waited := make(chan error, 1)
+
+	stdfxtest.StartTestApp(t,
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(func() stdfx.Runnable {
+			return runnableFunc(func(ctx context.Context) error {
+				go func() {
+					waited <- stdfx.WaitReady(ctx)
+				}()
+				if err := stdfx.Ready(ctx); err != nil {
+					return err
+				}
+				<-ctx.Done()
+				return nil
+			})
+		}),
+		fx.Invoke(stdfx.RunInvokerWithOptions(
+			stdfx.WithStartBackoff(time.Millisecond),
+		)),
+	)
+
+	select {
+	case err := <-waited:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitReady did not return after Ready")
+	}
+}
+
+func TestReadyMissingProbe(t *testing.T) {
+	assert.ErrorIs(t, stdfx.Ready(context.Background()), stdfx.ErrContextMissingReadyProbe)
+	assert.ErrorIs(t, stdfx.WaitReady(context.Background()), stdfx.ErrContextMissingReadyProbe)
+}
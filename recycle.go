@@ -0,0 +1,146 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/fx"
+)
+
+// RecycleInterval is the minimum time between two starts of the fx.App
+// run by [RunRecyclable]. Recycling earlier is delayed to prevent storms
+// of apps recycling themselves during startup.
+var RecycleInterval = 5 * time.Second
+
+var (
+	// ErrNotRecyclable is returned by [Recycle] if no fx.App
+	// is being run by [RunRecyclable]
+	ErrNotRecyclable = errors.New("app is not run by RunRecyclable")
+
+	// ErrRecyclePending is returned by [Recycler.Recycle]
+	// if recycling the app was requested before
+	ErrRecyclePending = errors.New("recycle is already pending")
+)
+
+// Recycler recycles the fx.App run by [RunRecyclable].
+// It is supplied to every app built by [RunRecyclable].
+type Recycler struct {
+	shutdowner fx.Shutdowner
+	pending    atomic.Bool
+}
+
+// Recycle stops the fx.App to build and start a fresh one
+// using the same options. It returns [ErrRecyclePending] if
+// recycling was requested before.
+func (r *Recycler) Recycle() error {
+	if !r.pending.CompareAndSwap(false, true) {
+		return ErrRecyclePending
+	}
+
+	return r.shutdowner.Shutdown()
+}
+
+// currentRecycler is the Recycler of the running app of [RunRecyclable]
+var currentRecycler atomic.Pointer[Recycler]
+
+// Recycle recycles the fx.App currently being run by [RunRecyclable],
+// see [Recycler.Recycle]. It returns [ErrNotRecyclable] if there is none.
+func Recycle() error {
+	r := currentRecycler.Load()
+	if r == nil {
+		return ErrNotRecyclable
+	}
+
+	return r.Recycle()
+}
+
+// RunRecyclable builds and runs a fx.App using opts until it is shut down
+// or ctx is done. Calling [Recycle] stops the app and builds a fresh one
+// from opts instead, which reads the config again and reconstructs every
+// component without re-executing the process. Use it for config changes
+// which can't be applied to running components.
+// Recycles are delayed to keep [RecycleInterval] between starts.
+// It returns the exit code of the last app or error.
+// Usage example:
+//
+//	code, err := stdfx.RunRecyclable(context.Background(),
+//		fx.Provide(...),
+//		fx.Invoke(stdfx.Commander),
+//	)
+func RunRecyclable(ctx context.Context, opts ...fx.Option) (int, error) {
+	var started time.Time
+	for {
+		// delay recycles to prevent storms
+		if wait := RecycleInterval - time.Since(started); !started.IsZero() && wait > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, nil
+			case <-time.After(wait):
+			}
+		}
+		started = time.Now()
+
+		recycler := &Recycler{}
+		app := fx.New(
+			fx.Supply(recycler),
+			fx.Invoke(func(shutdowner fx.Shutdowner) {
+				recycler.shutdowner = shutdowner
+			}),
+			fx.Options(opts...),
+		)
+
+		code, err := runRecyclable(ctx, app, recycler)
+		if err != nil || !recycler.pending.Load() || ctx.Err() != nil {
+			return code, err
+		}
+	}
+}
+
+// runRecyclable runs app until it is shut down or ctx is done
+// and returns its exit code or error
+func runRecyclable(ctx context.Context, app *fx.App, recycler *Recycler) (int, error) {
+	if err := app.Err(); err != nil {
+		return 1, err
+	}
+
+	startCtx, cancel := context.WithTimeout(ctx, app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		return 1, err
+	}
+	currentRecycler.Store(recycler)
+	defer currentRecycler.CompareAndSwap(recycler, nil)
+
+	code := 0
+	select {
+	case sig := <-app.Wait():
+		code = sig.ExitCode
+	case <-ctx.Done():
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+	defer cancel()
+	if err := app.Stop(stopCtx); err != nil {
+		return 1, err
+	}
+
+	return code, nil
+}
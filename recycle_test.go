@@ -0,0 +1,67 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+func TestRunRecyclable(t *testing.T) {
+	oldInterval := stdfx.RecycleInterval
+	defer func() { stdfx.RecycleInterval = oldInterval }()
+	stdfx.RecycleInterval = 50 * time.Millisecond
+
+	assert.ErrorIs(t, stdfx.Recycle(), stdfx.ErrNotRecyclable)
+
+	builds := 0
+	starts := []time.Time{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	code, err := stdfx.RunRecyclable(ctx,
+		fx.NopLogger,
+		fx.Invoke(func(lc fx.Lifecycle, recycler *stdfx.Recycler, shutdowner fx.Shutdowner) {
+			builds++
+			lc.Append(fx.StartHook(func() {
+				starts = append(starts, time.Now())
+				if len(starts) < 3 {
+					go func() {
+						assert.NoError(t, recycler.Recycle())
+						assert.ErrorIs(t, recycler.Recycle(), stdfx.ErrRecyclePending)
+					}()
+					return
+				}
+				go shutdowner.Shutdown(fx.ExitCode(3)) // nolint:errcheck
+			}))
+		}),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, 3, builds)
+
+	// recycles are delayed by RecycleInterval measured before building apps
+	for i := 1; i < len(starts); i++ {
+		assert.Greater(t, starts[i].Sub(starts[i-1]), stdfx.RecycleInterval/2)
+	}
+}
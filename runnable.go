@@ -0,0 +1,89 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.uber.org/fx"
+)
+
+// Runnable is implemented by long running services like servers.
+// Start shall block until ctx is cancelled or the service failed.
+type Runnable interface {
+	Start(ctx context.Context) error
+}
+
+// RunInvoker is a fx invoker running a provided [Runnable] during the
+// lifecycle of the app. Start is called in a goroutine on start and its
+// ctx is cancelled on stop, like [Commander] does for commands.
+// Errors of Start shutdown the app using exit code 1 or the code of
+// [ExitError], returning nil shuts down the app using exit code 0.
+// Usage example:
+//
+//	fx.Provide(fx.Annotate(NewServer, fx.As(new(stdfx.Runnable)))),
+//	fx.Invoke(stdfx.RunInvoker),
+func RunInvoker(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	runnable Runnable,
+	log *slog.Logger,
+) {
+	runInvoker(lc, shutdowner, runnable, log, defaultCommanderOptions())
+}
+
+// RunInvokerWithOptions is a [RunInvoker] which can be tuned using opts.
+// Usage example:
+//
+//	fx.Invoke(stdfx.RunInvokerWithOptions(
+//		stdfx.WithStartBackoff(3 * time.Second),
+//		stdfx.WithStopTimeout(30 * time.Second),
+//	)),
+func RunInvokerWithOptions(opts ...CommanderOption) func(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	runnable Runnable,
+	log *slog.Logger,
+) {
+	// apply any given opts
+	cOpts := defaultCommanderOptions()
+	for _, option := range opts {
+		option(cOpts)
+	}
+
+	return func(
+		lc fx.Lifecycle,
+		shutdowner fx.Shutdowner,
+		runnable Runnable,
+		log *slog.Logger,
+	) {
+		runInvoker(lc, shutdowner, runnable, log, cOpts)
+	}
+}
+
+// runInvoker implements [RunInvoker] using opts
+func runInvoker(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	runnable Runnable,
+	log *slog.Logger,
+	opts *commanderOptions,
+) {
+	appendRun(lc, shutdowner, fmt.Sprintf("%T", runnable), runnable.Start, log, opts)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+)
+
+// fakeRunnable is a stdfx.Runnable reporting its calls
+type fakeRunnable struct {
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+// Start implements stdfx.Runnable
+func (r *fakeRunnable) Start(ctx context.Context) error {
+	close(r.started)
+	<-ctx.Done()
+	close(r.cancelled)
+	return ctx.Err()
+}
+
+func TestRunInvoker(t *testing.T) {
+	runnable := &fakeRunnable{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(func() stdfx.Runnable { return runnable }),
+		fx.Invoke(stdfx.RunInvokerWithOptions(
+			stdfx.WithStartBackoff(50*time.Millisecond),
+		)),
+	)
+	require.NoError(t, app.Start(context.Background()))
+
+	select {
+	case <-runnable.started:
+	default:
+		t.Fatal("Start has not been called on start")
+	}
+	select {
+	case <-runnable.cancelled:
+		t.Fatal("ctx of Start cancelled before stop")
+	default:
+	}
+
+	require.NoError(t, app.Stop(context.Background()))
+	select {
+	case <-runnable.cancelled:
+	default:
+		t.Fatal("ctx of Start has not been cancelled on stop")
+	}
+}
+
+func TestRunInvokerExitCode(t *testing.T) {
+	app := fx.New(
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(func() stdfx.Runnable {
+			return runnableFunc(func(context.Context) error {
+				time.Sleep(50 * time.Millisecond) // fail after starting
+				return stdfx.ExitError{Code: 4}
+			})
+		}),
+		fx.Invoke(stdfx.RunInvokerWithOptions(
+			stdfx.WithStartBackoff(time.Millisecond),
+		)),
+	)
+	require.NoError(t, app.Start(context.Background()))
+	defer app.Stop(context.Background()) // nolint:errcheck
+
+	select {
+	case sig := <-app.Wait():
+		assert.Equal(t, 4, sig.ExitCode)
+	case <-time.After(5 * time.Second):
+		t.Fatal("app has not been shutdown")
+	}
+}
+
+// runnableFunc implements stdfx.Runnable using a func
+type runnableFunc func(context.Context) error
+
+// Start implements stdfx.Runnable
+func (f runnableFunc) Start(ctx context.Context) error {
+	return f(ctx)
+}
@@ -17,31 +17,155 @@ limitations under the License.
 package stdfx
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"strconv"
+	"strings"
 )
 
 var (
 	// ErrRunningAsRoot can be returned by [Unprivileged]
 	ErrRunningAsRoot = errors.New("running as root is dangerous and prohibited")
+
+	// ErrCapabilities can be returned by [UnprivilegedStrict]
+	ErrCapabilities = errors.New("running with capabilities is dangerous and prohibited")
 )
 
 // Unprivileged returns an error if being run as root.
 // This takes effect whenever the real or effective user id
 // of the current user process is 0.
+// On Windows it takes effect if the process token is elevated,
+// which is the case when being run as administrator.
 func Unprivileged() error {
-	if os.Getuid() == 0 || os.Geteuid() == 0 {
+	if privileged() {
 		return ErrRunningAsRoot
 	}
 	return nil
 }
 
 // UnprivilegedWarn warns if being run as root.
-// This takes effect whenever the real or effective user id
-// of the current user process is 0.
+// This takes effect whenever [Unprivileged] returns an error.
 func UnprivilegedWarn(log *slog.Logger) {
 	if Unprivileged() != nil {
 		log.Warn("running as root is dangerous")
 	}
 }
+
+// capabilityNames are the names of linux capabilities by bit
+var capabilityNames = []string{
+	"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_DAC_READ_SEARCH", "CAP_FOWNER",
+	"CAP_FSETID", "CAP_KILL", "CAP_SETGID", "CAP_SETUID", "CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE", "CAP_NET_BIND_SERVICE", "CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN", "CAP_NET_RAW", "CAP_IPC_LOCK", "CAP_IPC_OWNER",
+	"CAP_SYS_MODULE", "CAP_SYS_RAWIO", "CAP_SYS_CHROOT", "CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT", "CAP_SYS_ADMIN", "CAP_SYS_BOOT", "CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE", "CAP_SYS_TIME", "CAP_SYS_TTY_CONFIG", "CAP_MKNOD",
+	"CAP_LEASE", "CAP_AUDIT_WRITE", "CAP_AUDIT_CONTROL", "CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE", "CAP_MAC_ADMIN", "CAP_SYSLOG", "CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND", "CAP_AUDIT_READ", "CAP_PERFMON", "CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// UnprivilegedStrict works like [Unprivileged] but additionally returns
+// an error wrapping [ErrCapabilities] naming the held capabilities if the
+// effective capability set of the process is not empty on linux.
+// Capabilities granted on purpose can be allowed like "CAP_NET_BIND_SERVICE"
+// or "net_bind_service".
+// Usage example:
+//
+//	fx.Invoke(stdfx.UnprivilegedStrict("CAP_NET_BIND_SERVICE")),
+func UnprivilegedStrict(allowed ...string) func() error {
+	return func() error {
+		if err := Unprivileged(); err != nil {
+			return err
+		}
+		if runtime.GOOS != "linux" {
+			return nil
+		}
+
+		f, err := os.Open("/proc/self/status")
+		if err != nil {
+			return fmt.Errorf("reading capabilities: %s", err)
+		}
+		defer f.Close()
+
+		capEff, err := parseCapEff(f)
+		if err != nil {
+			return fmt.Errorf("reading capabilities: %s", err)
+		}
+
+		return checkCapabilities(capEff, allowed)
+	}
+}
+
+// checkCapabilities returns an error wrapping [ErrCapabilities] if capEff
+// holds any capability not found in allowed
+func checkCapabilities(capEff uint64, allowed []string) error {
+	held := []string{}
+	for _, name := range capabilities(capEff) {
+		isAllowed := false
+		for _, a := range allowed {
+			a = strings.ToUpper(a)
+			if !strings.HasPrefix(a, "CAP_") {
+				a = "CAP_" + a
+			}
+			if a == name {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			held = append(held, name)
+		}
+	}
+	if len(held) > 0 {
+		return fmt.Errorf("%w: %s", ErrCapabilities, strings.Join(held, ", "))
+	}
+
+	return nil
+}
+
+// capabilities returns the names of the capabilities set in mask
+func capabilities(mask uint64) []string {
+	names := []string{}
+	for bit := range 64 {
+		if mask&(1<<bit) == 0 {
+			continue
+		}
+		if bit < len(capabilityNames) {
+			names = append(names, capabilityNames[bit])
+		} else {
+			names = append(names, "CAP_"+strconv.Itoa(bit))
+		}
+	}
+
+	return names
+}
+
+// parseCapEff returns the effective capability set found in r
+// using the format of /proc/self/status
+func parseCapEff(r io.Reader) (uint64, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "CapEff:")
+		if !ok {
+			continue
+		}
+
+		mask, err := strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid CapEff %q: %s", strings.TrimSpace(value), err)
+		}
+		return mask, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, errors.New("missing CapEff")
+}
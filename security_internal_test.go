@@ -0,0 +1,54 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCapEff(t *testing.T) {
+	status := "Name:\tapp\nCapInh:\t0000000000000000\nCapPrm:\t0000000000000400\n" +
+		"CapEff:\t0000000000000400\nCapBnd:\t000001ffffffffff\n"
+
+	capEff, err := parseCapEff(strings.NewReader(status))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CAP_NET_BIND_SERVICE"}, capabilities(capEff))
+
+	_, err = parseCapEff(strings.NewReader("CapEff:\tnope\n"))
+	assert.ErrorContains(t, err, "invalid CapEff")
+	_, err = parseCapEff(strings.NewReader("Name:\tapp\n"))
+	assert.ErrorContains(t, err, "missing CapEff")
+}
+
+func TestCheckCapabilities(t *testing.T) {
+	// CAP_NET_BIND_SERVICE, CAP_NET_RAW and an unknown bit 63
+	capEff := uint64(1<<10 | 1<<13 | 1<<63)
+
+	err := checkCapabilities(capEff, nil)
+	assert.ErrorIs(t, err, ErrCapabilities)
+	assert.ErrorContains(t, err, "CAP_NET_BIND_SERVICE, CAP_NET_RAW, CAP_63")
+
+	err = checkCapabilities(capEff, []string{"CAP_NET_BIND_SERVICE", "net_raw"})
+	assert.ErrorContains(t, err, ": CAP_63")
+
+	assert.NoError(t, checkCapabilities(0, nil))
+	assert.NoError(t, checkCapabilities(1<<10, []string{"cap_net_bind_service"}))
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx_test
+
+import (
+	"testing"
+
+	"github.com/choopm/stdfx"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows"
+)
+
+func TestUnprivileged(t *testing.T) {
+	err := stdfx.Unprivileged()
+	if windows.GetCurrentProcessToken().IsElevated() {
+		assert.ErrorIs(t, err, stdfx.ErrRunningAsRoot)
+		return
+	}
+	assert.NoError(t, err)
+}
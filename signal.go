@@ -0,0 +1,112 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/fx"
+)
+
+// SignalForcedExitCode is the exit code used by [SignalHandler]
+// when a second signal forces the process to exit.
+const SignalForcedExitCode = 130
+
+// DefaultShutdownSignals are the default signals for [SignalHandler]
+var DefaultShutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// SignalHandler might be used with [fx.Invoke] to shutdown the fx.App
+// gracefully when receiving any of signals.
+// If signals is empty it will use a default list: [DefaultShutdownSignals].
+// The first signal calls fx.Shutdowner.Shutdown() using exit code 0,
+// a second signal forces [os.Exit] using [SignalForcedExitCode].
+// It should be invoked before [Commander] to keep handling signals
+// while the command is stopping.
+// Usage example:
+//
+//	fx.Invoke(stdfx.SignalHandler()),
+//	fx.Invoke(stdfx.Commander),
+func SignalHandler(signals ...os.Signal) func(
+	lc fx.Lifecycle,
+	shutdowner fx.Shutdowner,
+	log *slog.Logger,
+) {
+	// use default signals if nothing was provided
+	if len(signals) == 0 {
+		signals = DefaultShutdownSignals
+	}
+
+	return func(
+		lc fx.Lifecycle,
+		shutdowner fx.Shutdowner,
+		log *slog.Logger,
+	) {
+		ch := make(chan os.Signal, 2)
+		done := make(chan struct{})
+
+		lc.Append(fx.Hook{
+			OnStart: func(_ context.Context) error {
+				signal.Notify(ch, signals...)
+				go handleSignals(ch, done, shutdowner, log, os.Exit)
+				return nil
+			},
+			OnStop: func(_ context.Context) error {
+				signal.Stop(ch)
+				close(done)
+				return nil
+			},
+		})
+	}
+}
+
+// handleSignals waits for signals on ch until done is closed.
+// The first signal triggers a shutdown using shutdowner,
+// the second one calls exit using [SignalForcedExitCode].
+func handleSignals(
+	ch <-chan os.Signal,
+	done <-chan struct{},
+	shutdowner fx.Shutdowner,
+	log *slog.Logger,
+	exit func(code int),
+) {
+	select {
+	case <-done:
+		return
+
+	case sig := <-ch:
+		log.Info("received signal, shutting down",
+			slog.String("signal", sig.String()))
+		if err := shutdowner.Shutdown(fx.ExitCode(0)); err != nil {
+			log.Error("failed to shutdown", slog.Any("error", err))
+		}
+	}
+
+	select {
+	case <-done:
+		return
+
+	case sig := <-ch:
+		log.Warn("received second signal, forcing exit",
+			slog.String("signal", sig.String()),
+			slog.Int("exit-code", SignalForcedExitCode))
+		exit(SignalForcedExitCode)
+	}
+}
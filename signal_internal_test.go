@@ -0,0 +1,66 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfx
+
+import (
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+// countingShutdowner is a fx.Shutdowner counting calls to Shutdown
+type countingShutdowner struct {
+	calls atomic.Int32
+}
+
+// Shutdown implements fx.Shutdowner
+func (s *countingShutdowner) Shutdown(...fx.ShutdownOption) error {
+	s.calls.Add(1)
+	return nil
+}
+
+func TestSignalHandler(t *testing.T) {
+	ch := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	exited := make(chan int, 1)
+	shutdowner := &countingShutdowner{}
+
+	go handleSignals(ch, done, shutdowner, slog.New(slog.DiscardHandler),
+		func(code int) { exited <- code })
+
+	// first signal shall shutdown exactly once
+	ch <- syscall.SIGTERM
+	assert.Eventually(t, func() bool {
+		return shutdowner.calls.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// second signal shall force an exit
+	ch <- syscall.SIGINT
+	select {
+	case code := <-exited:
+		assert.Equal(t, SignalForcedExitCode, code)
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not force exit")
+	}
+	assert.Equal(t, int32(1), shutdowner.calls.Load())
+}
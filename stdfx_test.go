@@ -34,6 +34,7 @@ import (
 	"github.com/choopm/stdfx/globals"
 	"github.com/choopm/stdfx/loggingfx"
 	"github.com/choopm/stdfx/loggingfx/zerologfx"
+	"github.com/choopm/stdfx/stdfxtest"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
@@ -66,10 +67,6 @@ routes:
 
 // TestExampleWebserver tests the same things as examples/webserver
 func TestExampleWebserver(t *testing.T) {
-	d, _ := t.Deadline()
-	ctx, cancel := context.WithDeadline(context.Background(), d)
-	defer cancel()
-
 	// create config file
 	tempDir, err := os.MkdirTemp(os.TempDir(), "go-test")
 	require.Nil(t, err)
@@ -83,8 +80,9 @@ func TestExampleWebserver(t *testing.T) {
 	defer func() { os.Args = oldArgs }()
 	os.Args = []string{os.Args[0], "-c", tempDir, "server"}
 
-	// build app
-	app := fx.New(
+	// build and start the app
+	probe := stdfx.NewReadyProbe()
+	_, stop := stdfxtest.StartTestApp(t,
 		// logging
 		zerologfx.Module,
 		fx.WithLogger(zerologfx.ToFx),
@@ -102,15 +100,15 @@ func TestExampleWebserver(t *testing.T) {
 
 		// app start
 		fx.Invoke(stdfx.Unprivileged), // abort when being run as root
-		fx.Invoke(stdfx.Commander),    // run root cobra command
+		fx.Invoke(stdfx.CommanderWithOptions( // run root cobra command
+			stdfx.WithReadyProbe(probe), // signaled by the server
+		)),
 	)
 
-	// start the app
-	g, ctx := errgroup.WithContext(ctx)
-	g.Go(func() error {
-		return app.Start(ctx)
-	})
-	time.Sleep(time.Second * 3)
+	// wait for the server to listen
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, probe.WaitReady(ctx))
 
 	// test http requests
 	client := http.DefaultClient
@@ -123,11 +121,12 @@ func TestExampleWebserver(t *testing.T) {
 	require.Nil(t, err)
 	assert.Contains(t, string(body), "example from tests")
 
-	// stop the app
-	cancel()
+	// stop the app, we should not see any errors
+	stop()
 
-	// we should not see any errors
-	assert.Nil(t, g.Wait())
+	// the server has been stopped by cancelling its context
+	_, err = net.Dial("tcp", "localhost:8080")
+	assert.Error(t, err)
 }
 
 // serverCommand returns a *cobra.Command to start the server from a ConfigProvider
@@ -313,13 +312,17 @@ func (s *Server) Start(ctx context.Context) error {
 	addr := net.JoinHostPort(s.config.Webserver.Host,
 		strconv.Itoa(s.config.Webserver.Port),
 	)
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
 	server := &http.Server{Addr: addr, Handler: nil}
 	// shutdown hook, registered before starting
 	context.AfterFunc(ctx, func() {
 		_ = server.Close()
 	})
 	g.Go(func() error {
-		err := server.ListenAndServe()
+		err := server.Serve(ln)
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
@@ -327,6 +330,9 @@ func (s *Server) Start(ctx context.Context) error {
 		return nil
 	})
 
+	// signal readiness, ctx stems from stdfx.Commander
+	_ = stdfx.Ready(ctx)
+
 	// wait for started tasks
 	s.log.Info().
 		Str("addr", addr).
@@ -0,0 +1,67 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package stdfxtest provides helpers for testing stdfx apps.
+package stdfxtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.uber.org/fx"
+)
+
+// StartTestApp builds and starts an *fx.App using opts for use in tests.
+// Starting blocks until the start backoff of [stdfx.Commander] or
+// [stdfx.RunInvoker] has passed, errors during startup fail t.
+// The returned func stops the app and cancels the context of the running
+// command. It is registered using t.Cleanup and safe to be called again.
+// Usage example:
+//
+//	app, stop := stdfxtest.StartTestApp(t,
+//		fx.Provide(stdfx.AutoRegister(serverCommand), stdfx.AutoCommand),
+//		fx.Invoke(stdfx.Commander),
+//	)
+//	defer stop()
+func StartTestApp(t testing.TB, opts ...fx.Option) (*fx.App, func()) {
+	t.Helper()
+
+	app := fx.New(opts...)
+	if err := app.Err(); err != nil {
+		t.Fatalf("building app: %s", err)
+	}
+
+	startCtx, cancel := context.WithTimeout(context.Background(), app.StartTimeout())
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		t.Fatalf("starting app: %s", err)
+	}
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			stopCtx, cancel := context.WithTimeout(context.Background(), app.StopTimeout())
+			defer cancel()
+			if err := app.Stop(stopCtx); err != nil {
+				t.Errorf("stopping app: %s", err)
+			}
+		})
+	}
+	t.Cleanup(stop)
+
+	return app, stop
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 Christoph Hoopmann
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package stdfxtest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/choopm/stdfx"
+	"github.com/choopm/stdfx/stdfxtest"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+)
+
+// fakeRunnable is a stdfx.Runnable recording calls of Start
+type fakeRunnable struct {
+	started   chan struct{}
+	cancelled chan struct{}
+}
+
+// Start implements stdfx.Runnable
+func (r *fakeRunnable) Start(ctx context.Context) error {
+	close(r.started)
+	<-ctx.Done()
+	close(r.cancelled)
+	return ctx.Err()
+}
+
+// runnableFunc implements stdfx.Runnable using a func
+type runnableFunc func(context.Context) error
+
+// Start implements stdfx.Runnable
+func (f runnableFunc) Start(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestStartTestApp(t *testing.T) {
+	runnable := &fakeRunnable{
+		started:   make(chan struct{}),
+		cancelled: make(chan struct{}),
+	}
+
+	_, stop := stdfxtest.StartTestApp(t,
+		fx.NopLogger,
+		fx.Supply(slog.New(slog.DiscardHandler)),
+		fx.Provide(func() stdfx.Runnable { return runnable }),
+		fx.Invoke(stdfx.RunInvokerWithOptions(
+			stdfx.WithStartBackoff(50*time.Millisecond),
+		)),
+	)
+
+	select {
+	case <-runnable.started:
+	default:
+		t.Fatal("Start has not been called on start")
+	}
+
+	stop()
+	select {
+	case <-runnable.cancelled:
+	default:
+		t.Fatal("ctx of Start has not been cancelled by stop")
+	}
+
+	// stopping again is a no-op
+	stop()
+}
+
+// fatalTB is a testing.TB recording calls of Fatalf
+type fatalTB struct {
+	testing.TB
+	fatal string
+}
+
+// Fatalf implements testing.TB
+func (t *fatalTB) Fatalf(format string, args ...any) {
+	t.fatal = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+func TestStartTestAppStartError(t *testing.T) {
+	tb := &fatalTB{TB: t}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		stdfxtest.StartTestApp(tb,
+			fx.NopLogger,
+			fx.Supply(slog.New(slog.DiscardHandler)),
+			fx.Provide(func() stdfx.Runnable {
+				return runnableFunc(func(context.Context) error {
+					return errors.New("broken")
+				})
+			}),
+			fx.Invoke(stdfx.RunInvoker),
+		)
+	}()
+	<-done
+
+	assert.Contains(t, tb.fatal, "starting app")
+	assert.Contains(t, tb.fatal, "broken")
+}